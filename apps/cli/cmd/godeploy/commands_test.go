@@ -0,0 +1,1176 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/kong"
+	"github.com/silvabyte/godeploy/internal/api"
+	"github.com/silvabyte/godeploy/internal/api/apitest"
+	"github.com/silvabyte/godeploy/internal/auth"
+	"github.com/silvabyte/godeploy/internal/link"
+	"github.com/silvabyte/godeploy/internal/paths"
+	"github.com/silvabyte/godeploy/pkg/godeploy"
+)
+
+const (
+	testEmail    = "dev@example.com"
+	testPassword = "correct-horse"
+)
+
+// useTestEnv isolates a test from the user's credentials, settings and
+// working directory, and returns the directory it runs in
+func useTestEnv(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	work := filepath.Join(home, "work")
+	if err := os.MkdirAll(work, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	origConfigDir, origCacheDir := paths.GetConfigDir, paths.GetCacheDir
+	origLegacyDir := auth.GetLegacyConfigDir
+	origWd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		paths.GetConfigDir, paths.GetCacheDir = origConfigDir, origCacheDir
+		auth.GetLegacyConfigDir = origLegacyDir
+		auth.SetProfile("")
+		auth.SetAPIToken("")
+		_ = os.Chdir(origWd)
+	})
+	paths.GetConfigDir = func() string { return filepath.Join(home, "config") }
+	paths.GetCacheDir = func() string { return filepath.Join(home, "cache") }
+	auth.GetLegacyConfigDir = func() (string, error) { return filepath.Join(home, "legacy"), nil }
+	for _, name := range []string{auth.TokenEnvVar, auth.ProfileEnvVar, api.APIURLEnvVar} {
+		t.Setenv(name, "")
+	}
+
+	if err := os.Chdir(work); err != nil {
+		t.Fatal(err)
+	}
+	return work
+}
+
+// logIn saves a login for an account of server, as 'auth login' would
+func logIn(t *testing.T, server *apitest.Server) {
+	t.Helper()
+	accessToken, refreshToken := server.Login(testEmail)
+	if err := auth.SetTokens(accessToken, refreshToken); err != nil {
+		t.Fatal(err)
+	}
+	if err := auth.SetUserEmail(testEmail); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeSite writes a config with one app and its build output to dir
+func writeSite(t *testing.T, dir, app string) {
+	t.Helper()
+	config := `{"apps": [{"name": "` + app + `", "source_dir": "dist", "enabled": true}]}`
+	if err := os.WriteFile(filepath.Join(dir, "godeploy.config.json"), []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "dist"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "dist", "index.html"), []byte("<h1>hello</h1>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// runCommand runs the CLI with args against client and returns its output
+func runCommand(t *testing.T, client api.API, args ...string) (string, error) {
+	t.Helper()
+	parser, err := kong.New(&CLI, kong.Name("godeploy"), kong.Vars{"version": "test"},
+		kong.Exit(func(int) { t.Fatalf("godeploy %s exited", strings.Join(args, " ")) }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, err := parser.Parse(args)
+	if err != nil {
+		t.Fatalf("godeploy %s: %v", strings.Join(args, " "), err)
+	}
+	ctx.BindTo(context.Background(), (*context.Context)(nil))
+	ctx.BindTo(client, (*api.API)(nil))
+
+	read, write, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = write
+	output := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(read)
+		output <- string(data)
+	}()
+
+	err = ctx.Run()
+	os.Stdout = stdout
+	_ = write.Close()
+	return <-output, err
+}
+
+// TestDeployCmd tests that deploy uploads the app to the right project
+func TestDeployCmd(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		// setup prepares the server and working directory
+		setup func(t *testing.T, server *apitest.Server, dir string)
+		// wantErr is a substring of the error; empty for success
+		wantErr string
+		// wantOutput is a substring of the output
+		wantOutput string
+	}{
+		{
+			name: "deploys the app",
+			args: []string{"deploy", "--no-git"},
+			setup: func(t *testing.T, server *apitest.Server, dir string) {
+				logIn(t, server)
+				writeSite(t, dir, "web")
+			},
+			wantOutput: "spa.godeploy.app",
+		},
+		{
+			name: "deploys again to the same project",
+			args: []string{"deploy", "--no-git"},
+			setup: func(t *testing.T, server *apitest.Server, dir string) {
+				logIn(t, server)
+				writeSite(t, dir, "web")
+				server.AddProject(testEmail, "web")
+			},
+		},
+		{
+			name: "deploys to the linked project",
+			args: []string{"deploy", "--no-git"},
+			setup: func(t *testing.T, server *apitest.Server, dir string) {
+				logIn(t, server)
+				writeSite(t, dir, "web")
+				project := server.AddProject(testEmail, "web")
+				if err := link.Save(dir, &link.Binding{ProjectID: project.ID, ProjectName: project.Name}); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantOutput: "Using linked project: 'web'",
+		},
+		{
+			name: "deploys with an API token",
+			args: []string{"deploy", "--no-git"},
+			setup: func(t *testing.T, server *apitest.Server, dir string) {
+				t.Setenv(auth.TokenEnvVar, server.CreateToken(testEmail, "ci"))
+				writeSite(t, dir, "web")
+			},
+		},
+		{
+			name: "requires a login",
+			args: []string{"deploy", "--no-git"},
+			setup: func(t *testing.T, server *apitest.Server, dir string) {
+				writeSite(t, dir, "web")
+			},
+			wantErr: "must be authenticated",
+		},
+		{
+			name: "requires a config",
+			args: []string{"deploy", "--no-git"},
+			setup: func(t *testing.T, server *apitest.Server, dir string) {
+				logIn(t, server)
+			},
+			wantErr: "no godeploy.config.json found",
+		},
+		{
+			name: "reports a rejected upload",
+			args: []string{"deploy", "--no-git"},
+			setup: func(t *testing.T, server *apitest.Server, dir string) {
+				logIn(t, server)
+				writeSite(t, dir, "web")
+				server.Fail("POST /api/deploy", 413, "Archive too large")
+			},
+			wantErr:    "deployment failed",
+			wantOutput: "Archive too large",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := useTestEnv(t)
+			server := apitest.NewServer(t)
+			server.AddUser(testEmail, testPassword)
+			tt.setup(t, server, dir)
+
+			output, err := runCommand(t, server.Client(), tt.args...)
+			if !strings.Contains(output, tt.wantOutput) {
+				t.Errorf("output doesn't contain %q:\n%s", tt.wantOutput, output)
+			}
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("deploy failed: %v\n%s", err, output)
+			}
+
+			projects := server.Projects()
+			if len(projects) != 1 || projects[0].Name != "web" {
+				t.Fatalf("projects = %+v, want just 'web'", projects)
+			}
+			deployments := server.Deployments(projects[0].ID)
+			if len(deployments) != 1 {
+				t.Fatalf("got %d deployments, want 1", len(deployments))
+			}
+			if len(server.Archive(deployments[0].ID)) == 0 {
+				t.Error("deployment has no archive")
+			}
+		})
+	}
+}
+
+// TestDeployCmdSendsCommit tests that commit flags are saved with the deployment
+func TestDeployCmdSendsCommit(t *testing.T) {
+	dir := useTestEnv(t)
+	server := apitest.NewServer(t)
+	server.AddUser(testEmail, testPassword)
+	logIn(t, server)
+	writeSite(t, dir, "web")
+
+	if _, err := runCommand(t, server.Client(), "deploy", "--no-git", "--commit-sha", "abc1234", "--commit-branch", "main"); err != nil {
+		t.Fatal(err)
+	}
+	deployment := server.Deployments(server.Projects()[0].ID)[0]
+	if deployment.CommitSHA == nil || *deployment.CommitSHA != "abc1234" {
+		t.Errorf("commit SHA = %v, want abc1234", deployment.CommitSHA)
+	}
+	if deployment.CommitBranch == nil || *deployment.CommitBranch != "main" {
+		t.Errorf("commit branch = %v, want main", deployment.CommitBranch)
+	}
+}
+
+// TestLoginCmd tests that login saves the session of valid credentials only
+func TestLoginCmd(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		// loggedIn saves a login before the command runs
+		loggedIn   bool
+		wantErr    error
+		wantOutput string
+		// wantRequests is whether the command talks to the API
+		wantRequests bool
+	}{
+		{name: "logs in", password: testPassword, wantOutput: "You are now logged in", wantRequests: true},
+		{name: "rejects a wrong password", password: "wrong-password", wantErr: api.ErrUnauthorized, wantOutput: "Invalid email or password", wantRequests: true},
+		{name: "keeps a valid login", password: testPassword, loggedIn: true, wantOutput: "already authenticated"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTestEnv(t)
+			server := apitest.NewServer(t)
+			server.AddUser(testEmail, testPassword)
+			if tt.loggedIn {
+				logIn(t, server)
+			}
+
+			output, err := runCommand(t, server.Client(), "auth", "login", "--email", testEmail, "--password", tt.password)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if !strings.Contains(output, tt.wantOutput) {
+				t.Errorf("output doesn't contain %q:\n%s", tt.wantOutput, output)
+			}
+			if got := len(server.Requests()) > 0; got != tt.wantRequests {
+				t.Errorf("sent requests = %v, want %v", got, tt.wantRequests)
+			}
+
+			token, _ := auth.GetAuthToken()
+			if tt.wantErr == nil && token == "" {
+				t.Error("no token saved")
+			}
+			if tt.wantErr != nil && token != "" {
+				t.Error("token saved after a failed login")
+			}
+		})
+	}
+}
+
+// TestLoginCmdSavesAPIURL tests that --api-url is used for the login and saved
+func TestLoginCmdSavesAPIURL(t *testing.T) {
+	useTestEnv(t)
+	server := apitest.NewServer(t)
+	server.AddUser(testEmail, testPassword)
+
+	// The client starts out at another API; --api-url redirects it
+	client := server.Client()
+	client.BaseURL = "http://127.0.0.1:1"
+	if _, err := runCommand(t, client, "auth", "login", "--email", testEmail, "--password", testPassword, "--api-url", server.URL+"/"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := auth.GetAPIURL(); got != server.URL {
+		t.Errorf("saved API URL = %q, want %q", got, server.URL)
+	}
+}
+
+// TestSignUpCmd tests that sign-up creates accounts and reports existing ones
+func TestSignUpCmd(t *testing.T) {
+	tests := []struct {
+		name       string
+		email      string
+		password   string
+		wantErr    error
+		wantOutput string
+	}{
+		{name: "creates an account", email: "new@example.com", password: "long-enough", wantOutput: "Account created successfully"},
+		{name: "rejects an existing account", email: testEmail, password: "long-enough", wantErr: api.ErrConflict, wantOutput: "already exists"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTestEnv(t)
+			server := apitest.NewServer(t)
+			server.AddUser(testEmail, testPassword)
+
+			output, err := runCommand(t, server.Client(), "auth", "sign-up", "--email", tt.email, "--password", tt.password)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if !strings.Contains(output, tt.wantOutput) {
+				t.Errorf("output doesn't contain %q:\n%s", tt.wantOutput, output)
+			}
+		})
+	}
+}
+
+// TestLogoutCmdRevokesSession tests that logout revokes the session on the server
+func TestLogoutCmdRevokesSession(t *testing.T) {
+	useTestEnv(t)
+	server := apitest.NewServer(t)
+	server.AddUser(testEmail, testPassword)
+	logIn(t, server)
+	token, _ := auth.GetAuthToken()
+
+	client := server.Client()
+	if _, err := runCommand(t, client, "auth", "logout"); err != nil {
+		t.Fatal(err)
+	}
+	if saved, _ := auth.GetAuthToken(); saved != "" {
+		t.Error("token still saved after logout")
+	}
+	verify, err := client.VerifyToken(context.Background(), token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verify.Valid {
+		t.Error("session still valid after logout")
+	}
+}
+
+// TestAuthStatusCmd tests the status reported for logins and API tokens
+func TestAuthStatusCmd(t *testing.T) {
+	tests := []struct {
+		name       string
+		setup      func(t *testing.T, server *apitest.Server)
+		wantOutput string
+	}{
+		{
+			name:       "logged in",
+			setup:      logIn,
+			wantOutput: "You are authenticated with GoDeploy",
+		},
+		{
+			name:       "logged out",
+			setup:      func(*testing.T, *apitest.Server) {},
+			wantOutput: "You are not authenticated",
+		},
+		{
+			name: "valid API token",
+			setup: func(t *testing.T, server *apitest.Server) {
+				t.Setenv(auth.TokenEnvVar, server.CreateToken(testEmail, "ci"))
+			},
+			wantOutput: "Token belongs to: " + testEmail,
+		},
+		{
+			name: "invalid API token",
+			setup: func(t *testing.T, server *apitest.Server) {
+				t.Setenv(auth.TokenEnvVar, "gdp_revoked")
+			},
+			wantOutput: "The API token is not valid",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTestEnv(t)
+			server := apitest.NewServer(t)
+			server.AddUser(testEmail, testPassword)
+			tt.setup(t, server)
+
+			output, err := runCommand(t, server.Client(), "auth", "status")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(output, tt.wantOutput) {
+				t.Errorf("output doesn't contain %q:\n%s", tt.wantOutput, output)
+			}
+		})
+	}
+}
+
+// TestLinkCmd tests that link saves the project it finds
+func TestLinkCmd(t *testing.T) {
+	tests := []struct {
+		name    string
+		project string
+		wantErr error
+	}{
+		{name: "links by name", project: "web"},
+		{name: "unknown project", project: "missing", wantErr: api.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := useTestEnv(t)
+			server := apitest.NewServer(t)
+			server.AddUser(testEmail, testPassword)
+			logIn(t, server)
+			project := server.AddProject(testEmail, "web")
+
+			_, err := runCommand(t, server.Client(), "link", tt.project)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			binding, loadErr := link.Load(dir)
+			if tt.wantErr != nil {
+				if loadErr == nil {
+					t.Error("directory linked after a failed lookup")
+				}
+				return
+			}
+			if loadErr != nil {
+				t.Fatal(loadErr)
+			}
+			if binding.ProjectID != project.ID {
+				t.Errorf("linked project = %s, want %s", binding.ProjectID, project.ID)
+			}
+		})
+	}
+}
+
+// TestLinkedProjectSurvivesRename tests that commands without a project
+// argument use the linked project after it was renamed, even when another
+// project took its old name
+func TestLinkedProjectSurvivesRename(t *testing.T) {
+	useTestEnv(t)
+	server := apitest.NewServer(t)
+	server.AddUser(testEmail, testPassword)
+	logIn(t, server)
+	project := server.AddProject(testEmail, "web")
+	if _, err := runCommand(t, server.Client(), "link", "web"); err != nil {
+		t.Fatal(err)
+	}
+
+	server.RenameProject(project.ID, "site")
+	server.AddProject(testEmail, "web")
+
+	output, err := runCommand(t, server.Client(), "status", "--json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var status api.ProjectStatus
+	if err := json.Unmarshal([]byte(output), &status); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, output)
+	}
+	if status.Project.ID != project.ID || status.Project.Name != "site" {
+		t.Fatalf("status of %s (%s), want %s (site)", status.Project.ID, status.Project.Name, project.ID)
+	}
+}
+
+// TestTokensCmds tests creating, listing and revoking API tokens
+func TestTokensCmds(t *testing.T) {
+	useTestEnv(t)
+	server := apitest.NewServer(t)
+	server.AddUser(testEmail, testPassword)
+	logIn(t, server)
+	client := server.Client()
+
+	// Piped output is just the secret
+	secret, err := runCommand(t, client, "tokens", "create", "--name", "ci", "--expires", "never")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokens := server.APITokens()
+	if len(tokens) != 1 || !strings.HasPrefix(strings.TrimSpace(secret), tokens[0].Prefix) {
+		t.Fatalf("created %+v, printed %q", tokens, secret)
+	}
+
+	output, err := runCommand(t, client, "tokens", "list")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(output, tokens[0].ID) || !strings.Contains(output, "ci") {
+		t.Errorf("list doesn't show the token:\n%s", output)
+	}
+
+	if _, err := runCommand(t, client, "tokens", "revoke", tokens[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if tokens := server.APITokens(); len(tokens) != 0 {
+		t.Errorf("tokens after revoke = %+v", tokens)
+	}
+
+	_, err = runCommand(t, client, "tokens", "revoke", tokens[0].ID)
+	if !errors.Is(err, api.ErrNotFound) {
+		t.Errorf("revoking twice: error = %v, want ErrNotFound", err)
+	}
+}
+
+// setUpProjects creates the projects web, docs and old with deployments,
+// and a config file with the apps web, docs (disabled) and blog
+func setUpProjects(t *testing.T, server *apitest.Server, dir string) {
+	t.Helper()
+	server.AddUser(testEmail, testPassword)
+	logIn(t, server)
+	web := server.AddProject(testEmail, "web")
+	server.AddDeployment(web.ID, godeploy.StatusFailed)
+	server.AddDeployment(web.ID, godeploy.StatusSuccess)
+	docs := server.AddProject(testEmail, "docs")
+	server.AddDeployment(docs.ID, godeploy.StatusFailed)
+	server.AddProject(testEmail, "old")
+
+	config := `{"apps": [
+		{"name": "web", "source_dir": "dist", "enabled": true},
+		{"name": "docs", "source_dir": "docs", "enabled": false},
+		{"name": "blog", "source_dir": "blog", "enabled": true}
+	]}`
+	if err := os.WriteFile(filepath.Join(dir, "godeploy.config.json"), []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// outputRow returns the line of a table whose first column is name
+func outputRow(output, name string) string {
+	for _, line := range strings.Split(output, "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 && fields[0] == name {
+			return line
+		}
+	}
+	return ""
+}
+
+// TestProjectsCmd tests the rows and columns projects lists
+func TestProjectsCmd(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		// wantRows maps the names of the listed projects to a substring of
+		// their row
+		wantRows map[string]string
+	}{
+		{
+			name:     "lists projects with their last deployment",
+			args:     []string{"projects"},
+			wantRows: map[string]string{"web": "success", "docs": "failed", "old": "-"},
+		},
+		{
+			name:     "enabled apps",
+			args:     []string{"projects", "--filter", "enabled"},
+			wantRows: map[string]string{"web": "success"},
+		},
+		{
+			name:     "disabled apps",
+			args:     []string{"projects", "--filter", "disabled"},
+			wantRows: map[string]string{"docs": "failed"},
+		},
+		{
+			name: "compares with the config",
+			args: []string{"projects", "--local"},
+			wantRows: map[string]string{
+				"web":  "enabled",
+				"docs": "disabled",
+				"old":  "not in config",
+				"blog": "not deployed",
+			},
+		},
+		{
+			name:     "enabled apps compared with the config",
+			args:     []string{"projects", "--local", "--filter", "enabled"},
+			wantRows: map[string]string{"web": "enabled", "blog": "not deployed"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := useTestEnv(t)
+			server := apitest.NewServer(t)
+			setUpProjects(t, server, dir)
+
+			output, err := runCommand(t, server.Client(), tt.args...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, name := range []string{"web", "docs", "old", "blog"} {
+				row := outputRow(output, name)
+				want, listed := tt.wantRows[name]
+				if listed != (row != "") {
+					t.Errorf("%s listed = %v, want %v:\n%s", name, row != "", listed, output)
+				} else if listed && !strings.Contains(row, want) {
+					t.Errorf("row of %s doesn't contain %q: %s", name, want, row)
+				}
+			}
+		})
+	}
+}
+
+// TestProjectsCmdJSON tests that --json prints the projects with their
+// last deployment and app
+func TestProjectsCmdJSON(t *testing.T) {
+	dir := useTestEnv(t)
+	server := apitest.NewServer(t)
+	setUpProjects(t, server, dir)
+
+	output, err := runCommand(t, server.Client(), "projects", "--json", "--local")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var listings []projectListing
+	if err := json.Unmarshal([]byte(output), &listings); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, output)
+	}
+	byName := map[string]projectListing{}
+	for _, listing := range listings {
+		byName[listing.Name] = listing
+	}
+
+	if web := byName["web"]; web.Project == nil || web.LastDeployment == nil ||
+		web.LastDeployment.Status != godeploy.StatusSuccess || web.App == nil {
+		t.Errorf("web = %+v", web)
+	}
+	if old := byName["old"]; old.Project == nil || old.LastDeployment != nil || old.App != nil {
+		t.Errorf("old = %+v", old)
+	}
+	if blog := byName["blog"]; blog.Project != nil || blog.App == nil {
+		t.Errorf("blog = %+v", blog)
+	}
+}
+
+// TestProjectsCmdWithoutDeploymentHistory tests that projects are listed
+// when the API can't list deployments
+func TestProjectsCmdWithoutDeploymentHistory(t *testing.T) {
+	dir := useTestEnv(t)
+	server := apitest.NewServer(t)
+	setUpProjects(t, server, dir)
+	server.Fail("GET /api/projects/{id}/deployments", http.StatusNotImplemented, "Not implemented")
+
+	output, err := runCommand(t, server.Client(), "projects")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if row := outputRow(output, "web"); row == "" || strings.Contains(row, "success") {
+		t.Errorf("row of web = %q", row)
+	}
+}
+
+// TestStatusProjectCmd tests that status shows the active deployment with
+// its commit and deployer
+func TestStatusProjectCmd(t *testing.T) {
+	dir := useTestEnv(t)
+	server := apitest.NewServer(t)
+	server.AddUser(testEmail, testPassword)
+	logIn(t, server)
+	writeSite(t, dir, "web")
+	client := server.Client()
+
+	_, err := runCommand(t, client, "deploy", "--no-git", "--commit-sha", "0123456789abcdef",
+		"--commit-branch", "main", "--commit-message", "Fix the header\n\nIt overlapped the menu.")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := runCommand(t, client, "status", "web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Project: web", "spa.godeploy.app", "0123456 on main", "Fix the header", testEmail, "healthy"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output doesn't contain %q:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "overlapped") {
+		t.Errorf("output contains the commit message body:\n%s", output)
+	}
+}
+
+// TestStatusProjectCmdWatch tests that --watch polls until the in-progress
+// deployment finishes
+func TestStatusProjectCmdWatch(t *testing.T) {
+	useTestEnv(t)
+	server := apitest.NewServer(t)
+	server.AddUser(testEmail, testPassword)
+	logIn(t, server)
+	project := server.AddProject(testEmail, "web")
+	pending := server.AddDeployment(project.ID, godeploy.StatusPending)
+
+	interval := statusWatchInterval
+	statusWatchInterval = 10 * time.Millisecond
+	t.Cleanup(func() { statusWatchInterval = interval })
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		server.SetDeploymentStatus(pending.ID, godeploy.StatusSuccess)
+	}()
+
+	output, err := runCommand(t, server.Client(), "status", "web", "--watch", "--json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each change is printed as another JSON document
+	var statuses []api.ProjectStatus
+	decoder := json.NewDecoder(strings.NewReader(output))
+	for decoder.More() {
+		var status api.ProjectStatus
+		if err := decoder.Decode(&status); err != nil {
+			t.Fatalf("invalid JSON: %v\n%s", err, output)
+		}
+		statuses = append(statuses, status)
+	}
+	if len(statuses) != 2 {
+		t.Fatalf("printed %d statuses, want 2:\n%s", len(statuses), output)
+	}
+	if statuses[0].InProgress == nil || statuses[0].Active != nil {
+		t.Errorf("first status = %+v, want the pending deployment only", statuses[0])
+	}
+	if statuses[1].InProgress != nil || statuses[1].Active == nil || statuses[1].Active.ID != pending.ID {
+		t.Errorf("last status = %+v, want %s active", statuses[1], pending.ID)
+	}
+}
+
+// TestStatusProjectCmdWatchRetries tests that --watch keeps polling while
+// the API is unavailable
+func TestStatusProjectCmdWatchRetries(t *testing.T) {
+	useTestEnv(t)
+	server := apitest.NewServer(t)
+	server.AddUser(testEmail, testPassword)
+	logIn(t, server)
+	project := server.AddProject(testEmail, "web")
+	pending := server.AddDeployment(project.ID, godeploy.StatusPending)
+
+	interval := statusWatchInterval
+	statusWatchInterval = 10 * time.Millisecond
+	t.Cleanup(func() { statusWatchInterval = interval })
+	const route = "GET /api/projects/{id}/status"
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		server.Fail(route, http.StatusServiceUnavailable, "Service Unavailable")
+		time.Sleep(50 * time.Millisecond)
+		server.SetDeploymentStatus(pending.ID, godeploy.StatusSuccess)
+		server.Recover(route)
+	}()
+
+	output, err := runCommand(t, server.Client(), "status", "web", "--watch", "--json")
+	if err != nil {
+		t.Fatalf("Expected the watch to outlast the outage, got %v", err)
+	}
+	if count := strings.Count(output, `"project"`); count != 2 {
+		t.Fatalf("printed %d statuses, want 2:\n%s", count, output)
+	}
+}
+
+// TestRenderedRows tests that lines wider than the terminal count as the
+// rows they wrap to
+func TestRenderedRows(t *testing.T) {
+	tests := []struct {
+		text  string
+		width int
+		want  int
+	}{
+		{"one\ntwo\n", 80, 2},
+		{"one\ntwo\n", 0, 2},
+		{strings.Repeat("x", 100) + "\n", 40, 3},
+		{strings.Repeat("x", 80) + "\n", 80, 1},
+		{"\033[1m" + strings.Repeat("x", 40) + "\033[0m\n\n", 40, 2},
+	}
+	for _, tt := range tests {
+		if got := renderedRows(tt.text, tt.width); got != tt.want {
+			t.Errorf("renderedRows(%q, %d) = %d, want %d", tt.text, tt.width, got, tt.want)
+		}
+	}
+}
+
+// setUpDeployments deploys the site web three times, twice from main and
+// once from feature, and records a failed deployment
+func setUpDeployments(t *testing.T, server *apitest.Server, dir string) {
+	t.Helper()
+	server.AddUser(testEmail, testPassword)
+	logIn(t, server)
+	writeSite(t, dir, "web")
+	for _, commit := range []struct{ sha, branch, message string }{
+		{"1111111111", "main", "Add the landing page"},
+		{"2222222222", "feature", "Try a dark theme"},
+		{"3333333333", "main", "Fix the header\n\nIt overlapped the menu."},
+	} {
+		_, err := runCommand(t, server.Client(), "deploy", "--no-git",
+			"--commit-sha", commit.sha, "--commit-branch", commit.branch, "--commit-message", commit.message)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	server.AddDeployment(server.Projects()[0].ID, api.StatusFailed)
+}
+
+// TestDeploymentsCmd tests the filters of deployments
+func TestDeploymentsCmd(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		// wantRows are substrings of the rows, newest first
+		wantRows []string
+	}{
+		{
+			name:     "lists deployments newest first",
+			args:     []string{"deployments", "web"},
+			wantRows: []string{"failed", "3333333 Fix the header", "2222222 Try a dark theme", "1111111 Add the landing page"},
+		},
+		{
+			name:     "branch",
+			args:     []string{"deployments", "web", "--branch", "main"},
+			wantRows: []string{"3333333", "1111111"},
+		},
+		{
+			name:     "status",
+			args:     []string{"deployments", "web", "--status", "failed"},
+			wantRows: []string{"failed"},
+		},
+		{
+			name:     "author",
+			args:     []string{"deployments", "web", "--author", testEmail, "--status", "success"},
+			wantRows: []string{"3333333", "2222222", "1111111"},
+		},
+		{
+			name: "other author",
+			args: []string{"deployments", "web", "--author", "someone@example.com"},
+		},
+		{
+			name:     "since",
+			args:     []string{"deployments", "web", "--since", "1h", "--limit", "2"},
+			wantRows: []string{"failed", "3333333"},
+		},
+		{
+			name: "until",
+			args: []string{"deployments", "web", "--until", "2000-01-31"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := useTestEnv(t)
+			server := apitest.NewServer(t)
+			setUpDeployments(t, server, dir)
+
+			output, err := runCommand(t, server.Client(), tt.args...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var rows []string
+			for _, line := range strings.Split(output, "\n") {
+				if strings.HasPrefix(line, "deploy-") {
+					rows = append(rows, line)
+				}
+			}
+			if len(rows) != len(tt.wantRows) {
+				t.Fatalf("listed %d deployments, want %d:\n%s", len(rows), len(tt.wantRows), output)
+			}
+			for i, want := range tt.wantRows {
+				if !strings.Contains(rows[i], want) {
+					t.Errorf("row %d doesn't contain %q: %s", i, want, rows[i])
+				}
+			}
+		})
+	}
+}
+
+// TestDeploymentsCmdCursor tests that --cursor continues where a page ended
+func TestDeploymentsCmdCursor(t *testing.T) {
+	dir := useTestEnv(t)
+	server := apitest.NewServer(t)
+	setUpDeployments(t, server, dir)
+	client := server.Client()
+
+	var ids []string
+	cursor := ""
+	for page := 0; page < 3; page++ {
+		args := []string{"deployments", "web", "--json", "--limit", "3"}
+		if cursor != "" {
+			args = append(args, "--cursor", cursor)
+		}
+		output, err := runCommand(t, client, args...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var list api.DeploymentList
+		if err := json.Unmarshal([]byte(output), &list); err != nil {
+			t.Fatalf("invalid JSON: %v\n%s", err, output)
+		}
+		for _, deployment := range list.Deployments {
+			ids = append(ids, deployment.ID)
+		}
+		if cursor = list.NextCursor; cursor == "" {
+			break
+		}
+	}
+
+	var want []string
+	deployments := server.Deployments(server.Projects()[0].ID)
+	for i := len(deployments) - 1; i >= 0; i-- {
+		want = append(want, deployments[i].ID)
+	}
+	if strings.Join(ids, ",") != strings.Join(want, ",") {
+		t.Errorf("pages listed %v, want %v", ids, want)
+	}
+}
+
+// TestDeploymentsCmdFormat tests rendering deployments with --format
+func TestDeploymentsCmdFormat(t *testing.T) {
+	dir := useTestEnv(t)
+	server := apitest.NewServer(t)
+	setUpDeployments(t, server, dir)
+
+	output, err := runCommand(t, server.Client(), "deployments", "web", "--status", "success", "--branch", "main",
+		"--format=- {{.Message}} ({{.Commit}}, {{.Author}})")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "- Fix the header (3333333, " + testEmail + ")\n- Add the landing page (1111111, " + testEmail + ")\n"
+	if output != want {
+		t.Errorf("output = %q, want %q", output, want)
+	}
+}
+
+// TestDeploymentsCmdFormatOverridesSetting tests that --format wins over
+// the output setting and --json wins over --format
+func TestDeploymentsCmdFormatOverridesSetting(t *testing.T) {
+	dir := useTestEnv(t)
+	server := apitest.NewServer(t)
+	setUpDeployments(t, server, dir)
+	t.Setenv("GODEPLOY_OUTPUT", "json")
+
+	output, err := runCommand(t, server.Client(), "deployments", "web", "--limit", "1", "--format={{.Status}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output != "failed\n" {
+		t.Errorf("output = %q, want the template", output)
+	}
+
+	output, err = runCommand(t, server.Client(), "deployments", "web", "--limit", "1", "--format={{.Status}}", "--json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !json.Valid([]byte(output)) {
+		t.Errorf("output isn't JSON:\n%s", output)
+	}
+}
+
+// TestParseTimeFlagDates tests that a date is the start of the day for
+// --since and the last instant of it for --until
+func TestParseTimeFlagDates(t *testing.T) {
+	since, err := parseTimeFlag("since", "2025-01-31")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2025, 1, 31, 0, 0, 0, 0, time.Local); !since.Equal(want) {
+		t.Errorf("since = %v, want %v", since, want)
+	}
+
+	until, err := parseTimeFlag("until", "2025-01-31")
+	if err != nil {
+		t.Fatal(err)
+	}
+	nextDay := time.Date(2025, 2, 1, 0, 0, 0, 0, time.Local)
+	if !until.Before(nextDay) || nextDay.Sub(until) != time.Nanosecond {
+		t.Errorf("until = %v, want the instant before %v", until, nextDay)
+	}
+}
+
+// TestDeploymentsCmdUntilToday tests that --until with today's date keeps
+// the deployments made today
+func TestDeploymentsCmdUntilToday(t *testing.T) {
+	dir := useTestEnv(t)
+	server := apitest.NewServer(t)
+	setUpDeployments(t, server, dir)
+
+	output, err := runCommand(t, server.Client(), "deployments", "web", "--until", time.Now().Format("2006-01-02"), "--format={{.Commit}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(output, "3333333") {
+		t.Errorf("output = %q, want today's deployments", output)
+	}
+}
+
+// setUpLogs creates the project web with two finished deployments that
+// logged three lines each, and returns the deployments
+func setUpLogs(t *testing.T, server *apitest.Server) []api.Deployment {
+	t.Helper()
+	server.AddUser(testEmail, testPassword)
+	logIn(t, server)
+	project := server.AddProject(testEmail, "web")
+	var deployments []api.Deployment
+	for i := 1; i <= 2; i++ {
+		deployment := server.AddDeployment(project.ID, api.StatusSuccess)
+		server.AddLog(deployment.ID, "info", fmt.Sprintf("Uploading release %d", i))
+		server.AddLog(deployment.ID, "warn", fmt.Sprintf("Large bundle in release %d", i))
+		server.AddLog(deployment.ID, "error", fmt.Sprintf("Cache purge of release %d failed", i))
+		deployments = append(deployments, deployment)
+	}
+	return deployments
+}
+
+// TestLogsCmd tests showing the last lines of the deploy logs
+func TestLogsCmd(t *testing.T) {
+	useTestEnv(t)
+	server := apitest.NewServer(t)
+	deployments := setUpLogs(t, server)
+
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{
+			name: "all lines",
+			args: []string{"logs", "web"},
+			want: []string{"INFO  [deploy] Uploading release 1", "WARN  [deploy] Large bundle in release 1", "ERROR [deploy] Cache purge of release 1 failed",
+				"INFO  [deploy] Uploading release 2", "WARN  [deploy] Large bundle in release 2", "ERROR [deploy] Cache purge of release 2 failed"},
+		},
+		{
+			name: "last lines",
+			args: []string{"logs", "web", "-n", "2"},
+			want: []string{"Large bundle in release 2", "Cache purge of release 2 failed"},
+		},
+		{
+			name: "deployment",
+			args: []string{"logs", "web", "--deployment-id", deployments[0].ID},
+			want: []string{"Uploading release 1", "Large bundle in release 1", "Cache purge of release 1 failed"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := runCommand(t, server.Client(), tt.args...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines := strings.Split(strings.TrimSpace(output), "\n")
+			if len(lines) != len(tt.want) {
+				t.Fatalf("printed %d lines, want %d:\n%s", len(lines), len(tt.want), output)
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(lines[i], want) {
+					t.Errorf("line %d = %q, want it to contain %q", i+1, lines[i], want)
+				}
+			}
+		})
+	}
+}
+
+// TestLogsCmdRequestsTail tests that the last lines of a long log take a
+// single request
+func TestLogsCmdRequestsTail(t *testing.T) {
+	useTestEnv(t)
+	server := apitest.NewServer(t)
+	server.AddUser(testEmail, testPassword)
+	logIn(t, server)
+	project := server.AddProject(testEmail, "web")
+	deployment := server.AddDeployment(project.ID, api.StatusSuccess)
+	for i := 1; i <= 250; i++ {
+		server.AddLog(deployment.ID, "info", fmt.Sprintf("Uploaded file %d", i))
+	}
+
+	output, err := runCommand(t, server.Client(), "logs", "web", "-n", "2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], "Uploaded file 249") || !strings.HasSuffix(lines[1], "Uploaded file 250") {
+		t.Fatalf("Expected the last 2 lines, got:\n%s", output)
+	}
+	var logRequests int
+	for _, request := range server.Requests() {
+		if strings.HasSuffix(request, "/logs") {
+			logRequests++
+		}
+	}
+	if logRequests != 1 {
+		t.Errorf("Expected 1 log request, got %d", logRequests)
+	}
+}
+
+// TestLogsCmdJSON tests that --json prints each entry as a line of JSON
+func TestLogsCmdJSON(t *testing.T) {
+	useTestEnv(t)
+	server := apitest.NewServer(t)
+	deployments := setUpLogs(t, server)
+
+	output, err := runCommand(t, server.Client(), "logs", "web", "--json", "--lines", "4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("printed %d lines, want 4:\n%s", len(lines), output)
+	}
+	var entry api.LogEntry
+	if err := json.Unmarshal([]byte(lines[3]), &entry); err != nil {
+		t.Fatalf("invalid JSON line: %v\n%s", err, lines[3])
+	}
+	if entry.Level != "error" || entry.DeploymentID != deployments[1].ID || entry.Cursor == "" {
+		t.Errorf("last entry = %+v, want the error of %s", entry, deployments[1].ID)
+	}
+}
+
+// TestLogsCmdFollow tests that --follow streams new lines until the
+// deployment finishes
+func TestLogsCmdFollow(t *testing.T) {
+	useTestEnv(t)
+	server := apitest.NewServer(t)
+	server.AddUser(testEmail, testPassword)
+	logIn(t, server)
+	project := server.AddProject(testEmail, "web")
+	pending := server.AddDeployment(project.ID, api.StatusPending)
+	server.AddLog(pending.ID, "info", "Received archive")
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		server.AddLog(pending.ID, "info", "Extracting files")
+		time.Sleep(50 * time.Millisecond)
+		server.AddLog(pending.ID, "info", "Deployed")
+		server.SetDeploymentStatus(pending.ID, api.StatusSuccess)
+	}()
+
+	output, err := runCommand(t, server.Client(), "logs", "web", "--follow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	want := []string{"Received archive", "Extracting files", "Deployed", "Deployment finished."}
+	if len(lines) != len(want) {
+		t.Fatalf("printed %d lines, want %d:\n%s", len(lines), len(want), output)
+	}
+	for i := range want {
+		if !strings.HasSuffix(lines[i], want[i]) {
+			t.Errorf("line %d = %q, want it to end with %q", i+1, lines[i], want[i])
+		}
+	}
+}
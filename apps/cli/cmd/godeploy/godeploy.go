@@ -3,23 +3,40 @@ package main
 import (
 	"bufio"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"syscall"
+	"text/tabwriter"
+	"text/template"
+	"time"
 
 	"github.com/alecthomas/kong"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 	"github.com/silvabyte/godeploy/internal/api"
 	"github.com/silvabyte/godeploy/internal/archive"
 	"github.com/silvabyte/godeploy/internal/auth"
+	"github.com/silvabyte/godeploy/internal/build"
 	"github.com/silvabyte/godeploy/internal/cache"
 	"github.com/silvabyte/godeploy/internal/config"
+	"github.com/silvabyte/godeploy/internal/detect"
+	"github.com/silvabyte/godeploy/internal/devserver"
+	"github.com/silvabyte/godeploy/internal/link"
 	"github.com/silvabyte/godeploy/internal/logging"
+	"github.com/silvabyte/godeploy/internal/settings"
 	"github.com/silvabyte/godeploy/internal/theme"
 	"github.com/silvabyte/godeploy/internal/version"
+	"github.com/silvabyte/godeploy/internal/weblogin"
 	"github.com/yarlson/pin"
 	"golang.org/x/term"
 )
@@ -27,7 +44,10 @@ import (
 // CLI represents the command-line interface structure
 var CLI struct {
 	// Global flags
-	Config      string `help:"Path to the SPA configuration file" default:"godeploy.config.json"`
+	Config      string `help:"Path to the SPA configuration file (searched for in parent directories by default)" default:"godeploy.config.json"`
+	Token       string `help:"API token to authenticate with instead of the saved login (for CI)" env:"GODEPLOY_TOKEN"`
+	Profile     string `help:"Auth profile to use (see 'godeploy auth profiles')" env:"GODEPLOY_PROFILE"`
+	Color       string `help:"Colored output: auto, always or never (default: the color setting)" default:""`
 	VersionFlag bool   `name:"version" short:"v" help:"Display the version of godeploy"`
 
 	// Commands
@@ -37,7 +57,7 @@ var CLI struct {
 	Version     VersionCmd       `cmd:"version" help:"Display the version of godeploy"`
 	Projects    ProjectsCmd      `cmd:"projects" help:"List all deployed projects" aliases:"list"`
 	Status      StatusProjectCmd `cmd:"status" help:"Check deployment status for a project"`
-	Logs        LogsCmd          `cmd:"logs" help:"View deployment logs"`
+	Logs        LogsCmd          `cmd:"logs" help:"View deploy and build logs"`
 	Deployments DeploymentsCmd   `cmd:"deployments" help:"View deployment history for a project"`
 	Whoami      WhoamiCmd        `cmd:"whoami" help:"Display current user information"`
 	Rollback    RollbackCmd      `cmd:"rollback" help:"Rollback project to a previous deployment"`
@@ -45,6 +65,7 @@ var CLI struct {
 	Open        OpenCmd          `cmd:"open" help:"Open project URL in browser"`
 	Validate    ValidateCmd      `cmd:"validate" help:"Validate configuration file"`
 	Link        LinkCmd          `cmd:"link" help:"Link local directory to remote project"`
+	Unlink      UnlinkCmd        `cmd:"unlink" help:"Remove the link between this directory and a remote project"`
 	Preview     PreviewCmd       `cmd:"preview" help:"Create a preview deployment"`
 	Diff        DiffCmd          `cmd:"diff" help:"Show differences between local and deployed version"`
 	Env         EnvCmd           `cmd:"env" help:"Manage environment variables"`
@@ -60,25 +81,34 @@ var CLI struct {
 	Compare     CompareCmd       `cmd:"compare" help:"Compare two deployments"`
 	Cache       CacheCmd         `cmd:"cache" help:"Manage CDN cache"`
 	Builds      BuildsCmd        `cmd:"builds" help:"Manage build configuration"`
+	DevServer   DevServerCmd     `cmd:"dev-server" help:"Run a local mock of the GoDeploy API for offline development"`
 }
 
 // InitCmd represents the init command
 type InitCmd struct {
-	Force bool `help:"Overwrite existing config file if it exists" short:"f"`
+	Force     bool `help:"Overwrite existing config file if it exists" short:"f"`
+	Yes       bool `help:"Accept the detected settings without prompting" short:"y"`
+	Workspace bool `help:"Discover deployable apps in a pnpm, npm, yarn or Nx workspace"`
 }
 
 // AuthCmd represents the auth command
 type AuthCmd struct {
-	Login  LoginCmd  `cmd:"" help:"Authenticate with the GoDeploy service" default:"1"`
-	SignUp SignUpCmd `cmd:"" help:"Create a new GoDeploy account"`
-	Status StatusCmd `cmd:"" help:"Check authentication status"`
-	Logout LogoutCmd `cmd:"" help:"Log out from the GoDeploy service"`
+	Login    LoginCmd    `cmd:"" help:"Authenticate with the GoDeploy service" default:"1"`
+	SignUp   SignUpCmd   `cmd:"" help:"Create a new GoDeploy account"`
+	Status   StatusCmd   `cmd:"" help:"Check authentication status"`
+	Logout   LogoutCmd   `cmd:"" help:"Log out from the GoDeploy service"`
+	Profiles ProfilesCmd `cmd:"" help:"Manage auth profiles"`
+	Encrypt  EncryptCmd  `cmd:"" help:"Encrypt stored credentials with a passphrase"`
+	Decrypt  DecryptCmd  `cmd:"" help:"Store credentials unencrypted again"`
+	Password PasswordCmd `cmd:"" help:"Change or reset your password"`
 }
 
 // LoginCmd represents the auth login command
 type LoginCmd struct {
 	Email    string `help:"Email address to authenticate with" default:""`
 	Password string `help:"Password for authentication" default:""`
+	APIURL   string `name:"api-url" help:"API base URL to save with the profile" default:""`
+	Web      bool   `name:"web" help:"Log in through a link emailed to you instead of with a password" default:"false"`
 }
 
 // SignUpCmd represents the auth sign-up command
@@ -88,7 +118,9 @@ type SignUpCmd struct {
 }
 
 // LogoutCmd represents the logout command
-type LogoutCmd struct{}
+type LogoutCmd struct {
+	AllSessions bool `name:"all-sessions" help:"Sign out every session of the account, on all devices" default:"false"`
+}
 
 // StatusCmd represents the status command
 type StatusCmd struct{}
@@ -103,9 +135,9 @@ type DeployCmd struct {
 	CommitURL     string `name:"commit-url" help:"URL to the commit (e.g., GitHub commit link)" default:""`
 	NoGit         bool   `name:"no-git" help:"Disable auto-detection of git metadata" default:"false"`
 	ClearCache    bool   `name:"clear-cache" help:"Clear CDN cache after deployment" default:"false"`
+	Build         bool   `name:"build" help:"Run the app's build command before deploying" default:"false"`
 	DryRun        bool   `name:"dry-run" help:"Preview deployment without actually deploying" default:"false"`
-	Wait          bool   `name:"wait" help:"Wait for deployment to complete" default:"false"`
-	Timeout       string `name:"timeout" help:"Timeout for wait (e.g., 5m, 10m)" default:"10m"`
+	Timeout       string `name:"timeout" help:"Time allowed for uploading the deployment (e.g., 5m, 20m); overrides the deploy-timeout setting" default:""`
 	JSON          bool   `name:"json" help:"Output in JSON format for CI/CD" default:"false"`
 }
 
@@ -125,19 +157,6 @@ func (v *VersionCmd) Run() error {
 	return nil
 }
 
-// defaultConfig is the default configuration template
-const defaultConfig = `{
-  "apps": [
-    {
-      "name": "yourAppName",
-      "source_dir": "dist",
-      "description": "Your application description",
-      "enabled": true
-    }
-  ]
-}
-`
-
 // Run executes the init command
 func (i *InitCmd) Run() error {
 	// Create a context
@@ -160,6 +179,146 @@ func (i *InitCmd) Run() error {
 	checkCancel()
 	checkSpinner.Stop("Config check complete")
 
+	if i.Workspace {
+		return i.initWorkspace(ctx, configPath)
+	}
+
+	// Create a spinner for detecting the framework
+	detectSpinner := pin.New("Detecting project settings...",
+		pin.WithSpinnerColor(pin.ColorMagenta),
+		pin.WithTextColor(pin.ColorMagenta),
+	)
+	detectCancel := detectSpinner.Start(ctx)
+
+	detected, err := detect.Detect(filepath.Dir(configPath))
+	if err != nil {
+		detectCancel()
+		detectSpinner.Fail("Failed to inspect project")
+		return fmt.Errorf("failed to detect project settings: %w", err)
+	}
+
+	detectCancel()
+	if detected.Framework != "" {
+		detectSpinner.Stop(fmt.Sprintf("Detected %s", detected.Framework))
+	} else {
+		detectSpinner.Stop("No framework detected, using defaults")
+	}
+
+	app := config.App{
+		Name:        detected.AppName,
+		SourceDir:   detected.OutputDir,
+		Description: detected.Description,
+		Enabled:     true,
+	}
+	if detected.BuildCommand != "" {
+		app.Build = &config.Build{Command: detected.BuildCommand}
+	}
+
+	fmt.Println(formatDetectedApp(detected, app))
+	for _, warning := range detected.Warnings {
+		fmt.Println(theme.WarningMsg(warning))
+	}
+
+	// Let the user review the proposal unless running non-interactively
+	if !i.Yes && term.IsTerminal(int(os.Stdin.Fd())) {
+		app, err = confirmApp(bufio.NewReader(os.Stdin), app)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := writeInitConfig(ctx, configPath, []config.App{app}); err != nil {
+		return err
+	}
+	if app.Build != nil {
+		fmt.Println(theme.MutedMsg("Run 'godeploy deploy --build' to build and deploy your app."))
+	} else {
+		fmt.Println(theme.MutedMsg("Build your app, then run 'godeploy deploy'."))
+	}
+	return nil
+}
+
+// initWorkspace generates one app per deployable package in a monorepo
+func (i *InitCmd) initWorkspace(ctx context.Context, configPath string) error {
+	discoverSpinner := pin.New("Discovering workspace packages...",
+		pin.WithSpinnerColor(pin.ColorMagenta),
+		pin.WithTextColor(pin.ColorMagenta),
+	)
+	discoverCancel := discoverSpinner.Start(ctx)
+
+	configDir, err := filepath.Abs(filepath.Dir(configPath))
+	if err != nil {
+		discoverCancel()
+		discoverSpinner.Fail("Failed to resolve config directory")
+		return fmt.Errorf("failed to resolve config directory: %w", err)
+	}
+
+	ws, err := detect.FindWorkspace(configDir)
+	if err == nil && ws == nil {
+		err = fmt.Errorf("no pnpm, npm, yarn or Nx workspace found in %s", configDir)
+	}
+	var discovered []detect.WorkspaceApp
+	if err == nil {
+		discovered, err = ws.DiscoverApps()
+	}
+	if err == nil && len(discovered) == 0 {
+		err = fmt.Errorf("none of the %d workspace packages look like a deployable SPA", len(ws.Packages))
+	}
+	if err != nil {
+		discoverCancel()
+		discoverSpinner.Fail("Workspace discovery failed")
+		return err
+	}
+
+	discoverCancel()
+	discoverSpinner.Stop(fmt.Sprintf("Found %d deployable apps in %d %s workspace packages", len(discovered), len(ws.Packages), ws.Kind))
+
+	apps := make([]config.App, 0, len(discovered))
+	for _, found := range discovered {
+		// Paths are written relative to the config file
+		sourceDir, err := filepath.Rel(configDir, filepath.Join(ws.Root, found.OutputPath))
+		if err != nil {
+			return fmt.Errorf("failed to resolve output path for %s: %w", found.AppName, err)
+		}
+		app := config.App{
+			Name:        found.AppName,
+			SourceDir:   filepath.ToSlash(sourceDir),
+			Description: found.Description,
+			Enabled:     true,
+		}
+		if found.BuildCommand != "" {
+			app.Build = &config.Build{Command: found.BuildCommand}
+			if workingDir, err := filepath.Rel(configDir, filepath.Join(ws.Root, found.WorkingDir)); err == nil && workingDir != "." {
+				app.Build.WorkingDir = filepath.ToSlash(workingDir)
+			}
+		}
+		apps = append(apps, app)
+	}
+
+	fmt.Println(formatWorkspaceApps(discovered, apps))
+	for _, found := range discovered {
+		for _, warning := range found.Warnings {
+			fmt.Println(theme.WarningMsg(fmt.Sprintf("%s: %s", found.AppName, warning)))
+		}
+	}
+
+	// Let the user pick which apps to keep unless running non-interactively
+	if !i.Yes && term.IsTerminal(int(os.Stdin.Fd())) {
+		apps, err = confirmWorkspaceApps(bufio.NewReader(os.Stdin), apps)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := writeInitConfig(ctx, configPath, apps); err != nil {
+		return err
+	}
+	fmt.Println(theme.MutedMsg("Run 'godeploy deploy --build --project <name>' to build and deploy an app."))
+	return nil
+}
+
+// writeInitConfig writes the config file generated by init
+func writeInitConfig(ctx context.Context, configPath string, apps []config.App) error {
 	// Create a spinner for creating the config file
 	createSpinner := pin.New("Creating configuration file...",
 		pin.WithSpinnerColor(pin.ColorMagenta),
@@ -168,7 +327,7 @@ func (i *InitCmd) Run() error {
 	createCancel := createSpinner.Start(ctx)
 
 	// Create the config file
-	if err := os.WriteFile(configPath, []byte(defaultConfig), 0o644); err != nil {
+	if err := config.SaveConfig(&config.SpaConfig{Apps: apps}, configPath); err != nil {
 		createCancel()
 		createSpinner.Fail("Failed to create config file")
 		return fmt.Errorf("failed to create config file: %w", err)
@@ -177,26 +336,178 @@ func (i *InitCmd) Run() error {
 	createCancel()
 	createSpinner.Stop("Configuration created")
 	fmt.Println(theme.SuccessMsg(fmt.Sprintf("Created config file: %s", configPath)))
-	fmt.Println(theme.MutedMsg("You can now edit this file to configure your SPAs."))
 	return nil
 }
 
+// formatWorkspaceApps renders the apps discovered in a workspace
+func formatWorkspaceApps(discovered []detect.WorkspaceApp, apps []config.App) string {
+	blocks := make([]string, 0, len(apps))
+	for idx, app := range apps {
+		buildCommand := "none"
+		if app.Build != nil {
+			buildCommand = app.Build.Command
+		}
+		blocks = append(blocks, lipgloss.JoinVertical(lipgloss.Left,
+			theme.KeyValue("App Name", app.Name),
+			theme.KeyValue("Framework", discovered[idx].Framework),
+			theme.KeyValue("Package", discovered[idx].Dir),
+			theme.KeyValue("Build Command", buildCommand),
+			theme.KeyValue("Output Dir", app.SourceDir),
+		))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		theme.TitleStyle.Margin(1, 0).Render("Discovered Apps"),
+		theme.BoxStyle.Margin(1, 0).Render(strings.Join(blocks, "\n\n")),
+	)
+}
+
+// confirmWorkspaceApps asks the user to accept all discovered apps or pick them one by one
+func confirmWorkspaceApps(reader *bufio.Reader, apps []config.App) ([]config.App, error) {
+	answer, err := prompt(reader, "Add all of these apps? [Y/n]: ")
+	if err != nil {
+		return nil, err
+	}
+	if answer == "" || strings.HasPrefix(strings.ToLower(answer), "y") {
+		return apps, nil
+	}
+
+	var selected []config.App
+	for _, app := range apps {
+		answer, err := prompt(reader, fmt.Sprintf("Add '%s' (%s)? [Y/n]: ", app.Name, app.SourceDir))
+		if err != nil {
+			return nil, err
+		}
+		if answer == "" || strings.HasPrefix(strings.ToLower(answer), "y") {
+			selected = append(selected, app)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no apps selected")
+	}
+	return selected, nil
+}
+
+// formatDetectedApp renders the settings proposed by init
+func formatDetectedApp(detected *detect.Result, app config.App) string {
+	framework := detected.Framework
+	if framework == "" {
+		framework = "unknown"
+	}
+	if detected.PackageManager != "" {
+		framework = fmt.Sprintf("%s (%s)", framework, detected.PackageManager)
+	}
+	buildCommand := "none"
+	if app.Build != nil {
+		buildCommand = app.Build.Command
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		theme.KeyValue("Framework", framework),
+		theme.KeyValue("App Name", app.Name),
+		theme.KeyValue("Build Command", buildCommand),
+		theme.KeyValue("Output Dir", app.SourceDir),
+	)
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		theme.TitleStyle.Margin(1, 0).Render("Detected Settings"),
+		theme.BoxStyle.Margin(1, 0).Render(content),
+	)
+}
+
+// confirmApp asks the user to accept the proposed app or edit each field
+func confirmApp(reader *bufio.Reader, app config.App) (config.App, error) {
+	answer, err := prompt(reader, "Use these settings? [Y/n]: ")
+	if err != nil {
+		return app, err
+	}
+	if answer == "" || strings.HasPrefix(strings.ToLower(answer), "y") {
+		return app, nil
+	}
+
+	if app.Name, err = promptWithDefault(reader, "App name", app.Name); err != nil {
+		return app, err
+	}
+	buildCommand := ""
+	if app.Build != nil {
+		buildCommand = app.Build.Command
+	}
+	if buildCommand, err = promptWithDefault(reader, "Build command (\"-\" for none)", buildCommand); err != nil {
+		return app, err
+	}
+	if buildCommand == "" || buildCommand == "-" {
+		app.Build = nil
+	} else {
+		app.Build = &config.Build{Command: buildCommand}
+	}
+	if app.SourceDir, err = promptWithDefault(reader, "Output directory", app.SourceDir); err != nil {
+		return app, err
+	}
+
+	if app.Name == "" {
+		return app, fmt.Errorf("app name is required")
+	}
+	if app.SourceDir == "" {
+		return app, fmt.Errorf("output directory is required")
+	}
+	return app, nil
+}
+
+// prompt prints label and reads a trimmed line from reader
+func prompt(reader *bufio.Reader, label string) (string, error) {
+	fmt.Print(label)
+	input, err := reader.ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("failed to read input: %w", err)
+	}
+	return strings.TrimSpace(input), nil
+}
+
+// promptWithDefault prompts for a value, returning def when the input is empty
+func promptWithDefault(reader *bufio.Reader, label, def string) (string, error) {
+	if def != "" {
+		label = fmt.Sprintf("%s [%s]", label, def)
+	}
+	value, err := prompt(reader, label+": ")
+	if err != nil {
+		return "", err
+	}
+	if value == "" {
+		return def, nil
+	}
+	return value, nil
+}
+
 // Run executes the login command
-func (l *LoginCmd) Run() error {
+func (l *LoginCmd) Run(ctx context.Context, apiClient api.API) error {
 	logging.Info().Msg("login command started")
 
 	// Check if already authenticated with a LOCAL token check only
 	// We don't want to make network calls here - if token is expired, let user login again
 	logging.Debug().Msg("checking existing authentication (local only)")
-	apiClient := api.NewClient()
 	tokenManager := createTokenManager(apiClient)
 
+	profile, err := auth.ActiveProfile()
+	if err != nil {
+		return fmt.Errorf("error loading auth profiles: %w", err)
+	}
+
+	// A new API URL is used for this login and saved with the profile
+	if l.APIURL != "" {
+		apiURL, err := normalizeAPIURL(l.APIURL)
+		if err != nil {
+			return err
+		}
+		l.APIURL = apiURL
+		apiClient.SetAPIURL(apiURL)
+	}
+
 	// First check if we have a token locally
 	token, _ := auth.GetAuthToken()
 	if token != "" {
 		// Check if token is NOT expired (without network call)
 		expired, err := tokenManager.IsTokenExpired(token, 0)
-		if err == nil && !expired {
+		if err == nil && !expired && l.APIURL == "" {
 			logging.Info().Msg("user already authenticated with valid token")
 			fmt.Println("You are already authenticated. To log out, run 'godeploy auth logout'.")
 			return nil
@@ -204,37 +515,13 @@ func (l *LoginCmd) Run() error {
 		logging.Debug().Bool("expired", expired).Msg("existing token is expired or invalid, proceeding with login")
 	}
 
-	// Get email from argument or stored config
-	email := l.Email
-	if email == "" {
-		// Try to get email from saved config
-		logging.Debug().Msg("checking for saved email")
-		savedEmail, err := auth.GetUserEmail()
-		if err != nil {
-			logging.Err(err, "failed to retrieve saved email")
-			return fmt.Errorf("error retrieving saved email: %w", err)
-		}
+	if l.Web {
+		return l.runWeb(ctx, apiClient, profile)
+	}
 
-		if savedEmail != "" {
-			email = savedEmail
-			logging.Debug().Str("email", email).Msg("using saved email")
-			fmt.Printf("Using saved email: %s\n", email)
-		} else {
-			// Prompt for email if not provided
-			logging.Debug().Msg("prompting for email")
-			fmt.Print("Email: ")
-			reader := bufio.NewReader(os.Stdin)
-			emailInput, err := reader.ReadString('\n')
-			if err != nil {
-				logging.Err(err, "failed to read email input")
-				return fmt.Errorf("failed to read email: %w", err)
-			}
-			email = strings.TrimSpace(emailInput)
-			if email == "" {
-				logging.Warn().Msg("empty email provided")
-				return fmt.Errorf("email is required for authentication")
-			}
-		}
+	email, err := loginEmail(l.Email)
+	if err != nil {
+		return err
 	}
 
 	logging.Info().Str("email", email).Msg("login attempt started")
@@ -257,9 +544,6 @@ func (l *LoginCmd) Run() error {
 		}
 	}
 
-	// Create a context with a timeout
-	ctx := context.Background()
-
 	// Create a spinner for authentication
 	authSpinner := pin.New(fmt.Sprintf("Authenticating %s...", email),
 		pin.WithSpinnerColor(pin.ColorMagenta),
@@ -269,7 +553,7 @@ func (l *LoginCmd) Run() error {
 
 	// Authenticate with email and password (reuse apiClient from above)
 	logging.Debug().Str("email", email).Msg("calling SignIn API")
-	signInResp, err := apiClient.SignIn(email, password)
+	signInResp, err := apiClient.SignIn(ctx, email, password)
 
 	authCancel()
 
@@ -278,11 +562,13 @@ func (l *LoginCmd) Run() error {
 		logging.Error().Err(err).Str("email", email).Msg("authentication failed")
 
 		// Check for specific error messages
-		if strings.Contains(err.Error(), "Invalid credentials") || strings.Contains(err.Error(), "Invalid email or password") {
+		switch {
+		case errors.Is(err, context.Canceled):
+		case errors.Is(err, api.ErrUnauthorized):
 			fmt.Println(theme.ErrorMsg("Invalid email or password. Please check your credentials and try again."))
-		} else if strings.Contains(err.Error(), "User not found") {
-			fmt.Println(theme.ErrorMsg("User not found. Please check your email address."))
-		} else {
+		case errors.Is(err, api.ErrTimeout), errors.Is(err, api.ErrUnavailable):
+			fmt.Println(theme.ErrorMsg("Could not reach GoDeploy. Check your connection and try again."))
+		default:
 			fmt.Println(theme.ErrorMsg(fmt.Sprintf("Authentication failed: %v", err)))
 		}
 		return err
@@ -291,6 +577,11 @@ func (l *LoginCmd) Run() error {
 	authSpinner.Stop("Authentication successful")
 	logging.Info().Str("email", email).Msg("authentication successful")
 
+	return l.saveLogin(ctx, profile, email, signInResp.Token, signInResp.RefreshToken, signInResp.User.Email)
+}
+
+// saveLogin stores the tokens of a successful login in the active profile
+func (l *LoginCmd) saveLogin(ctx context.Context, profile, email, accessToken, refreshToken, userEmail string) error {
 	// Create a spinner for saving the token
 	saveSpinner := pin.New("Saving authentication token...",
 		pin.WithSpinnerColor(pin.ColorMagenta),
@@ -300,7 +591,7 @@ func (l *LoginCmd) Run() error {
 
 	// Save both tokens
 	logging.Debug().Msg("saving tokens")
-	if err := auth.SetTokens(signInResp.Token, signInResp.RefreshToken); err != nil {
+	if err := auth.SetTokens(accessToken, refreshToken); err != nil {
 		saveCancel()
 		saveSpinner.Fail("Failed to save tokens")
 		logging.Err(err, "failed to save tokens")
@@ -308,12 +599,22 @@ func (l *LoginCmd) Run() error {
 	}
 
 	// Save email for future authentication
-	logging.Debug().Msg("saving email")
-	if err := auth.SetUserEmail(email); err != nil {
-		saveCancel()
-		saveSpinner.Fail("Failed to save email")
-		logging.Err(err, "failed to save email")
-		return fmt.Errorf("failed to save email: %w", err)
+	if email != "" {
+		logging.Debug().Msg("saving email")
+		if err := auth.SetUserEmail(email); err != nil {
+			saveCancel()
+			saveSpinner.Fail("Failed to save email")
+			logging.Err(err, "failed to save email")
+			return fmt.Errorf("failed to save email: %w", err)
+		}
+	}
+
+	if l.APIURL != "" {
+		if err := auth.SetAPIURL(l.APIURL); err != nil {
+			saveCancel()
+			saveSpinner.Fail("Failed to save API URL")
+			return fmt.Errorf("failed to save API URL: %w", err)
+		}
 	}
 
 	saveCancel()
@@ -321,17 +622,116 @@ func (l *LoginCmd) Run() error {
 	logging.Info().Str("email", email).Msg("login completed successfully")
 
 	fmt.Println(theme.SuccessMsg("Authentication successful! You are now logged in."))
-	if signInResp.User.Email != "" {
-		fmt.Println(theme.MutedMsg(fmt.Sprintf("Logged in as: %s", signInResp.User.Email)))
+	if userEmail != "" {
+		fmt.Println(theme.MutedMsg(fmt.Sprintf("Logged in as: %s", userEmail)))
+	}
+	if profile != auth.DefaultProfile {
+		fmt.Println(theme.MutedMsg(fmt.Sprintf("Profile: %s", profile)))
+		if current, _ := currentProfile(); current != profile {
+			fmt.Println(theme.MutedMsg(fmt.Sprintf("Run 'godeploy auth profiles use %s' to make it the default, or pass --profile %s.", profile, profile)))
+		}
 	}
 
 	return nil
 }
 
+// loginEmail returns the email to log in with: flag if set, otherwise the
+// saved email, otherwise one read from stdin
+func loginEmail(flag string) (string, error) {
+	email := flag
+	if email == "" {
+		// Try to get email from saved config
+		logging.Debug().Msg("checking for saved email")
+		savedEmail, err := auth.GetUserEmail()
+		if err != nil {
+			logging.Err(err, "failed to retrieve saved email")
+			return "", fmt.Errorf("error retrieving saved email: %w", err)
+		}
+
+		if savedEmail != "" {
+			email = savedEmail
+			logging.Debug().Str("email", email).Msg("using saved email")
+			fmt.Printf("Using saved email: %s\n", email)
+		} else {
+			// Prompt for email if not provided
+			logging.Debug().Msg("prompting for email")
+			fmt.Print("Email: ")
+			reader := bufio.NewReader(os.Stdin)
+			emailInput, err := reader.ReadString('\n')
+			if err != nil {
+				logging.Err(err, "failed to read email input")
+				return "", fmt.Errorf("failed to read email: %w", err)
+			}
+			email = strings.TrimSpace(emailInput)
+			if email == "" {
+				logging.Warn().Msg("empty email provided")
+				return "", fmt.Errorf("email is required for authentication")
+			}
+		}
+	}
+	return email, nil
+}
+
+// webLoginTimeout is how long the browser login waits for the callback
+const webLoginTimeout = 5 * time.Minute
+
+// runWeb logs in through the browser: /api/auth/init emails a magic link
+// whose /magic-link redirect brings the session back to a listener on a
+// random loopback port
+func (l *LoginCmd) runWeb(ctx context.Context, apiClient api.API, profile string) error {
+	email, err := loginEmail(l.Email)
+	if err != nil {
+		return err
+	}
+
+	flow, err := weblogin.Start()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = flow.Close()
+	}()
+
+	logging.Info().Str("email", email).Msg("sending magic link for browser login")
+	if _, err := apiClient.InitAuth(ctx, email, flow.RedirectURI()); err != nil {
+		fmt.Println(theme.ErrorMsg(fmt.Sprintf("Failed to send login link: %v", err)))
+		return err
+	}
+	fmt.Println(theme.InfoMsg(fmt.Sprintf("We sent a login link to %s.", email)))
+	fmt.Println(theme.MutedMsg("Open it on this computer to finish logging in."))
+
+	ctx, cancel := context.WithTimeout(ctx, webLoginTimeout)
+	defer cancel()
+
+	waitSpinner := pin.New("Waiting for the browser...",
+		pin.WithSpinnerColor(pin.ColorMagenta),
+		pin.WithTextColor(pin.ColorMagenta),
+	)
+	waitCancel := waitSpinner.Start(ctx)
+	callback, err := flow.Wait(ctx)
+	waitCancel()
+	if err != nil {
+		waitSpinner.Fail("Browser login failed")
+		if errors.Is(err, context.DeadlineExceeded) {
+			return fmt.Errorf("timed out waiting for the browser login")
+		}
+		return err
+	}
+
+	waitSpinner.Stop("Authentication successful")
+
+	// Magic links don't say who logged in; ask the API
+	userEmail := ""
+	if verifyResp, err := apiClient.VerifyToken(ctx, callback.AccessToken); err == nil && verifyResp.Valid {
+		userEmail = verifyResp.User.Email
+	}
+
+	return l.saveLogin(ctx, profile, email, callback.AccessToken, callback.RefreshToken, userEmail)
+}
+
 // Run executes the signup command
-func (s *SignUpCmd) Run() error {
+func (s *SignUpCmd) Run(ctx context.Context, apiClient api.API) error {
 	// Check if already authenticated with a simple token check using TokenManager
-	apiClient := api.NewClient()
 	tokenManager := createTokenManager(apiClient)
 	if token, err := tokenManager.EnsureValidToken(); err == nil && token != "" {
 		fmt.Println("You are already authenticated. To log out, run 'godeploy auth logout'.")
@@ -386,9 +786,6 @@ func (s *SignUpCmd) Run() error {
 		return fmt.Errorf("password must be at least 8 characters long")
 	}
 
-	// Create a context
-	ctx := context.Background()
-
 	// Create a spinner for registration
 	signUpSpinner := pin.New(fmt.Sprintf("Creating account for %s...", email),
 		pin.WithSpinnerColor(pin.ColorMagenta),
@@ -397,7 +794,7 @@ func (s *SignUpCmd) Run() error {
 	signUpCancel := signUpSpinner.Start(ctx)
 
 	// Create account with email and password (reuse apiClient from above)
-	signUpResp, err := apiClient.SignUp(email, password)
+	signUpResp, err := apiClient.SignUp(ctx, email, password)
 
 	signUpCancel()
 
@@ -405,13 +802,14 @@ func (s *SignUpCmd) Run() error {
 		signUpSpinner.Fail("Registration failed")
 
 		// Check for specific error messages
-		if strings.Contains(err.Error(), "already exists") || strings.Contains(err.Error(), "User already exists") {
+		switch {
+		case errors.Is(err, context.Canceled):
+		case errors.Is(err, api.ErrConflict):
 			fmt.Println(theme.ErrorMsg("An account with this email already exists. Use 'godeploy auth login' to sign in."))
-		} else if strings.Contains(err.Error(), "Invalid email") {
-			fmt.Println(theme.ErrorMsg("Invalid email address. Please provide a valid email."))
-		} else if strings.Contains(err.Error(), "Password must be at least") {
-			fmt.Println(theme.ErrorMsg("Password does not meet requirements. It must be at least 8 characters long."))
-		} else {
+		case errors.Is(err, api.ErrInvalidInput):
+			fmt.Println(theme.ErrorMsg("Invalid email or password. Use a valid email address and a password of at least 8 characters."))
+			fmt.Println(theme.MutedMsg(err.Error()))
+		default:
 			fmt.Println(theme.ErrorMsg(fmt.Sprintf("Registration failed: %v", err)))
 		}
 		return err
@@ -452,16 +850,13 @@ func (s *SignUpCmd) Run() error {
 }
 
 // Run executes the logout command
-func (l *LogoutCmd) Run() error {
-	// Create a context
-	ctx := context.Background()
-
+func (l *LogoutCmd) Run(ctx context.Context, apiClient api.API) error {
 	// Check if authenticated
-	isAuth, err := auth.IsAuthenticated()
+	config, err := auth.LoadAuthConfig()
 	if err != nil {
 		return fmt.Errorf("error checking authentication: %w", err)
 	}
-	if !isAuth {
+	if config.AuthToken == "" {
 		fmt.Println("You are not currently authenticated.")
 		return nil
 	}
@@ -473,6 +868,28 @@ func (l *LogoutCmd) Run() error {
 	)
 	logoutCancel := logoutSpinner.Start(ctx)
 
+	// Revoke the session on the server before forgetting the tokens
+	revokeErr := apiClient.RevokeSession(ctx, config.AuthToken, config.RefreshToken, l.AllSessions)
+	if errors.Is(revokeErr, context.Canceled) {
+		// Nothing has changed yet; stay logged in
+		logoutCancel()
+		logoutSpinner.Fail("Logout cancelled")
+		return errCancelled
+	}
+	queued := false
+	if revokeErr != nil {
+		logging.Warn().Err(revokeErr).Msg("server-side sign-out failed")
+		if api.IsUnavailable(revokeErr) {
+			if err := apiClient.QueueSignOut(config.AuthToken, config.RefreshToken, l.AllSessions); err != nil {
+				logging.Err(err, "failed to queue sign-out")
+			} else {
+				queued = true
+			}
+		}
+	} else {
+		apiClient.RevokePendingSignOuts(ctx)
+	}
+
 	// Clear the token but keep the email for future logins
 	if err := auth.ClearAuthToken(); err != nil {
 		logoutCancel()
@@ -483,6 +900,16 @@ func (l *LogoutCmd) Run() error {
 	logoutCancel()
 	logoutSpinner.Stop("Logged out")
 
+	switch {
+	case queued:
+		fmt.Println(theme.WarningMsg("Couldn't reach GoDeploy to end the session on the server."))
+		fmt.Println(theme.MutedMsg("It will be revoked the next time a command can reach the API."))
+	case revokeErr != nil:
+		fmt.Println(theme.WarningMsg(fmt.Sprintf("The server did not end the session: %v", revokeErr)))
+	case l.AllSessions:
+		fmt.Println(theme.MutedMsg("All sessions of this account have been signed out."))
+	}
+
 	// Get saved email for message
 	savedEmail, _ := auth.GetUserEmail()
 	if savedEmail != "" {
@@ -494,21 +921,361 @@ func (l *LogoutCmd) Run() error {
 	return nil
 }
 
-// Run executes the status command
-func (s *StatusCmd) Run() error {
-	logging.Info().Msg("auth status command started")
+// ProfilesCmd manages auth profiles
+type ProfilesCmd struct {
+	List   ProfilesListCmd   `cmd:"" help:"List auth profiles" default:"1"`
+	Use    ProfilesUseCmd    `cmd:"" help:"Switch the default auth profile"`
+	Remove ProfilesRemoveCmd `cmd:"" help:"Remove an auth profile and its credentials"`
+}
 
-	// Get saved email if available
-	savedEmail, _ := auth.GetUserEmail()
+// ProfilesListCmd lists auth profiles
+type ProfilesListCmd struct{}
 
-	// First do a quick local check - if no token exists, no need to try refresh
-	token, _ := auth.GetAuthToken()
-	if token == "" {
-		logging.Debug().Msg("no token found locally")
-		fmt.Println(theme.ErrorMsg("You are not authenticated with GoDeploy."))
-		if savedEmail != "" {
-			fmt.Println(theme.MutedMsg(fmt.Sprintf("Run 'godeploy auth login' to authenticate with saved email: %s", savedEmail)))
-		} else {
+func (p *ProfilesListCmd) Run() error {
+	store, err := auth.LoadStore()
+	if err != nil {
+		return fmt.Errorf("error loading auth profiles: %w", err)
+	}
+
+	active := store.Active()
+	names := store.Names()
+	if len(names) == 0 {
+		fmt.Println("No auth profiles yet. Run 'godeploy auth login' to create one.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\tPROFILE\tEMAIL\tAPI URL\tLOGGED IN")
+	for _, name := range names {
+		profile := store.Profiles[name]
+		marker := ""
+		if name == active {
+			marker = "*"
+		}
+		apiURL := profile.APIURL
+		if apiURL == "" {
+			apiURL = api.DefaultAPIBaseURL
+		}
+		loggedIn := "no"
+		if profile.AuthToken != "" {
+			loggedIn = "yes"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", marker, name, profile.Email, apiURL, loggedIn)
+	}
+	return w.Flush()
+}
+
+// ProfilesUseCmd switches the default auth profile
+type ProfilesUseCmd struct {
+	Name string `arg:"" help:"Profile name" required:"true"`
+}
+
+func (p *ProfilesUseCmd) Run() error {
+	if err := auth.UseProfile(p.Name); err != nil {
+		return err
+	}
+	fmt.Println(theme.SuccessMsg(fmt.Sprintf("Switched to profile '%s'", p.Name)))
+	if os.Getenv(auth.ProfileEnvVar) != "" || CLI.Profile != "" {
+		fmt.Println(theme.WarningMsg(fmt.Sprintf("--profile or %s is set and overrides this for the current command.", auth.ProfileEnvVar)))
+	}
+	return nil
+}
+
+// ProfilesRemoveCmd removes an auth profile
+type ProfilesRemoveCmd struct {
+	Name string `arg:"" help:"Profile name" required:"true"`
+}
+
+func (p *ProfilesRemoveCmd) Run() error {
+	current, err := currentProfile()
+	if err != nil {
+		return err
+	}
+	if err := auth.RemoveProfile(p.Name); err != nil {
+		return err
+	}
+	fmt.Println(theme.SuccessMsg(fmt.Sprintf("Removed profile '%s'", p.Name)))
+	if current == p.Name {
+		fmt.Println(theme.MutedMsg(fmt.Sprintf("Switched back to profile '%s'.", auth.DefaultProfile)))
+	}
+	return nil
+}
+
+// EncryptCmd encrypts the credentials file at rest
+type EncryptCmd struct{}
+
+func (e *EncryptCmd) Run() error {
+	passphrase := os.Getenv(auth.PassphraseEnvVar)
+	if passphrase == "" {
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			return fmt.Errorf("no terminal to read a passphrase from; set %s", auth.PassphraseEnvVar)
+		}
+		var err error
+		passphrase, err = readSecret("New passphrase: ")
+		if err != nil {
+			return err
+		}
+		confirm, err := readSecret("Confirm passphrase: ")
+		if err != nil {
+			return err
+		}
+		if passphrase != confirm {
+			return fmt.Errorf("passphrases do not match")
+		}
+	}
+
+	if err := auth.EnableEncryption(passphrase); err != nil {
+		return fmt.Errorf("failed to encrypt credentials: %w", err)
+	}
+
+	fmt.Println(theme.SuccessMsg("Credentials are now encrypted at rest."))
+	fmt.Println(theme.MutedMsg(fmt.Sprintf("Commands will ask for the passphrase, or read it from %s.", auth.PassphraseEnvVar)))
+	return nil
+}
+
+// DecryptCmd removes encryption from the credentials file
+type DecryptCmd struct{}
+
+func (d *DecryptCmd) Run() error {
+	encrypted, err := auth.IsEncrypted()
+	if err != nil {
+		return err
+	}
+	if !encrypted {
+		fmt.Println("Credentials are not encrypted.")
+		return nil
+	}
+
+	if err := auth.DisableEncryption(); err != nil {
+		return fmt.Errorf("failed to decrypt credentials: %w", err)
+	}
+	fmt.Println(theme.SuccessMsg("Credentials are stored unencrypted (readable by your user only)."))
+	return nil
+}
+
+// PasswordCmd groups the password management commands
+type PasswordCmd struct {
+	Change PasswordChangeCmd `cmd:"" help:"Change the password of the logged-in account"`
+	Reset  PasswordResetCmd  `cmd:"" help:"Reset a forgotten password by email"`
+}
+
+// PasswordChangeCmd changes the password of the logged-in account
+type PasswordChangeCmd struct{}
+
+func (p *PasswordChangeCmd) Run(ctx context.Context, apiClient api.API) error {
+	if apiClient.HasAPIToken() {
+		return fmt.Errorf("changing the password requires a login session; unset %s and run 'godeploy auth login'", auth.TokenEnvVar)
+	}
+	if err := requireAuth(apiClient); err != nil {
+		return err
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return fmt.Errorf("no terminal to read the password from")
+	}
+
+	current, err := readSecret("Current password: ")
+	if err != nil {
+		return err
+	}
+	if current == "" {
+		return fmt.Errorf("current password is required")
+	}
+	newPassword, err := readNewPassword()
+	if err != nil {
+		return err
+	}
+	if newPassword == current {
+		return fmt.Errorf("new password must be different from the current password")
+	}
+
+	changeSpinner := pin.New("Changing password...",
+		pin.WithSpinnerColor(pin.ColorMagenta),
+		pin.WithTextColor(pin.ColorMagenta),
+	)
+	changeCancel := changeSpinner.Start(ctx)
+	err = apiClient.ChangePassword(ctx, current, newPassword)
+	changeCancel()
+
+	if err != nil {
+		changeSpinner.Fail("Failed to change password")
+		logging.Error().Err(err).Msg("password change failed")
+		if errors.Is(err, api.ErrIncorrectPassword) {
+			fmt.Println(theme.ErrorMsg("The current password is incorrect."))
+			return api.ErrIncorrectPassword
+		}
+		return fmt.Errorf("failed to change password: %w", err)
+	}
+
+	changeSpinner.Stop("Password changed")
+	fmt.Println(theme.SuccessMsg("Your password has been changed."))
+	return nil
+}
+
+// PasswordResetCmd emails a reset link and sets a new password with its token
+type PasswordResetCmd struct {
+	Email      string `help:"Email address of the account (defaults to the saved email)" default:""`
+	ResetToken string `name:"reset-token" help:"Reset token from the email; skips sending a new email" default:""`
+}
+
+func (p *PasswordResetCmd) Run(ctx context.Context, apiClient api.API) error {
+	interactive := term.IsTerminal(int(os.Stdin.Fd()))
+	reader := bufio.NewReader(os.Stdin)
+
+	token := p.ResetToken
+	if token == "" {
+		email := p.Email
+		if email == "" {
+			email, _ = auth.GetUserEmail()
+		}
+		if email == "" {
+			if !interactive {
+				return fmt.Errorf("--email is required")
+			}
+			fmt.Print("Email: ")
+			input, err := reader.ReadString('\n')
+			if err != nil {
+				return fmt.Errorf("failed to read email: %w", err)
+			}
+			email = strings.TrimSpace(input)
+			if email == "" {
+				return fmt.Errorf("email is required to reset the password")
+			}
+		}
+
+		if err := apiClient.RequestPasswordReset(ctx, email, ""); err != nil {
+			logging.Error().Err(err).Str("email", email).Msg("password reset request failed")
+			return fmt.Errorf("failed to request password reset: %w", err)
+		}
+		fmt.Println(theme.SuccessMsg(fmt.Sprintf("If an account exists for %s, a password reset email is on its way.", email)))
+
+		if !interactive {
+			fmt.Println(theme.MutedMsg("Then run 'godeploy auth password reset --reset-token <token>' to set a new password."))
+			return nil
+		}
+		fmt.Print("Reset token from the email (leave empty to finish later): ")
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("failed to read token: %w", err)
+		}
+		token = strings.TrimSpace(input)
+		if token == "" {
+			fmt.Println(theme.MutedMsg("Run 'godeploy auth password reset --reset-token <token>' once you have the email."))
+			return nil
+		}
+	}
+
+	if !interactive {
+		return fmt.Errorf("no terminal to read the new password from")
+	}
+	newPassword, err := readNewPassword()
+	if err != nil {
+		return err
+	}
+
+	if err := apiClient.ConfirmPasswordReset(ctx, token, newPassword); err != nil {
+		logging.Error().Err(err).Msg("password reset confirmation failed")
+		if errors.Is(err, api.ErrInvalidResetToken) {
+			fmt.Println(theme.ErrorMsg("The reset token is invalid or has expired. Run 'godeploy auth password reset' to get a new one."))
+			return api.ErrInvalidResetToken
+		}
+		return fmt.Errorf("failed to reset password: %w", err)
+	}
+
+	fmt.Println(theme.SuccessMsg("Your password has been reset."))
+	fmt.Println(theme.MutedMsg("Run 'godeploy auth login' to log in with the new password."))
+	return nil
+}
+
+// minPasswordLength matches the server's password policy
+const minPasswordLength = 8
+
+// readNewPassword prompts for a new password twice and checks the policy
+func readNewPassword() (string, error) {
+	password, err := readSecret(fmt.Sprintf("New password (min %d characters): ", minPasswordLength))
+	if err != nil {
+		return "", err
+	}
+	if len(password) < minPasswordLength {
+		return "", fmt.Errorf("password must be at least %d characters long", minPasswordLength)
+	}
+	confirm, err := readSecret("Confirm new password: ")
+	if err != nil {
+		return "", err
+	}
+	if confirm != password {
+		return "", fmt.Errorf("passwords do not match")
+	}
+	return password, nil
+}
+
+// messageError replaces the message of an error the command already showed
+// in detail, while errors.Is and errors.As still see the original
+type messageError struct {
+	message string
+	err     error
+}
+
+func (e *messageError) Error() string { return e.message }
+func (e *messageError) Unwrap() error { return e.err }
+
+// withMessage returns err with a shorter message for the final error line
+func withMessage(message string, err error) error {
+	return &messageError{message: message, err: err}
+}
+
+// readSecret prompts for a value without echoing it
+func readSecret(label string) (string, error) {
+	fmt.Fprint(os.Stderr, label)
+	value, err := term.ReadPassword(int(syscall.Stdin))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read input: %w", err)
+	}
+	return string(value), nil
+}
+
+// currentProfile returns the saved default profile, ignoring --profile and
+// GODEPLOY_PROFILE
+func currentProfile() (string, error) {
+	store, err := auth.LoadStore()
+	if err != nil {
+		return "", fmt.Errorf("error loading auth profiles: %w", err)
+	}
+	if store.CurrentProfile == "" {
+		return auth.DefaultProfile, nil
+	}
+	return store.CurrentProfile, nil
+}
+
+// normalizeAPIURL validates an API base URL and strips any trailing slash
+func normalizeAPIURL(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("invalid API URL '%s': expected http(s)://host", raw)
+	}
+	return strings.TrimSuffix(u.String(), "/"), nil
+}
+
+// Run executes the status command
+func (s *StatusCmd) Run(ctx context.Context, apiClient api.API) error {
+	logging.Info().Msg("auth status command started")
+
+	// An API token takes precedence over the saved login
+	if auth.GetAPIToken() != "" {
+		return s.apiTokenStatus(ctx, apiClient)
+	}
+
+	// Get saved email if available
+	savedEmail, _ := auth.GetUserEmail()
+
+	// First do a quick local check - if no token exists, no need to try refresh
+	token, _ := auth.GetAuthToken()
+	if token == "" {
+		logging.Debug().Msg("no token found locally")
+		fmt.Println(theme.ErrorMsg("You are not authenticated with GoDeploy."))
+		if savedEmail != "" {
+			fmt.Println(theme.MutedMsg(fmt.Sprintf("Run 'godeploy auth login' to authenticate with saved email: %s", savedEmail)))
+		} else {
 			fmt.Println(theme.MutedMsg("Run 'godeploy auth login' to authenticate."))
 		}
 		return nil
@@ -516,14 +1283,12 @@ func (s *StatusCmd) Run() error {
 
 	// Check authentication using TokenManager (which handles refresh automatically)
 	// Show a spinner since this may make network calls
-	ctx := context.Background()
 	statusSpinner := pin.New("Checking authentication status...",
 		pin.WithSpinnerColor(pin.ColorMagenta),
 		pin.WithTextColor(pin.ColorMagenta),
 	)
 	statusCancel := statusSpinner.Start(ctx)
 
-	apiClient := api.NewClient()
 	tokenManager := createTokenManager(apiClient)
 	token, err := tokenManager.EnsureValidToken()
 
@@ -549,14 +1314,50 @@ func (s *StatusCmd) Run() error {
 	if savedEmail != "" {
 		fmt.Println(theme.MutedMsg(fmt.Sprintf("Logged in as: %s", savedEmail)))
 	}
+	if profile, err := auth.ActiveProfile(); err == nil {
+		fmt.Println(theme.MutedMsg(fmt.Sprintf("Profile: %s (%s)", profile, apiClient.APIURL())))
+	}
+
+	return nil
+}
+
+// apiTokenStatus verifies the API token from --token or GODEPLOY_TOKEN
+func (s *StatusCmd) apiTokenStatus(ctx context.Context, apiClient api.API) error {
+	statusSpinner := pin.New("Checking API token...",
+		pin.WithSpinnerColor(pin.ColorMagenta),
+		pin.WithTextColor(pin.ColorMagenta),
+	)
+	statusCancel := statusSpinner.Start(ctx)
+
+	verifyResp, err := apiClient.VerifyToken(ctx, auth.GetAPIToken())
+
+	statusCancel()
+
+	if errors.Is(err, context.Canceled) {
+		statusSpinner.Fail("API token check failed")
+		return err
+	}
+	if err != nil || !verifyResp.Valid {
+		statusSpinner.Fail("API token check failed")
+		logging.Debug().Err(err).Msg("api token validation failed")
+		fmt.Println(theme.ErrorMsg("The API token is not valid."))
+		fmt.Println(theme.MutedMsg(fmt.Sprintf("Check the value of --token or %s, or create a new token with 'godeploy tokens create'.", auth.TokenEnvVar)))
+		return nil
+	}
 
+	statusSpinner.Stop("Authenticated")
+	fmt.Println(theme.SuccessMsg("You are authenticated with an API token."))
+	if verifyResp.User.Email != "" {
+		fmt.Println(theme.MutedMsg(fmt.Sprintf("Token belongs to: %s", verifyResp.User.Email)))
+	}
 	return nil
 }
 
-// createTokenManager creates a TokenManager with the given API client
-func createTokenManager(apiClient *api.Client) *auth.TokenManager {
+// createTokenManager creates a TokenManager with the given API client. Like
+// the client's own, its refreshes aren't cancelled with the command.
+func createTokenManager(apiClient api.API) *auth.TokenManager {
 	return auth.NewTokenManager(func(refreshToken string) (string, string, error) {
-		resp, err := apiClient.RefreshToken(refreshToken)
+		resp, err := apiClient.RefreshToken(context.Background(), refreshToken)
 		if err != nil {
 			return "", "", err
 		}
@@ -564,6 +1365,31 @@ func createTokenManager(apiClient *api.Client) *auth.TokenManager {
 	})
 }
 
+// requireAuth returns an error asking the user to log in unless a valid
+// token is available (refreshing it if needed)
+func requireAuth(apiClient api.API) error {
+	// API tokens are checked by the server on first use
+	if apiClient.HasAPIToken() {
+		return nil
+	}
+
+	tokenManager := createTokenManager(apiClient)
+	if _, err := tokenManager.EnsureValidToken(); err != nil {
+		// A refresh that couldn't reach the API, or a wrong passphrase, keeps
+		// its own exit code
+		var apiErr *api.Error
+		if (errors.As(err, &apiErr) && !errors.Is(err, api.ErrUnauthorized)) || errors.Is(err, auth.ErrIncorrectPassphrase) {
+			return err
+		}
+		message := "you must be authenticated to use this command. Run 'godeploy auth login' to authenticate"
+		if savedEmail, _ := auth.GetUserEmail(); savedEmail != "" {
+			message += " with saved email: " + savedEmail
+		}
+		return &api.Error{Message: message, Kind: api.ErrUnauthorized, Err: err}
+	}
+	return nil
+}
+
 // gitOutput runs a git command and returns its trimmed string output.
 func gitOutput(args ...string) string {
 	cmd := exec.Command("git", args...)
@@ -646,6 +1472,10 @@ func formatDeploymentError(projectName string, err error, zipStats *archive.ZipS
 		"",
 		theme.KeyValueError("Error", err.Error()),
 	)
+	var apiErr *api.Error
+	if errors.As(err, &apiErr) && apiErr.RequestID != "" {
+		content = lipgloss.JoinVertical(lipgloss.Left, content, theme.KeyValueError("Request ID", apiErr.RequestID))
+	}
 
 	// Use theme title and box styles
 	titleStyle := theme.TitleErrorStyle.Margin(1, 0)
@@ -682,46 +1512,14 @@ func buildCommitURL(remoteURL, sha string) string {
 	return ""
 }
 
-// Run executes the deploy command
-func (d *DeployCmd) Run() error {
-	// Create a context
-	ctx := context.Background()
-
-	// Quick authentication check - token refresh will happen automatically during deploy
-	apiClient := api.NewClient()
-	tokenManager := createTokenManager(apiClient)
-	if _, err := tokenManager.EnsureValidToken(); err != nil {
-		savedEmail, _ := auth.GetUserEmail()
-		if savedEmail != "" {
-			return fmt.Errorf("you must be authenticated to use this command. Run 'godeploy auth login' to authenticate with saved email: %s", savedEmail)
-		}
-		return fmt.Errorf("you must be authenticated to use this command. Run 'godeploy auth login' to authenticate")
-	}
-
-	// Load the SPA configuration
-	configSpinner := pin.New("Loading SPA configuration...",
-		pin.WithSpinnerColor(pin.ColorMagenta),
-		pin.WithTextColor(pin.ColorMagenta),
-	)
-	configCancel := configSpinner.Start(ctx)
-
-	spaConfig, err := config.LoadConfig(CLI.Config)
-	if err != nil {
-		configCancel()
-		configSpinner.Fail("Failed to load SPA configuration")
-		return fmt.Errorf("error loading SPA configuration: %w", err)
-	}
-
-	configCancel()
-	configSpinner.Stop("SPA configuration loaded")
-
-	// Determine the project name
-	projectName := d.Project
+// selectApp returns the named app from the SPA configuration, or the first
+// enabled app when no name is given
+func selectApp(spaConfig *config.SpaConfig, projectName string) (config.App, error) {
 	if projectName == "" {
 		// If no project is specified, use the first enabled app
 		enabledApps := spaConfig.GetEnabledApps()
 		if len(enabledApps) == 0 {
-			return fmt.Errorf("no enabled apps found in SPA configuration")
+			return config.App{}, fmt.Errorf("no enabled apps found in SPA configuration")
 		}
 		projectName = enabledApps[0].Name
 		fmt.Printf("No project specified, using first enabled app: '%s'\n", projectName)
@@ -732,62 +1530,243 @@ func (d *DeployCmd) Run() error {
 	// Validate the project name
 	app, found := spaConfig.GetAppByName(projectName)
 	if !found {
-		return fmt.Errorf("project '%s' not found in SPA configuration", projectName)
+		return config.App{}, fmt.Errorf("project '%s' not found in SPA configuration", projectName)
 	}
 
 	if !app.Enabled {
-		return fmt.Errorf("project '%s' is disabled in SPA configuration", projectName)
-	}
-
-	// Create a cache directory for the deployment using XDG Base Directory
-	tempDir, err := cache.GetDeploymentCacheDir(projectName)
-	if err != nil {
-		return fmt.Errorf("failed to create deployment cache directory: %w", err)
+		return config.App{}, fmt.Errorf("project '%s' is disabled in SPA configuration", projectName)
 	}
-	defer func() {
-		_ = cache.RemoveDeploymentCache(tempDir)
-	}()
 
-	// Create the zip file path
-	zipFilePath := filepath.Join(tempDir, projectName+".zip")
+	return app, nil
+}
 
-	// Create a spinner for creating the zip archive
-	zipSpinner := pin.New(fmt.Sprintf("Creating zip archive for project '%s'...", projectName),
-		pin.WithSpinnerColor(pin.ColorMagenta),
-		pin.WithTextColor(pin.ColorMagenta),
-	)
-	zipCancel := zipSpinner.Start(ctx)
+// selectLinkedApp picks the app to deploy to a linked project: the app named
+// like the project (currently or when it was linked), else the first enabled app
+func selectLinkedApp(spaConfig *config.SpaConfig, names ...string) (config.App, error) {
+	for _, name := range names {
+		if app, found := spaConfig.GetAppByName(name); found && name != "" {
+			return selectApp(spaConfig, app.Name)
+		}
+	}
+	return selectApp(spaConfig, "")
+}
 
-	// Get the absolute path of the source directory
-	sourceDir := app.SourceDir
-	if !filepath.IsAbs(sourceDir) {
-		// If the source directory is relative, make it absolute
-		// relative to the current working directory
-		cwd, err := os.Getwd()
-		if err != nil {
-			zipCancel()
-			zipSpinner.Fail("Failed to get current working directory")
-			return fmt.Errorf("failed to get current working directory: %w", err)
+// resolveConfigPath returns the SPA config file to use. An explicit --config
+// path is used as given; the default file name is searched for in the current
+// directory and its parents, the way git finds .git.
+func resolveConfigPath() (string, error) {
+	configPath := CLI.Config
+	if configPath != config.DefaultConfigFile {
+		if _, err := os.Stat(configPath); err != nil {
+			return "", fmt.Errorf("config file %s not found", configPath)
 		}
-		sourceDir = filepath.Join(cwd, sourceDir)
+		return filepath.Abs(configPath)
 	}
 
-	// Check if the source directory exists
-	if _, err := os.Stat(sourceDir); os.IsNotExist(err) {
-		zipCancel()
-		zipSpinner.Fail("Source directory not found")
-		return fmt.Errorf("source directory '%s' not found", app.SourceDir)
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to get current working directory: %w", err)
+	}
+	found, err := config.FindConfig(cwd, configPath)
+	if err != nil {
+		return "", fmt.Errorf("no %s found in this directory or any parent. Run 'godeploy init' to create one", configPath)
 	}
+	return found, nil
+}
 
-	// Create the zip archive
-	zipStats, err := archive.CreateZipFromDirectory(sourceDir, zipFilePath)
+// loadSpaConfig finds and loads the SPA configuration
+func loadSpaConfig() (*config.SpaConfig, error) {
+	configPath, err := resolveConfigPath()
 	if err != nil {
-		zipCancel()
-		zipSpinner.Fail("Failed to create zip archive")
-		return fmt.Errorf("error creating zip archive: %w", err)
+		return nil, err
 	}
 
-	zipCancel()
+	spaConfig, err := config.LoadConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	logging.Debug().Str("config", spaConfig.Path).Msg("using SPA configuration")
+	return spaConfig, nil
+}
+
+// displayPath shortens an absolute path to one relative to the working
+// directory when that is inside it
+func displayPath(path string) string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(cwd, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return rel
+}
+
+// runAppBuild runs the build configured for app, streaming its output.
+// A non-empty commandOverride replaces the configured build command.
+func runAppBuild(ctx context.Context, spaConfig *config.SpaConfig, app config.App, commandOverride string) error {
+	buildConfig := config.Build{}
+	if app.Build != nil {
+		buildConfig = *app.Build
+	}
+	if commandOverride != "" {
+		buildConfig.Command = commandOverride
+	}
+	if buildConfig.Command == "" {
+		return fmt.Errorf("no build command configured for '%s'. Add a \"build\" block to %s or run 'godeploy builds config --command <cmd>'", app.Name, displayPath(spaConfig.Path))
+	}
+
+	// Paths in the config are relative to the config file
+	workingDir := spaConfig.ResolvePath(buildConfig.WorkingDir)
+	outputDir := buildConfig.OutputDir
+	if outputDir == "" {
+		outputDir = app.SourceDir
+	}
+	outputDir = spaConfig.ResolvePath(outputDir)
+
+	logging.Info().Str("project", app.Name).Str("command", buildConfig.Command).Msg("running build")
+	fmt.Println(theme.InfoMsg(fmt.Sprintf("Building '%s': %s", app.Name, buildConfig.Command)))
+
+	result, err := build.Run(ctx, build.Options{
+		Command:   buildConfig.Command,
+		Dir:       workingDir,
+		Env:       buildConfig.Env,
+		OutputDir: outputDir,
+		Prefix:    theme.MutedMsg(fmt.Sprintf("[%s]", app.Name)) + " ",
+	})
+	if err != nil && ctx.Err() != nil {
+		logging.Info().Str("project", app.Name).Msg("build cancelled")
+		return ctx.Err()
+	}
+	if err != nil {
+		logging.Error().Err(err).Str("project", app.Name).Msg("build failed")
+		fmt.Println(theme.ErrorMsg(fmt.Sprintf("Build failed for '%s'", app.Name)))
+		return fmt.Errorf("build failed: %w", err)
+	}
+
+	logging.Info().Str("project", app.Name).Dur("duration", result.Duration).Msg("build completed")
+	fmt.Println(theme.SuccessMsg(fmt.Sprintf("Build completed in %s", result.Duration.Round(time.Millisecond))))
+	return nil
+}
+
+// Run executes the deploy command
+func (d *DeployCmd) Run(ctx context.Context, apiClient api.API) error {
+	if d.Timeout != "" {
+		if err := settings.SetFlag(settings.DeployTimeout, d.Timeout); err != nil {
+			return err
+		}
+	}
+
+	// Quick authentication check - token refresh will happen automatically during deploy
+	if err := requireAuth(apiClient); err != nil {
+		return err
+	}
+
+	// Load the SPA configuration
+	configSpinner := pin.New("Loading SPA configuration...",
+		pin.WithSpinnerColor(pin.ColorMagenta),
+		pin.WithTextColor(pin.ColorMagenta),
+	)
+	configCancel := configSpinner.Start(ctx)
+
+	spaConfig, err := loadSpaConfig()
+	if err != nil {
+		configCancel()
+		configSpinner.Fail("Failed to load SPA configuration")
+		return fmt.Errorf("error loading SPA configuration: %w", err)
+	}
+
+	configCancel()
+	configSpinner.Stop(fmt.Sprintf("SPA configuration loaded from %s", displayPath(spaConfig.Path)))
+
+	// Without --project, deploy to the linked project if there is one
+	binding, err := findBinding()
+	if err != nil {
+		return err
+	}
+	if d.Project != "" || binding == nil {
+		binding = nil
+	}
+
+	var app config.App
+	var projectName string
+	if binding != nil {
+		project, err := linkedProject(ctx, apiClient, binding)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Using linked project: '%s'\n", project.Name)
+		app, err = selectLinkedApp(spaConfig, project.Name, binding.ProjectName)
+		if err != nil {
+			return err
+		}
+		projectName = project.Name
+	} else {
+		app, err = selectApp(spaConfig, d.Project)
+		if err != nil {
+			return err
+		}
+		projectName = app.Name
+	}
+
+	// Run the build first so the archive contains fresh output
+	if d.Build {
+		if err := runAppBuild(ctx, spaConfig, app, ""); err != nil {
+			if errors.Is(err, context.Canceled) {
+				fmt.Println(theme.WarningMsg("Deployment cancelled during the build"))
+				return errCancelled
+			}
+			return err
+		}
+	}
+
+	// Create a cache directory for the deployment using XDG Base Directory.
+	// It is removed on every return, including a cancelled deploy.
+	tempDir, err := cache.GetDeploymentCacheDir(projectName)
+	if err != nil {
+		return fmt.Errorf("failed to create deployment cache directory: %w", err)
+	}
+	defer func() {
+		if err := cache.RemoveDeploymentCache(tempDir); err != nil {
+			logging.Warn().Err(err).Str("dir", tempDir).Msg("failed to remove deployment cache")
+		}
+	}()
+
+	// Create the zip file path
+	zipFilePath := filepath.Join(tempDir, projectName+".zip")
+
+	// Create a spinner for creating the zip archive
+	zipSpinner := pin.New(fmt.Sprintf("Creating zip archive for project '%s'...", projectName),
+		pin.WithSpinnerColor(pin.ColorMagenta),
+		pin.WithTextColor(pin.ColorMagenta),
+	)
+	zipCancel := zipSpinner.Start(ctx)
+
+	// Get the absolute path of the source directory, relative to the config file
+	sourceDir := spaConfig.ResolvePath(app.SourceDir)
+
+	// Check if the source directory exists
+	if _, err := os.Stat(sourceDir); os.IsNotExist(err) {
+		zipCancel()
+		zipSpinner.Fail("Source directory not found")
+		return fmt.Errorf("source directory '%s' not found (resolved to %s)", app.SourceDir, sourceDir)
+	}
+
+	// Create the zip archive
+	zipStats, err := archive.CreateZipFromDirectory(sourceDir, zipFilePath)
+	if err != nil {
+		zipCancel()
+		zipSpinner.Fail("Failed to create zip archive")
+		return fmt.Errorf("error creating zip archive: %w", err)
+	}
+
+	zipCancel()
+	if ctx.Err() != nil {
+		zipSpinner.Fail("Deployment cancelled")
+		return errCancelled
+	}
 	zipSpinner.Stop("Zip archive created")
 
 	// Display formatted zip statistics
@@ -800,7 +1779,7 @@ func (d *DeployCmd) Run() error {
 	}
 
 	// Read the SPA configuration file
-	configData, err := os.ReadFile(CLI.Config)
+	configData, err := os.ReadFile(spaConfig.Path)
 	if err != nil {
 		return fmt.Errorf("error reading SPA configuration file: %w", err)
 	}
@@ -839,7 +1818,13 @@ func (d *DeployCmd) Run() error {
 	}
 
 	// Deploy the SPA
-	deployResp, err := apiClient.Deploy(projectName, configData, zipData, commitSHA, commitBranch, commitMessage, commitURL, d.ClearCache)
+	deployResp, err := apiClient.Deploy(ctx, projectName, configData, zipData, commitSHA, commitBranch, commitMessage, commitURL, d.ClearCache)
+	if errors.Is(err, context.Canceled) {
+		deployCancel()
+		deploySpinner.Fail("Deployment cancelled")
+		fmt.Println(theme.MutedMsg("The upload was stopped. If the server had already received it, the deployment may still complete."))
+		return errCancelled
+	}
 	if err != nil {
 		deployCancel()
 		deploySpinner.Fail("Failed to deploy project")
@@ -848,10 +1833,10 @@ func (d *DeployCmd) Run() error {
 		fmt.Println(formatDeploymentError(projectName, err, zipStats))
 
 		// If this was a timeout while awaiting headers, deployment may still have succeeded server-side.
-		if strings.Contains(err.Error(), "Client.Timeout exceeded") || strings.Contains(err.Error(), "context deadline exceeded") {
-			return fmt.Errorf("request timed out waiting for server response; your deployment may still complete. Consider increasing timeout via GODEPLOY_DEPLOY_TIMEOUT (e.g. '5m')")
+		if errors.Is(err, api.ErrTimeout) {
+			return withMessage("request timed out waiting for server response; your deployment may still complete. Consider increasing it with --timeout or the deploy-timeout setting (e.g. '20m')", err)
 		}
-		return fmt.Errorf("deployment failed")
+		return withMessage("deployment failed", err)
 	}
 
 	deployCancel()
@@ -865,60 +1850,710 @@ func (d *DeployCmd) Run() error {
 
 // ProjectsCmd lists all deployed projects
 type ProjectsCmd struct {
-	Filter string `help:"Filter projects (enabled/disabled/all)" default:"all"`
+	Filter string `help:"Show projects whose app is enabled or disabled in the config file, or all projects (enabled/disabled/all)" default:"all"`
 	JSON   bool   `help:"Output in JSON format" default:"false"`
+	Local  bool   `help:"Compare with the apps in the config file: show apps that aren't deployed yet and mark projects that aren't in it" default:"false"`
+}
+
+// projectListing is a row of 'godeploy projects'
+type projectListing struct {
+	Name string `json:"name"`
+	// Project is nil for an app of the config file that wasn't deployed yet
+	Project *api.Project `json:"project"`
+	// LastDeployment is nil when the project has no deployments or the API
+	// can't list them
+	LastDeployment *api.Deployment `json:"last_deployment"`
+	// App is the project's app in the config file, when it was read
+	App *config.App `json:"app,omitempty"`
 }
 
-func (p *ProjectsCmd) Run() error {
-	fmt.Println(theme.NotImplementedWithDesc("projects", "This will list all your deployed projects with their URLs and status"))
+func (p *ProjectsCmd) Run(ctx context.Context, apiClient api.API) error {
+	if p.Filter != "all" && p.Filter != "enabled" && p.Filter != "disabled" {
+		return fmt.Errorf("invalid filter '%s': expected enabled, disabled or all", p.Filter)
+	}
+
+	// The filter and --local need the apps of the config file
+	var spaConfig *config.SpaConfig
+	if p.Local || p.Filter != "all" {
+		var err error
+		spaConfig, err = loadSpaConfig()
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := requireAuth(apiClient); err != nil {
+		return err
+	}
+
+	projects, err := apiClient.ListProjects(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list projects: %w", err)
+	}
+
+	listings := make([]projectListing, 0, len(projects))
+	deployed := map[string]bool{}
+	for i := range projects {
+		listing := projectListing{Name: projects[i].Name, Project: &projects[i]}
+		if spaConfig != nil {
+			if app, ok := spaConfig.GetAppByName(projects[i].Name); ok {
+				listing.App = &app
+				deployed[app.Name] = true
+			}
+		}
+		listings = append(listings, listing)
+	}
+	if p.Local {
+		for _, app := range spaConfig.Apps {
+			if !deployed[app.Name] {
+				listings = append(listings, projectListing{Name: app.Name, App: &app})
+			}
+		}
+	}
+
+	if p.Filter != "all" {
+		kept := listings[:0]
+		for _, listing := range listings {
+			if listing.App != nil && listing.App.Enabled == (p.Filter == "enabled") {
+				kept = append(kept, listing)
+			}
+		}
+		listings = kept
+	}
+
+	if err := addLastDeployments(ctx, apiClient, listings); err != nil {
+		return err
+	}
+
+	if jsonOutput(p.JSON) {
+		return printJSON(listings)
+	}
+
+	if len(listings) == 0 {
+		if p.Filter != "all" {
+			fmt.Printf("No projects of %s apps.\n", p.Filter)
+		} else {
+			fmt.Println("No projects. Deploy one with 'godeploy deploy'.")
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	header := "NAME\tURL\tLAST DEPLOY\tSTATUS\tDOMAIN"
+	if p.Local {
+		header += "\tLOCAL"
+	}
+	fmt.Fprintln(w, header)
+	for _, listing := range listings {
+		siteURL, lastDeploy, status, domain := "-", "-", "-", "-"
+		if listing.Project != nil {
+			siteURL = listing.Project.URL
+			if listing.Project.Domain != nil && *listing.Project.Domain != "" {
+				domain = *listing.Project.Domain
+			}
+		}
+		if listing.LastDeployment != nil {
+			lastDeploy = formatDate(listing.LastDeployment.CreatedAt)
+			status = listing.LastDeployment.Status
+		}
+		row := fmt.Sprintf("%s\t%s\t%s\t%s\t%s", listing.Name, siteURL, lastDeploy, status, domain)
+		if p.Local {
+			row += "\t" + localState(listing)
+		}
+		fmt.Fprintln(w, row)
+	}
+	return w.Flush()
+}
+
+// lastDeploymentLookups is how many projects' deployments are looked up at
+// once
+const lastDeploymentLookups = 4
+
+// addLastDeployments looks up the newest deployment of each listed project.
+// The columns stay empty when the API doesn't list deployments yet.
+func addLastDeployments(ctx context.Context, apiClient api.API, listings []projectListing) error {
+	errs := make([]error, len(listings))
+	slots := make(chan struct{}, lastDeploymentLookups)
+	var wg sync.WaitGroup
+	for i := range listings {
+		if listings[i].Project == nil {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			list, err := apiClient.ListDeployments(ctx, listings[i].Project.ID, &api.DeploymentListOptions{Limit: 1})
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			if len(list.Deployments) > 0 {
+				listings[i].LastDeployment = &list.Deployments[0]
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if errors.Is(err, api.ErrNotImplemented) {
+			logging.Debug().Msg("deployment history not available; skipping last deployments")
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get deployments of %s: %w", listings[i].Name, err)
+		}
+	}
 	return nil
 }
 
+// localState describes how a listed project relates to the config file
+func localState(listing projectListing) string {
+	switch {
+	case listing.App == nil:
+		return "not in config"
+	case listing.Project == nil:
+		return "not deployed"
+	case !listing.App.Enabled:
+		return "disabled"
+	}
+	return "enabled"
+}
+
 // StatusProjectCmd checks deployment status for a project
 type StatusProjectCmd struct {
-	Project string `arg:"" help:"Project name" required:"true"`
+	Project string `arg:"" optional:"" help:"Project name (defaults to the linked project)"`
+	Watch   bool   `help:"Refresh until the in-progress deployment finishes" short:"w" default:"false"`
+	JSON    bool   `help:"Output in JSON format" default:"false"`
 }
 
-func (s *StatusProjectCmd) Run() error {
-	fmt.Println(theme.NotImplementedWithDesc(
-		fmt.Sprintf("status %s", s.Project),
-		"This will show deployment state, last deployment info, and current URL",
-	))
-	return nil
+// statusWatchInterval is how often --watch polls the project status
+var statusWatchInterval = 2 * time.Second
+
+func (s *StatusProjectCmd) Run(ctx context.Context, apiClient api.API) error {
+	name, err := projectArg(s.Project)
+	if err != nil {
+		return err
+	}
+
+	if err := requireAuth(apiClient); err != nil {
+		return err
+	}
+
+	project, err := apiClient.FindProject(ctx, name)
+	if err != nil {
+		return fmt.Errorf("failed to resolve project: %w", err)
+	}
+
+	// In place re-rendering needs a terminal; otherwise each change is
+	// printed below the last one
+	inPlace := !jsonOutput(s.JSON) && term.IsTerminal(int(os.Stdout.Fd()))
+	previous := ""
+	for {
+		status, err := apiClient.GetProjectStatus(ctx, project.ID)
+		if err != nil && s.Watch && previous != "" && api.IsUnavailable(err) {
+			// Keep showing the last status and try again on the next tick
+			logging.Debug().Err(err).Msg("status unavailable; retrying")
+			if err := sleepContext(ctx, statusWatchInterval); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get status of %s: %w", project.Name, err)
+		}
+
+		var rendered string
+		if jsonOutput(s.JSON) {
+			data, err := json.MarshalIndent(status, "", "  ")
+			if err != nil {
+				return err
+			}
+			rendered = string(data) + "\n"
+		} else {
+			rendered = formatProjectStatus(status) + "\n"
+		}
+		if rendered != previous {
+			if inPlace && previous != "" {
+				// Move up over the previous render and clear it
+				fmt.Printf("\033[%dA\033[J", renderedRows(previous, terminalWidth()))
+			}
+			fmt.Print(rendered)
+			previous = rendered
+		}
+
+		if !s.Watch || status.InProgress == nil {
+			return nil
+		}
+		if err := sleepContext(ctx, statusWatchInterval); err != nil {
+			return err
+		}
+	}
+}
+
+// sleepContext waits for d, or returns the error of ctx when it is done
+// first
+func sleepContext(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
+
+// terminalWidth returns the width of the terminal on stdout, or 0 when it
+// is unknown
+func terminalWidth() int {
+	width, _, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		return 0
+	}
+	return width
+}
+
+// renderedRows counts the terminal rows text takes up when lines longer
+// than width wrap. A width of 0 counts lines.
+func renderedRows(text string, width int) int {
+	rows := 0
+	for _, line := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+		cells := lipgloss.Width(line)
+		if width <= 0 || cells <= width {
+			rows++
+			continue
+		}
+		rows += (cells + width - 1) / width
+	}
+	return rows
 }
 
-// LogsCmd views deployment logs
+// formatProjectStatus renders the live state of a project. Keys share one
+// style so they line up; states are colored in the values.
+func formatProjectStatus(status *api.ProjectStatus) string {
+	project := status.Project
+	lines := []string{theme.KeyValue("URL", theme.URLStyle.Render(project.URL))}
+	if len(status.Domains) > 0 {
+		lines = append(lines, theme.KeyValue("Domains", strings.Join(status.Domains, ", ")))
+	}
+	if status.Health != nil {
+		lines = append(lines, formatHealth(status.Health))
+	}
+
+	lines = append(lines, "")
+	if active := status.Active; active != nil {
+		lines = append(lines, theme.KeyValue("Active", lipgloss.NewStyle().Foreground(theme.Primary).Render(active.ID)))
+		lines = append(lines, formatDeploymentDetails(active)...)
+	} else {
+		lines = append(lines, theme.KeyValue("Active", "none; deploy with 'godeploy deploy'"))
+	}
+
+	if pending := status.InProgress; pending != nil {
+		lines = append(lines, "", theme.KeyValue("In Progress", fmt.Sprintf("%s (%s)", pending.ID, pending.Status)))
+		lines = append(lines, formatDeploymentDetails(pending)...)
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		theme.TitleStyle.Margin(1, 0).Render(fmt.Sprintf("Project: %s", project.Name)),
+		theme.BoxStyle.Margin(1, 0).Render(lipgloss.JoinVertical(lipgloss.Left, lines...)),
+	)
+}
+
+// formatDeploymentDetails renders when and by whom a deployment was made
+// and its commit
+func formatDeploymentDetails(deployment *api.Deployment) []string {
+	deployer := deployment.UserID
+	if deployment.UserEmail != nil {
+		deployer = *deployment.UserEmail
+	}
+	lines := []string{theme.KeyValue("Deployed", fmt.Sprintf("%s by %s", formatDate(deployment.CreatedAt), deployer))}
+
+	if deployment.CommitSHA != nil {
+		commit := shortSHA(*deployment.CommitSHA)
+		if deployment.CommitBranch != nil {
+			commit += " on " + *deployment.CommitBranch
+		}
+		lines = append(lines, theme.KeyValue("Commit", commit))
+	}
+	if deployment.CommitMessage != nil {
+		// The subject line is enough here
+		message, _, _ := strings.Cut(*deployment.CommitMessage, "\n")
+		lines = append(lines, theme.KeyValue("Message", message))
+	}
+	return lines
+}
+
+// formatHealth renders the result of the last health check
+func formatHealth(health *api.Health) string {
+	details := []string{}
+	if health.StatusCode != 0 {
+		details = append(details, fmt.Sprintf("HTTP %d", health.StatusCode))
+	}
+	if health.ResponseTimeMS != 0 {
+		details = append(details, fmt.Sprintf("%d ms", health.ResponseTimeMS))
+	}
+	details = append(details, "checked "+formatDate(health.CheckedAt))
+	value := fmt.Sprintf("%s (%s)", health.Status, strings.Join(details, ", "))
+
+	switch health.Status {
+	case "healthy":
+		value = lipgloss.NewStyle().Foreground(theme.Primary).Render(value)
+	case "down":
+		value = theme.ValueErrorStyle.Render(value)
+	}
+	return theme.KeyValue("Health", value)
+}
+
+// shortSHA abbreviates a commit SHA the way git does
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// LogsCmd shows the deploy logs of a project or the logs of a build
 type LogsCmd struct {
-	Project      string `arg:"" help:"Project name" required:"true"`
-	Follow       bool   `help:"Follow log output" short:"f" default:"false"`
-	Lines        int    `help:"Number of lines to show" short:"n" default:"100"`
-	DeploymentID string `help:"Specific deployment ID" default:""`
+	Project      string `arg:"" optional:"" help:"Project name (defaults to the linked project)"`
+	Follow       bool   `help:"Stream new log lines until the deployment or build finishes" short:"f" default:"false"`
+	Lines        int    `help:"Number of earlier lines to show" short:"n" default:"100"`
+	DeploymentID string `help:"Only logs of this deployment" default:""`
+	Build        string `help:"Show the logs of this build instead of the deploy logs" default:""`
+	JSON         bool   `help:"Output each log entry as a line of JSON" default:"false"`
+}
+
+// logLevelStyles color the level and message of log lines by level
+var logLevelStyles = map[string]struct{ level, message lipgloss.Style }{
+	"debug": {lipgloss.NewStyle().Foreground(theme.TextMuted), lipgloss.NewStyle().Foreground(theme.TextDim)},
+	"info":  {lipgloss.NewStyle().Foreground(theme.Info), lipgloss.NewStyle()},
+	"warn":  {lipgloss.NewStyle().Foreground(theme.Warning), lipgloss.NewStyle().Foreground(theme.WarningLight)},
+	"error": {lipgloss.NewStyle().Foreground(theme.Error).Bold(true), lipgloss.NewStyle().Foreground(theme.ErrorLight)},
 }
 
-func (l *LogsCmd) Run() error {
-	desc := "This will display deployment logs for the project"
-	if l.Follow {
-		desc = "This will stream logs in real-time"
+func (l *LogsCmd) Run(ctx context.Context, apiClient api.API) error {
+	name, err := projectArg(l.Project)
+	if err != nil {
+		return err
+	}
+	if l.Lines < 0 {
+		return fmt.Errorf("invalid number of lines %d: expected 0 or more", l.Lines)
+	}
+	if l.Build != "" && l.DeploymentID != "" {
+		return fmt.Errorf("--build and --deployment-id can't be combined")
+	}
+
+	if err := requireAuth(apiClient); err != nil {
+		return err
+	}
+
+	project, err := apiClient.FindProject(ctx, name)
+	if err != nil {
+		return fmt.Errorf("failed to resolve project: %w", err)
+	}
+
+	// Ask for the last lines only; the page's cursor is where following
+	// continues. A page holds at least one entry, which --lines 0 drops.
+	opts := &api.LogOptions{DeploymentID: l.DeploymentID, Limit: max(l.Lines, 1), Tail: true}
+	var page *api.LogList
+	if l.Build != "" {
+		page, err = apiClient.GetBuildLogs(ctx, project.ID, l.Build, opts)
+	} else {
+		page, err = apiClient.GetProjectLogs(ctx, project.ID, opts)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get logs of %s: %w", project.Name, err)
+	}
+	entries := page.Entries
+	if len(entries) > l.Lines {
+		entries = entries[len(entries)-l.Lines:]
+	}
+	opts.Cursor = page.NextCursor
+
+	asJSON := jsonOutput(l.JSON)
+	for _, entry := range entries {
+		if err := printLogEntry(entry, asJSON); err != nil {
+			return err
+		}
+	}
+	if !l.Follow {
+		if len(entries) == 0 && l.Lines > 0 && !asJSON {
+			fmt.Println(theme.MutedMsg(fmt.Sprintf("No logs for %s yet.", project.Name)))
+		}
+		return nil
+	}
+
+	handle := func(entry api.LogEntry) error {
+		return printLogEntry(entry, asJSON)
+	}
+	finished := "Deployment finished."
+	if l.Build != "" {
+		err = apiClient.FollowBuildLogs(ctx, project.ID, l.Build, opts, handle)
+		finished = "Build finished."
+	} else {
+		err = apiClient.FollowProjectLogs(ctx, project.ID, opts, handle)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to follow logs of %s: %w", project.Name, err)
+	}
+	if !asJSON {
+		fmt.Println(theme.MutedMsg(finished))
 	}
-	fmt.Println(theme.NotImplementedWithDesc(fmt.Sprintf("logs %s", l.Project), desc))
 	return nil
 }
 
+// printLogEntry writes a log entry as a line of JSON or as a line of text
+// colored by its level
+func printLogEntry(entry api.LogEntry, asJSON bool) error {
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetEscapeHTML(false)
+		return encoder.Encode(entry)
+	}
+
+	styles, ok := logLevelStyles[entry.Level]
+	if !ok {
+		styles = logLevelStyles["info"]
+	}
+	line := theme.MutedMsg(entry.Time.Local().Format("2006-01-02 15:04:05")) + " " +
+		styles.level.Render(fmt.Sprintf("%-5s", strings.ToUpper(entry.Level)))
+	if entry.Source != "" {
+		line += " " + theme.MutedMsg("["+entry.Source+"]")
+	}
+	_, err := fmt.Println(line + " " + styles.message.Render(entry.Message))
+	return err
+}
+
 // DeploymentsCmd views deployment history
 type DeploymentsCmd struct {
-	Project string `arg:"" help:"Project name" required:"true"`
+	Project string `arg:"" optional:"" help:"Project name (defaults to the linked project)"`
 	Limit   int    `help:"Number of deployments to show" default:"10"`
 	JSON    bool   `help:"Output in JSON format" default:"false"`
-}
+	Branch  string `help:"Only deployments of this commit branch" default:""`
+	Status  string `help:"Only deployments with this status (pending/success/failed)" default:""`
+	Author  string `help:"Only deployments by this user (email or ID)" default:""`
+	Since   string `help:"Only deployments since this date or age (e.g., 2025-01-31, 7d, 12h)" default:""`
+	Until   string `help:"Only deployments until this date or age (e.g., 2025-01-31, 7d, 12h)" default:""`
+	Cursor  string `help:"Continue after the deployments of an earlier page" default:""`
+	Format  string `help:"Go template for each deployment, e.g. '{{.Commit}} {{.Message}} ({{.Author}})'; see the docs for fields" default:""`
+}
+
+// deploymentRow is a deployment as the table and --format templates see it
+type deploymentRow struct {
+	ID        string
+	ShortID   string
+	Status    string
+	URL       string
+	Branch    string
+	CommitSHA string
+	// Commit is the abbreviated CommitSHA
+	Commit    string
+	CommitURL string
+	// Message is the subject line of the commit message
+	Message   string
+	Author    string
+	CreatedAt time.Time
+	// Age is how long ago the deployment was created, e.g. "3h ago"
+	Age      string
+	Size     int64
+	Duration time.Duration
+}
+
+func (d *DeploymentsCmd) Run(ctx context.Context, apiClient api.API) error {
+	name, err := projectArg(d.Project)
+	if err != nil {
+		return err
+	}
+	if d.Limit < 1 {
+		return fmt.Errorf("invalid limit %d: expected at least 1", d.Limit)
+	}
+	if d.Status != "" && d.Status != api.StatusPending && d.Status != api.StatusSuccess && d.Status != api.StatusFailed {
+		return fmt.Errorf("invalid status '%s': expected pending, success or failed", d.Status)
+	}
+	opts := &api.DeploymentListOptions{Cursor: d.Cursor, Status: d.Status, Branch: d.Branch, Author: d.Author}
+	if opts.Since, err = parseTimeFlag("since", d.Since); err != nil {
+		return err
+	}
+	if opts.Until, err = parseTimeFlag("until", d.Until); err != nil {
+		return err
+	}
+	var tmpl *template.Template
+	if d.Format != "" {
+		if tmpl, err = template.New("format").Parse(d.Format + "\n"); err != nil {
+			return fmt.Errorf("invalid --format template: %w", err)
+		}
+	}
 
-func (d *DeploymentsCmd) Run() error {
-	fmt.Println(theme.NotImplementedWithDesc(
-		fmt.Sprintf("deployments %s", d.Project),
-		"This will show past deployments with timestamps, commit info, and status",
-	))
+	if err := requireAuth(apiClient); err != nil {
+		return err
+	}
+
+	project, err := apiClient.FindProject(ctx, name)
+	if err != nil {
+		return fmt.Errorf("failed to resolve project: %w", err)
+	}
+
+	// Follow the cursor until there are enough deployments; each page asks
+	// for the rest only, so the last cursor continues right after them
+	list := &api.DeploymentList{Deployments: []api.Deployment{}}
+	for len(list.Deployments) < d.Limit {
+		opts.Limit = d.Limit - len(list.Deployments)
+		page, err := apiClient.ListDeployments(ctx, project.ID, opts)
+		if err != nil {
+			return fmt.Errorf("failed to list deployments of %s: %w", project.Name, err)
+		}
+		list.Deployments = append(list.Deployments, page.Deployments...)
+		list.NextCursor = page.NextCursor
+		if page.NextCursor == "" || len(page.Deployments) == 0 {
+			break
+		}
+		opts.Cursor = page.NextCursor
+	}
+
+	// --json wins over --format, which wins over the output setting
+	if d.JSON || (tmpl == nil && jsonOutput(d.JSON)) {
+		return printJSON(list)
+	}
+
+	if tmpl != nil {
+		for _, deployment := range list.Deployments {
+			if err := tmpl.Execute(os.Stdout, newDeploymentRow(deployment)); err != nil {
+				return fmt.Errorf("failed to render --format template: %w", err)
+			}
+		}
+		return nil
+	}
+
+	if len(list.Deployments) == 0 {
+		fmt.Printf("No deployments of %s match.\n", project.Name)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tAGE\tSTATUS\tCOMMIT\tAUTHOR\tSIZE\tDURATION")
+	for _, deployment := range list.Deployments {
+		row := newDeploymentRow(deployment)
+		commit, size, duration := "-", "-", "-"
+		if row.Commit != "" {
+			commit = strings.TrimSpace(row.Commit + " " + truncate(row.Message, 50))
+		}
+		if row.Size > 0 {
+			size = formatBytes(row.Size)
+		}
+		if row.Duration > 0 {
+			duration = row.Duration.Round(100 * time.Millisecond).String()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", row.ShortID, row.Age, row.Status, commit, row.Author, size, duration)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if list.NextCursor != "" {
+		fmt.Println(theme.MutedMsg(fmt.Sprintf("More deployments: add --cursor %s", list.NextCursor)))
+	}
 	return nil
 }
 
+// newDeploymentRow flattens a deployment for display
+func newDeploymentRow(deployment api.Deployment) deploymentRow {
+	row := deploymentRow{
+		ID:        deployment.ID,
+		ShortID:   shortID(deployment.ID),
+		Status:    deployment.Status,
+		URL:       deployment.URL,
+		Author:    deployment.UserID,
+		CreatedAt: deployment.CreatedAt,
+		Age:       formatAge(deployment.CreatedAt),
+		Size:      deployment.ArchiveSize,
+		Duration:  time.Duration(deployment.DurationMS) * time.Millisecond,
+	}
+	if deployment.UserEmail != nil {
+		row.Author = *deployment.UserEmail
+	}
+	if deployment.CommitBranch != nil {
+		row.Branch = *deployment.CommitBranch
+	}
+	if deployment.CommitSHA != nil {
+		row.CommitSHA = *deployment.CommitSHA
+		row.Commit = shortSHA(*deployment.CommitSHA)
+	}
+	if deployment.CommitURL != nil {
+		row.CommitURL = *deployment.CommitURL
+	}
+	if deployment.CommitMessage != nil {
+		row.Message, _, _ = strings.Cut(*deployment.CommitMessage, "\n")
+	}
+	return row
+}
+
+// shortID abbreviates a UUID to its first group, like a short commit SHA.
+// Other IDs are short enough already.
+func shortID(id string) string {
+	if len(id) == 36 && strings.Count(id, "-") == 4 {
+		return id[:8]
+	}
+	return id
+}
+
+// formatAge renders how long ago t was, e.g. "5m ago" or "3d ago"
+func formatAge(t time.Time) string {
+	age := time.Since(t)
+	switch {
+	case age < time.Minute:
+		return "just now"
+	case age < time.Hour:
+		return fmt.Sprintf("%dm ago", int(age.Minutes()))
+	case age < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(age.Hours()))
+	case age < 60*24*time.Hour:
+		return fmt.Sprintf("%dd ago", int(age.Hours()/24))
+	}
+	return formatDate(t)
+}
+
+// truncate shortens s to at most n runes, marking the cut with an ellipsis
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
+
+// parseTimeFlag parses a date (2006-01-02), an RFC 3339 time or an age
+// such as 7d or 12h, which means that long ago. A date is the start of the
+// day, or its end for --until, so the day itself is included. Empty values
+// give the zero time.
+func parseTimeFlag(flag, value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		if flag == "until" {
+			t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+		return t, nil
+	}
+
+	var age time.Duration
+	var err error
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		var n int
+		n, err = strconv.Atoi(days)
+		age = time.Duration(n) * 24 * time.Hour
+	} else {
+		age, err = time.ParseDuration(value)
+	}
+	if err != nil || age <= 0 {
+		return time.Time{}, fmt.Errorf("invalid --%s '%s': expected a date such as 2025-01-31 or an age such as 7d or 12h", flag, value)
+	}
+	return time.Now().Add(-age), nil
+}
+
 // WhoamiCmd displays current user information
 type WhoamiCmd struct {
 	JSON bool `help:"Output in JSON format" default:"false"`
@@ -931,29 +2566,39 @@ func (w *WhoamiCmd) Run() error {
 
 // RollbackCmd rolls back to a previous deployment
 type RollbackCmd struct {
-	Project      string `arg:"" help:"Project name" required:"true"`
+	Project      string `arg:"" optional:"" help:"Project name (defaults to the linked project)"`
 	DeploymentID string `help:"Deployment ID to rollback to (defaults to last successful)" default:""`
 	Force        bool   `help:"Skip confirmation prompt" short:"f" default:"false"`
 }
 
 func (r *RollbackCmd) Run() error {
+	project, err := projectArg(r.Project)
+	if err != nil {
+		return err
+	}
+
 	desc := "This will rollback to the last successful deployment"
 	if r.DeploymentID != "" {
 		desc = fmt.Sprintf("This will rollback to deployment: %s", r.DeploymentID)
 	}
-	fmt.Println(theme.NotImplementedWithDesc(fmt.Sprintf("rollback %s", r.Project), desc))
+	fmt.Println(theme.NotImplementedWithDesc(fmt.Sprintf("rollback %s", project), desc))
 	return nil
 }
 
 // DeleteCmd deletes a deployed project
 type DeleteCmd struct {
-	Project string `arg:"" help:"Project name" required:"true"`
+	Project string `arg:"" optional:"" help:"Project name (defaults to the linked project)"`
 	Force   bool   `help:"Skip confirmation prompt" short:"f" default:"false"`
 }
 
 func (d *DeleteCmd) Run() error {
+	project, err := projectArg(d.Project)
+	if err != nil {
+		return err
+	}
+
 	fmt.Println(theme.NotImplementedWithDesc(
-		fmt.Sprintf("delete %s", d.Project),
+		fmt.Sprintf("delete %s", project),
 		"This will remove the project from the platform with confirmation",
 	))
 	return nil
@@ -961,16 +2606,21 @@ func (d *DeleteCmd) Run() error {
 
 // OpenCmd opens project URL in browser
 type OpenCmd struct {
-	Project   string `arg:"" help:"Project name" required:"true"`
+	Project   string `arg:"" optional:"" help:"Project name (defaults to the linked project)"`
 	Dashboard bool   `help:"Open dashboard page instead of project URL" default:"false"`
 }
 
 func (o *OpenCmd) Run() error {
+	project, err := projectArg(o.Project)
+	if err != nil {
+		return err
+	}
+
 	desc := "This will open the project URL in your browser"
 	if o.Dashboard {
 		desc = "This will open the dashboard page in your browser"
 	}
-	fmt.Println(theme.NotImplementedWithDesc(fmt.Sprintf("open %s", o.Project), desc))
+	fmt.Println(theme.NotImplementedWithDesc(fmt.Sprintf("open %s", project), desc))
 	return nil
 }
 
@@ -987,19 +2637,166 @@ func (v *ValidateCmd) Run() error {
 	return nil
 }
 
-// LinkCmd links local directory to remote project
-type LinkCmd struct {
-	Project string `arg:"" help:"Project name" required:"true"`
+// LinkCmd links local directory to remote project
+type LinkCmd struct {
+	Project string `arg:"" help:"Project name or ID" required:"true"`
+	Force   bool   `help:"Replace an existing link to a different project" short:"f" default:"false"`
+}
+
+func (l *LinkCmd) Run(ctx context.Context, apiClient api.API) error {
+	if err := requireAuth(apiClient); err != nil {
+		return err
+	}
+
+	dir, err := linkDir()
+	if err != nil {
+		return err
+	}
+
+	lookupSpinner := pin.New(fmt.Sprintf("Looking up project '%s'...", l.Project),
+		pin.WithSpinnerColor(pin.ColorMagenta),
+		pin.WithTextColor(pin.ColorMagenta),
+	)
+	lookupCancel := lookupSpinner.Start(ctx)
+
+	project, err := apiClient.FindProject(ctx, l.Project)
+	if err != nil {
+		lookupCancel()
+		lookupSpinner.Fail("Project lookup failed")
+		return fmt.Errorf("failed to resolve project: %w", err)
+	}
+
+	lookupCancel()
+	lookupSpinner.Stop(fmt.Sprintf("Found project '%s'", project.Name))
+
+	// Don't silently re-point a directory at another project
+	if existing, err := link.Load(dir); err == nil && existing.ProjectID != project.ID && !l.Force {
+		return fmt.Errorf("%s is already linked to project %s. Use --force to replace the link", displayPath(dir), bindingLabel(existing))
+	}
+
+	binding := &link.Binding{
+		ProjectID:   project.ID,
+		TenantID:    project.TenantID,
+		ProjectName: project.Name,
+		LinkedAt:    time.Now().UTC(),
+	}
+	if err := link.Save(dir, binding); err != nil {
+		return err
+	}
+
+	logging.Info().Str("project_id", project.ID).Str("dir", dir).Msg("linked directory to project")
+	fmt.Println(theme.SuccessMsg(fmt.Sprintf("Linked %s to project '%s' (%s)", displayPath(dir), project.Name, project.ID)))
+	fmt.Println(theme.MutedMsg(fmt.Sprintf("Saved %s. Project commands now default to this project.", displayPath(binding.Path))))
+	return nil
+}
+
+// UnlinkCmd removes the link between the local directory and a remote project
+type UnlinkCmd struct{}
+
+func (u *UnlinkCmd) Run() error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current working directory: %w", err)
+	}
+
+	binding, err := link.Find(cwd)
+	if errors.Is(err, link.ErrNotLinked) {
+		fmt.Println("This directory is not linked to a project.")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := link.Remove(binding.Dir()); err != nil {
+		return err
+	}
+
+	logging.Info().Str("project_id", binding.ProjectID).Str("dir", binding.Dir()).Msg("unlinked directory")
+	fmt.Println(theme.SuccessMsg(fmt.Sprintf("Unlinked %s from project %s", displayPath(binding.Dir()), bindingLabel(binding))))
+	return nil
+}
+
+// linkDir returns the directory a new link is written to: the directory of
+// the SPA config file when there is one, otherwise the working directory
+func linkDir() (string, error) {
+	if configPath, err := resolveConfigPath(); err == nil {
+		return filepath.Dir(configPath), nil
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to get current working directory: %w", err)
+	}
+	return cwd, nil
+}
+
+// findBinding returns the link for the working directory, or nil when it is
+// not linked
+func findBinding() (*link.Binding, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current working directory: %w", err)
+	}
+	binding, err := link.Find(cwd)
+	if errors.Is(err, link.ErrNotLinked) {
+		return nil, nil
+	}
+	return binding, err
+}
+
+// bindingLabel describes a binding for messages
+func bindingLabel(binding *link.Binding) string {
+	if binding.ProjectName != "" {
+		return fmt.Sprintf("'%s' (%s)", binding.ProjectName, binding.ProjectID)
+	}
+	return binding.ProjectID
+}
+
+// projectArg returns the project a command acts on: the explicit argument,
+// then GODEPLOY_PROJECT, then the linked project, then the default-project
+// setting. It does not contact the API.
+func projectArg(arg string) (string, error) {
+	if arg != "" {
+		return arg, nil
+	}
+	fallback, err := settings.Get(settings.DefaultProject)
+	if err != nil {
+		return "", err
+	}
+	if fallback.Origin == settings.OriginEnv {
+		return fallback.Value, nil
+	}
+	binding, err := findBinding()
+	if err != nil {
+		return "", err
+	}
+	if binding == nil {
+		if fallback.Value != "" {
+			return fallback.Value, nil
+		}
+		return "", errNoProject
+	}
+	// The name saved at link time may be stale; the ID survives renames
+	return binding.ProjectID, nil
 }
 
-func (l *LinkCmd) Run() error {
-	fmt.Println(theme.NotImplementedWithDesc(
-		fmt.Sprintf("link %s", l.Project),
-		"This will associate this directory with the remote project",
-	))
-	return nil
+// linkedProject fetches the project a binding points at
+func linkedProject(ctx context.Context, apiClient api.API, binding *link.Binding) (*api.Project, error) {
+	projects, err := apiClient.ListProjects(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve linked project: %w", err)
+	}
+	for i := range projects {
+		if projects[i].ID == binding.ProjectID {
+			return &projects[i], nil
+		}
+	}
+	return nil, fmt.Errorf("linked project %s was not found. It may have been deleted; run 'godeploy link <project>' to link again", bindingLabel(binding))
 }
 
+// errNoProject is returned when a project command has no argument and no link
+var errNoProject = errors.New("no project specified and this directory is not linked. Pass a project name, run 'godeploy link <project>' or set default-project with 'godeploy cli-config set'")
+
 // PreviewCmd creates a preview deployment
 type PreviewCmd struct {
 	Name string `help:"Preview name" default:""`
@@ -1013,12 +2810,17 @@ func (p *PreviewCmd) Run() error {
 
 // DiffCmd shows differences between local and deployed
 type DiffCmd struct {
-	Project string `arg:"" help:"Project name" required:"true"`
+	Project string `arg:"" optional:"" help:"Project name (defaults to the linked project)"`
 }
 
 func (d *DiffCmd) Run() error {
+	project, err := projectArg(d.Project)
+	if err != nil {
+		return err
+	}
+
 	fmt.Println(theme.NotImplementedWithDesc(
-		fmt.Sprintf("diff %s", d.Project),
+		fmt.Sprintf("diff %s", project),
 		"This will compare local build with deployed version",
 	))
 	return nil
@@ -1033,11 +2835,16 @@ type EnvCmd struct {
 }
 
 type EnvListCmd struct {
-	Project string `arg:"" help:"Project name" required:"true"`
+	Project string `arg:"" optional:"" help:"Project name (defaults to the linked project)"`
 }
 
 func (e *EnvListCmd) Run() error {
-	fmt.Println(theme.NotImplementedMsg(fmt.Sprintf("env list %s", e.Project)))
+	project, err := projectArg(e.Project)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(theme.NotImplementedMsg(fmt.Sprintf("env list %s", project)))
 	return nil
 }
 
@@ -1063,12 +2870,17 @@ func (e *EnvUnsetCmd) Run() error {
 }
 
 type EnvPullCmd struct {
-	Project string `arg:"" help:"Project name" required:"true"`
+	Project string `arg:"" optional:"" help:"Project name (defaults to the linked project)"`
 	Output  string `help:"Output file" default:".env"`
 }
 
 func (e *EnvPullCmd) Run() error {
-	fmt.Println(theme.NotImplementedMsg(fmt.Sprintf("env pull %s --output %s", e.Project, e.Output)))
+	project, err := projectArg(e.Project)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(theme.NotImplementedMsg(fmt.Sprintf("env pull %s --output %s", project, e.Output)))
 	return nil
 }
 
@@ -1076,42 +2888,166 @@ func (e *EnvPullCmd) Run() error {
 type CLIConfigCmd struct {
 	Get    CLIConfigGetCmd    `cmd:"get" help:"Get a configuration value"`
 	Set    CLIConfigSetCmd    `cmd:"set" help:"Set a configuration value"`
+	Unset  CLIConfigUnsetCmd  `cmd:"unset" help:"Remove a configuration value from the settings file"`
 	List   CLIConfigListCmd   `cmd:"list" help:"List all configuration values"`
-	APIUrl CLIConfigAPIUrlCmd `cmd:"api-url" help:"Set API URL"`
+	APIUrl CLIConfigAPIUrlCmd `cmd:"api-url" help:"Show or set the API URL of the active profile"`
 }
 
 type CLIConfigGetCmd struct {
-	Key string `arg:"" help:"Configuration key" required:"true"`
+	Key string `arg:"" help:"Configuration key (see 'godeploy cli-config list')" required:"true"`
 }
 
 func (c *CLIConfigGetCmd) Run() error {
-	fmt.Println(theme.NotImplementedMsg(fmt.Sprintf("cli-config get %s", c.Key)))
+	value, err := settings.Get(c.Key)
+	if errors.Is(err, settings.ErrUnknownKey) {
+		return err
+	}
+	if err != nil {
+		fmt.Println(theme.WarningMsg(err.Error()))
+	}
+	fmt.Println(value.Value)
 	return nil
 }
 
 type CLIConfigSetCmd struct {
-	Key   string `arg:"" help:"Configuration key" required:"true"`
+	Key   string `arg:"" help:"Configuration key: api-url, default-project, output, color, deploy-timeout or telemetry" required:"true"`
 	Value string `arg:"" help:"Configuration value" required:"true"`
 }
 
 func (c *CLIConfigSetCmd) Run() error {
-	fmt.Println(theme.NotImplementedMsg(fmt.Sprintf("cli-config set %s=%s", c.Key, c.Value)))
+	if err := settings.Set(c.Key, c.Value); err != nil {
+		return err
+	}
+
+	value, _ := settings.Get(c.Key)
+	fmt.Println(theme.SuccessMsg(fmt.Sprintf("Set %s to %s", c.Key, value.Value)))
+	if value.Origin == settings.OriginEnv {
+		fmt.Println(theme.WarningMsg(fmt.Sprintf("%s is set and takes precedence.", value.Key.EnvVar)))
+	}
+	return nil
+}
+
+type CLIConfigUnsetCmd struct {
+	Key string `arg:"" help:"Configuration key (see 'godeploy cli-config list')" required:"true"`
+}
+
+func (c *CLIConfigUnsetCmd) Run() error {
+	if err := settings.Unset(c.Key); err != nil {
+		return err
+	}
+	value, _ := settings.Get(c.Key)
+	fmt.Println(theme.SuccessMsg(fmt.Sprintf("Unset %s; it is now %s (%s)", c.Key, displaySetting(value.Value), value.Origin)))
 	return nil
 }
 
-type CLIConfigListCmd struct{}
+type CLIConfigListCmd struct {
+	ShowOrigin bool `name:"show-origin" help:"Show where each value comes from" default:"false"`
+	JSON       bool `help:"Output in JSON format" default:"false"`
+}
 
 func (c *CLIConfigListCmd) Run() error {
-	fmt.Println(theme.NotImplementedMsg("cli-config list"))
+	values, err := settings.List()
+	if values == nil {
+		return err
+	}
+
+	if jsonOutput(c.JSON) {
+		type entry struct {
+			Key    string `json:"key"`
+			Value  string `json:"value"`
+			Origin string `json:"origin"`
+			EnvVar string `json:"env_var,omitempty"`
+		}
+		entries := make([]entry, len(values))
+		for i, v := range values {
+			entries[i] = entry{Key: v.Key.Name, Value: v.Value, Origin: string(v.Origin), EnvVar: v.Key.EnvVar}
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, theme.WarningMsg(err.Error()))
+		}
+		return printJSON(entries)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if c.ShowOrigin {
+		fmt.Fprintln(w, "KEY\tVALUE\tORIGIN")
+	} else {
+		fmt.Fprintln(w, "KEY\tVALUE")
+	}
+	for _, v := range values {
+		if c.ShowOrigin {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", v.Key.Name, displaySetting(v.Value), settingOrigin(v))
+		} else {
+			fmt.Fprintf(w, "%s\t%s\n", v.Key.Name, displaySetting(v.Value))
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if c.ShowOrigin {
+		fmt.Println(theme.MutedMsg(fmt.Sprintf("Settings file: %s", settings.Path())))
+	}
+	if err != nil {
+		fmt.Println(theme.WarningMsg(err.Error()))
+	}
 	return nil
 }
 
+// displaySetting shows an empty setting as "(none)"
+func displaySetting(value string) string {
+	if value == "" {
+		return "(none)"
+	}
+	return value
+}
+
+// settingOrigin describes where a setting's value came from
+func settingOrigin(v settings.Value) string {
+	switch v.Origin {
+	case settings.OriginEnv:
+		return fmt.Sprintf("env %s", v.Key.EnvVar)
+	case settings.OriginFile:
+		return "settings file"
+	}
+	return string(v.Origin)
+}
+
 type CLIConfigAPIUrlCmd struct {
-	URL string `arg:"" help:"API URL" required:"true"`
+	URL   string `arg:"" optional:"" help:"API URL to save with the active profile"`
+	Reset bool   `help:"Go back to the default API URL" default:"false"`
 }
 
 func (c *CLIConfigAPIUrlCmd) Run() error {
-	fmt.Println(theme.NotImplementedMsg(fmt.Sprintf("cli-config api-url %s", c.URL)))
+	if c.URL == "" && !c.Reset {
+		apiURL, source := api.ResolveAPIURL()
+		fmt.Println(apiURL)
+		fmt.Println(theme.MutedMsg(fmt.Sprintf("Source: %s", source)))
+		return nil
+	}
+	if c.URL != "" && c.Reset {
+		return fmt.Errorf("pass either an API URL or --reset, not both")
+	}
+
+	apiURL := ""
+	if !c.Reset {
+		var err error
+		if apiURL, err = normalizeAPIURL(c.URL); err != nil {
+			return err
+		}
+	}
+	if err := auth.SetAPIURL(apiURL); err != nil {
+		return fmt.Errorf("failed to save API URL: %w", err)
+	}
+
+	profile, _ := auth.ActiveProfile()
+	if apiURL == "" {
+		fmt.Println(theme.SuccessMsg(fmt.Sprintf("Profile '%s' now uses the default API URL (%s)", profile, api.DefaultAPIBaseURL)))
+	} else {
+		fmt.Println(theme.SuccessMsg(fmt.Sprintf("Profile '%s' now uses %s", profile, apiURL)))
+	}
+	if os.Getenv(api.APIURLEnvVar) != "" {
+		fmt.Println(theme.WarningMsg(fmt.Sprintf("%s is set and takes precedence.", api.APIURLEnvVar)))
+	}
 	return nil
 }
 
@@ -1124,11 +3060,16 @@ type DomainsCmd struct {
 }
 
 type DomainsListCmd struct {
-	Project string `arg:"" help:"Project name" required:"true"`
+	Project string `arg:"" optional:"" help:"Project name (defaults to the linked project)"`
 }
 
 func (d *DomainsListCmd) Run() error {
-	fmt.Println(theme.NotImplementedMsg(fmt.Sprintf("domains list %s", d.Project)))
+	project, err := projectArg(d.Project)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(theme.NotImplementedMsg(fmt.Sprintf("domains list %s", project)))
 	return nil
 }
 
@@ -1169,11 +3110,16 @@ type AliasesCmd struct {
 }
 
 type AliasesListCmd struct {
-	Project string `arg:"" help:"Project name" required:"true"`
+	Project string `arg:"" optional:"" help:"Project name (defaults to the linked project)"`
 }
 
 func (a *AliasesListCmd) Run() error {
-	fmt.Println(theme.NotImplementedMsg(fmt.Sprintf("aliases list %s", a.Project)))
+	project, err := projectArg(a.Project)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(theme.NotImplementedMsg(fmt.Sprintf("aliases list %s", project)))
 	return nil
 }
 
@@ -1199,24 +3145,34 @@ func (a *AliasesRemoveCmd) Run() error {
 
 // MetricsCmd views project metrics
 type MetricsCmd struct {
-	Project string `arg:"" help:"Project name" required:"true"`
+	Project string `arg:"" optional:"" help:"Project name (defaults to the linked project)"`
 	Period  string `help:"Time period (e.g., 7d, 30d)" default:"7d"`
 	JSON    bool   `help:"Output in JSON format" default:"false"`
 }
 
 func (m *MetricsCmd) Run() error {
-	fmt.Println(theme.NotImplementedMsg(fmt.Sprintf("metrics %s --period %s", m.Project, m.Period)))
+	project, err := projectArg(m.Project)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(theme.NotImplementedMsg(fmt.Sprintf("metrics %s --period %s", project, m.Period)))
 	return nil
 }
 
 // AnalyticsCmd opens analytics dashboard
 type AnalyticsCmd struct {
-	Project string `arg:"" help:"Project name" required:"true"`
+	Project string `arg:"" optional:"" help:"Project name (defaults to the linked project)"`
 }
 
 func (a *AnalyticsCmd) Run() error {
+	project, err := projectArg(a.Project)
+	if err != nil {
+		return err
+	}
+
 	fmt.Println(theme.NotImplementedWithDesc(
-		fmt.Sprintf("analytics %s", a.Project),
+		fmt.Sprintf("analytics %s", project),
 		"This will open the analytics dashboard in your browser",
 	))
 	return nil
@@ -1224,13 +3180,18 @@ func (a *AnalyticsCmd) Run() error {
 
 // HealthCmd checks project health
 type HealthCmd struct {
-	Project string `arg:"" help:"Project name" required:"true"`
+	Project string `arg:"" optional:"" help:"Project name (defaults to the linked project)"`
 	Verbose bool   `help:"Show detailed health information" default:"false"`
 }
 
 func (h *HealthCmd) Run() error {
+	project, err := projectArg(h.Project)
+	if err != nil {
+		return err
+	}
+
 	fmt.Println(theme.NotImplementedWithDesc(
-		fmt.Sprintf("health %s", h.Project),
+		fmt.Sprintf("health %s", project),
 		"This will ping deployment, check CDN, and verify SSL certificate",
 	))
 	return nil
@@ -1285,20 +3246,82 @@ type TokensCmd struct {
 	Revoke TokensRevokeCmd `cmd:"revoke" help:"Revoke an API token"`
 }
 
-type TokensListCmd struct{}
+type TokensListCmd struct {
+	JSON bool `help:"Output in JSON format" default:"false"`
+}
 
-func (t *TokensListCmd) Run() error {
-	fmt.Println(theme.NotImplementedMsg("tokens list"))
-	return nil
+func (t *TokensListCmd) Run(ctx context.Context, apiClient api.API) error {
+	if err := requireAuth(apiClient); err != nil {
+		return err
+	}
+
+	tokens, err := apiClient.ListTokens(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list tokens: %w", err)
+	}
+
+	if jsonOutput(t.JSON) {
+		return printJSON(tokens)
+	}
+
+	if len(tokens) == 0 {
+		fmt.Println("No API tokens. Create one with 'godeploy tokens create --name <name>'.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tCREATED\tEXPIRES\tLAST USED")
+	for _, token := range tokens {
+		expires := "never"
+		if token.ExpiresAt != nil {
+			expires = formatDate(*token.ExpiresAt)
+		}
+		lastUsed := "never"
+		if token.LastUsedAt != nil {
+			lastUsed = formatDate(*token.LastUsedAt)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", token.ID, token.Name, formatDate(token.CreatedAt), expires, lastUsed)
+	}
+	return w.Flush()
 }
 
 type TokensCreateCmd struct {
-	Name    string `help:"Token name" default:""`
-	Expires string `help:"Expiration duration (e.g., 90d)" default:"90d"`
+	Name    string `help:"Token name" required:"true"`
+	Expires string `help:"Expiration (e.g., 90d, 12h, or 'never')" default:"90d"`
 }
 
-func (t *TokensCreateCmd) Run() error {
-	fmt.Println(theme.NotImplementedMsg(fmt.Sprintf("tokens create --expires %s", t.Expires)))
+func (t *TokensCreateCmd) Run(ctx context.Context, apiClient api.API) error {
+	expiresAt, err := parseExpiry(t.Expires)
+	if err != nil {
+		return err
+	}
+
+	if err := requireAuth(apiClient); err != nil {
+		return err
+	}
+
+	created, err := apiClient.CreateToken(ctx, t.Name, expiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to create token: %w", err)
+	}
+	logging.Info().Str("token_id", created.ID).Msg("api token created")
+
+	// Print only the secret when piped, e.g. into a CI secret store
+	if !term.IsTerminal(int(os.Stdout.Fd())) {
+		fmt.Println(created.Token)
+		return nil
+	}
+
+	expires := "never"
+	if created.ExpiresAt != nil {
+		expires = formatDate(*created.ExpiresAt)
+	}
+	fmt.Println(theme.SuccessMsg(fmt.Sprintf("Created API token '%s' (%s), expires %s", created.Name, created.ID, expires)))
+	fmt.Println()
+	fmt.Println(created.Token)
+	fmt.Println()
+	fmt.Println(theme.WarningMsg("Copy this token now. It will not be shown again."))
+	fmt.Println(theme.MutedMsg(fmt.Sprintf("Use it with --token or the %s environment variable.", auth.TokenEnvVar)))
 	return nil
 }
 
@@ -1306,11 +3329,65 @@ type TokensRevokeCmd struct {
 	ID string `arg:"" help:"Token ID" required:"true"`
 }
 
-func (t *TokensRevokeCmd) Run() error {
-	fmt.Println(theme.NotImplementedMsg(fmt.Sprintf("tokens revoke %s", t.ID)))
+func (t *TokensRevokeCmd) Run(ctx context.Context, apiClient api.API) error {
+	if err := requireAuth(apiClient); err != nil {
+		return err
+	}
+
+	if err := apiClient.RevokeToken(ctx, t.ID); err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+
+	logging.Info().Str("token_id", t.ID).Msg("api token revoked")
+	fmt.Println(theme.SuccessMsg(fmt.Sprintf("Revoked API token %s", t.ID)))
 	return nil
 }
 
+// parseExpiry converts a token expiration such as "90d", "12h" or "never"
+// into an absolute time; nil means the token does not expire
+func parseExpiry(value string) (*time.Time, error) {
+	value = strings.TrimSpace(strings.ToLower(value))
+	if value == "never" || value == "0" {
+		return nil, nil
+	}
+
+	var d time.Duration
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid expiration '%s', expected e.g. 90d, 12h or never", value)
+		}
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		d, err = time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid expiration '%s', expected e.g. 90d, 12h or never", value)
+		}
+	}
+
+	expiresAt := time.Now().Add(d).UTC()
+	return &expiresAt, nil
+}
+
+// formatDate renders a timestamp in local time for tables
+func formatDate(t time.Time) string {
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+// jsonOutput reports whether a list command prints JSON: with --json, or
+// when the output setting is json
+func jsonOutput(flag bool) bool {
+	return flag || settings.String(settings.Output) == "json"
+}
+
+// printJSON writes v to stdout as indented JSON
+func printJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
 // PromoteCmd promotes deployment between projects
 type PromoteCmd struct {
 	Source string `arg:"" help:"Source project name" required:"true"`
@@ -1342,11 +3419,16 @@ type CacheCmd struct {
 }
 
 type CacheClearCmd struct {
-	Project string `arg:"" help:"Project name" required:"true"`
+	Project string `arg:"" optional:"" help:"Project name (defaults to the linked project)"`
 }
 
 func (c *CacheClearCmd) Run() error {
-	fmt.Println(theme.NotImplementedMsg(fmt.Sprintf("cache clear %s", c.Project)))
+	project, err := projectArg(c.Project)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(theme.NotImplementedMsg(fmt.Sprintf("cache clear %s", project)))
 	return nil
 }
 
@@ -1361,11 +3443,16 @@ func (c *CachePurgeCmd) Run() error {
 }
 
 type CacheStatsCmd struct {
-	Project string `arg:"" help:"Project name" required:"true"`
+	Project string `arg:"" optional:"" help:"Project name (defaults to the linked project)"`
 }
 
 func (c *CacheStatsCmd) Run() error {
-	fmt.Println(theme.NotImplementedMsg(fmt.Sprintf("cache stats %s", c.Project)))
+	project, err := projectArg(c.Project)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(theme.NotImplementedMsg(fmt.Sprintf("cache stats %s", project)))
 	return nil
 }
 
@@ -1376,31 +3463,194 @@ type BuildsCmd struct {
 }
 
 type BuildsRunCmd struct {
-	BuildCmd string `help:"Build command to run" default:""`
+	Project  string `help:"Project name (defaults to the first enabled app)" default:""`
+	BuildCmd string `help:"Build command to run (overrides the configured command)" default:""`
 }
 
-func (b *BuildsRunCmd) Run() error {
-	if b.BuildCmd != "" {
-		fmt.Println(theme.NotImplementedMsg(fmt.Sprintf("builds run --build-cmd %s", b.BuildCmd)))
-	} else {
-		fmt.Println(theme.NotImplementedWithDesc("builds run", "This will auto-detect and run your build command"))
+func (b *BuildsRunCmd) Run(ctx context.Context) error {
+	spaConfig, err := loadSpaConfig()
+	if err != nil {
+		return fmt.Errorf("error loading SPA configuration: %w", err)
 	}
-	return nil
+	fmt.Println(theme.MutedMsg(fmt.Sprintf("Using config: %s", displayPath(spaConfig.Path))))
+
+	app, err := selectApp(spaConfig, b.Project)
+	if err != nil {
+		return err
+	}
+
+	return runAppBuild(ctx, spaConfig, app, b.BuildCmd)
 }
 
 type BuildsConfigCmd struct {
-	Command string `help:"Set build command" default:""`
+	Project    string   `help:"Project name (defaults to the first enabled app)" default:""`
+	Command    string   `help:"Set build command" default:""`
+	OutputDir  string   `name:"output-dir" help:"Set the directory the build is expected to produce" default:""`
+	WorkingDir string   `name:"working-dir" help:"Set the directory the build command runs in" default:""`
+	Env        []string `help:"Set a build environment variable (KEY=VALUE, repeatable)"`
 }
 
 func (b *BuildsConfigCmd) Run() error {
+	spaConfig, err := loadSpaConfig()
+	if err != nil {
+		return fmt.Errorf("error loading SPA configuration: %w", err)
+	}
+	fmt.Println(theme.MutedMsg(fmt.Sprintf("Using config: %s", displayPath(spaConfig.Path))))
+
+	app, err := selectApp(spaConfig, b.Project)
+	if err != nil {
+		return err
+	}
+
+	// Without any settings, show the current build configuration
+	if b.Command == "" && b.OutputDir == "" && b.WorkingDir == "" && len(b.Env) == 0 {
+		fmt.Println(formatBuildConfig(app))
+		return nil
+	}
+
+	buildConfig := config.Build{}
+	if app.Build != nil {
+		buildConfig = *app.Build
+	}
 	if b.Command != "" {
-		fmt.Println(theme.NotImplementedMsg(fmt.Sprintf("builds config --command %s", b.Command)))
-	} else {
-		fmt.Println(theme.NotImplementedMsg("builds config"))
+		buildConfig.Command = b.Command
+	}
+	if b.OutputDir != "" {
+		buildConfig.OutputDir = b.OutputDir
+	}
+	if b.WorkingDir != "" {
+		buildConfig.WorkingDir = b.WorkingDir
+	}
+	for _, kv := range b.Env {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok || key == "" {
+			return fmt.Errorf("invalid environment variable '%s', expected KEY=VALUE", kv)
+		}
+		if buildConfig.Env == nil {
+			buildConfig.Env = map[string]string{}
+		}
+		buildConfig.Env[key] = value
+	}
+
+	for i := range spaConfig.Apps {
+		if spaConfig.Apps[i].Name == app.Name {
+			spaConfig.Apps[i].Build = &buildConfig
+			app = spaConfig.Apps[i]
+		}
 	}
+
+	if err := config.SaveConfig(spaConfig, spaConfig.Path); err != nil {
+		return err
+	}
+
+	fmt.Println(theme.SuccessMsg(fmt.Sprintf("Updated build configuration for '%s'", app.Name)))
+	fmt.Println(formatBuildConfig(app))
 	return nil
 }
 
+// formatBuildConfig renders an app's build configuration
+func formatBuildConfig(app config.App) string {
+	if app.Build == nil || app.Build.Command == "" {
+		return theme.MutedMsg(fmt.Sprintf("No build command configured for '%s'. Set one with 'godeploy builds config --command <cmd>'.", app.Name))
+	}
+
+	outputDir := app.Build.OutputDir
+	if outputDir == "" {
+		outputDir = app.SourceDir
+	}
+	workingDir := app.Build.WorkingDir
+	if workingDir == "" {
+		workingDir = "."
+	}
+
+	lines := []string{
+		theme.KeyValue("Command", app.Build.Command),
+		theme.KeyValue("Working Dir", workingDir),
+		theme.KeyValue("Output Dir", outputDir),
+	}
+	envKeys := make([]string, 0, len(app.Build.Env))
+	for k := range app.Build.Env {
+		envKeys = append(envKeys, k)
+	}
+	sort.Strings(envKeys)
+	for _, k := range envKeys {
+		lines = append(lines, theme.KeyValue("Env", fmt.Sprintf("%s=%s", k, app.Build.Env[k])))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		theme.TitleStyle.Margin(1, 0).Render(fmt.Sprintf("Build: %s", app.Name)),
+		theme.BoxStyle.Margin(1, 0).Render(lipgloss.JoinVertical(lipgloss.Left, lines...)),
+	)
+}
+
+// DevServerCmd runs a local mock of the GoDeploy API
+type DevServerCmd struct {
+	Listen   string `help:"Address to listen on" default:"127.0.0.1:8787"`
+	Dir      string `help:"Directory to store uploaded archives in (default: a temporary directory removed on exit)" default:""`
+	Email    string `help:"Email of the account the server starts with" default:"dev@godeploy.test"`
+	Password string `help:"Password of the account the server starts with" default:"password"`
+}
+
+func (d *DevServerCmd) Run(ctx context.Context) error {
+	dir := d.Dir
+	if dir == "" {
+		tempDir, err := os.MkdirTemp("", "godeploy-dev-server-")
+		if err != nil {
+			return fmt.Errorf("failed to create data directory: %w", err)
+		}
+		defer func() {
+			_ = os.RemoveAll(tempDir)
+		}()
+		dir = tempDir
+	}
+
+	server, err := devserver.New(dir)
+	if err != nil {
+		return err
+	}
+	if err := server.Listen(d.Listen); err != nil {
+		return err
+	}
+	server.Logf = func(format string, args ...any) {
+		fmt.Println(theme.MutedMsg(fmt.Sprintf(format, args...)))
+	}
+	server.API.AddUser(d.Email, d.Password)
+	token := server.API.CreateToken(d.Email, "dev-server")
+
+	fmt.Println(theme.SuccessMsg(fmt.Sprintf("GoDeploy dev server listening on %s", server.URL)))
+	fmt.Println(theme.KeyValue("Archives", filepath.Join(dir, "archives")))
+	fmt.Println(theme.KeyValue("Sites", server.URL+devserver.SitesPath+"<subdomain>/"))
+	fmt.Println(theme.KeyValue("Account", fmt.Sprintf("%s / %s", d.Email, d.Password)))
+	fmt.Println(theme.KeyValue("API token", token))
+	fmt.Println()
+	fmt.Println(theme.MutedMsg("Point the CLI at it in another terminal:"))
+	fmt.Printf("  export %s=%s\n", api.APIURLEnvVar, server.URL)
+	fmt.Printf("  godeploy auth login --email %s --password %s\n", d.Email, d.Password)
+	fmt.Println(theme.MutedMsg("State is kept in memory and lost when the server stops. Press Ctrl-C to stop."))
+	fmt.Println()
+
+	return server.Serve(ctx)
+}
+
+// applyColorSetting turns colored output on or off according to the color
+// setting; "auto" keeps the terminal detection, which honors NO_COLOR
+func applyColorSetting() {
+	switch settings.String(settings.Color) {
+	case "never":
+		lipgloss.SetColorProfile(termenv.Ascii)
+	case "always":
+		lipgloss.SetColorProfile(termenv.ANSI256)
+	}
+}
+
+// cancelGracePeriod is how long a cancelled command gets to stop and clean
+// up before the process exits anyway
+const cancelGracePeriod = 3 * time.Second
+
+// errCancelled is returned when the user interrupted the command; the
+// command has already said so
+var errCancelled = errors.New("cancelled")
+
 // RunCLI parses and executes the CLI commands
 func RunCLI() error {
 	// Check for version flag early
@@ -1447,5 +3697,69 @@ func RunCLI() error {
 		},
 	)
 
-	return ctx.Run()
+	// Encrypted credentials are unlocked interactively when possible
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		auth.PromptPassphrase = func() (string, error) {
+			return readSecret("Passphrase for GoDeploy credentials: ")
+		}
+	}
+
+	// Select the auth profile before any command touches credentials
+	if CLI.Profile != "" {
+		if err := auth.ValidateProfileName(CLI.Profile); err != nil {
+			return err
+		}
+		auth.SetProfile(CLI.Profile)
+	}
+
+	// An API token replaces the saved login for every command
+	if CLI.Token != "" {
+		auth.SetAPIToken(CLI.Token)
+	}
+
+	if CLI.Color != "" {
+		if err := settings.SetFlag(settings.Color, CLI.Color); err != nil {
+			return err
+		}
+	}
+	applyColorSetting()
+
+	// Ctrl-C and SIGTERM cancel the context commands receive, so the running
+	// operation stops and deferred cleanup runs. A second signal, or a
+	// command that doesn't stop in time (e.g. one waiting on a prompt),
+	// exits immediately.
+	runCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	finished := make(chan struct{})
+	defer close(finished)
+	go func() {
+		select {
+		case <-finished:
+			return
+		case <-runCtx.Done():
+		}
+		stop()
+		select {
+		case <-finished:
+		case <-time.After(cancelGracePeriod):
+			fmt.Println(theme.WarningMsg("Cancelled"))
+			os.Exit(exitCancelled)
+		}
+	}()
+	ctx.BindTo(runCtx, (*context.Context)(nil))
+
+	// Commands get the API client as a parameter, so tests can pass a fake.
+	// It is created on first use, after the flags above were applied.
+	if err := ctx.BindSingletonProvider(func() (api.API, error) {
+		return api.NewClient(), nil
+	}); err != nil {
+		return err
+	}
+
+	err := ctx.Run()
+	if err != nil && runCtx.Err() != nil && errors.Is(err, context.Canceled) {
+		fmt.Println(theme.WarningMsg("Cancelled"))
+		return errCancelled
+	}
+	return err
 }
@@ -1,12 +1,51 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
+	"github.com/silvabyte/godeploy/internal/api"
+	"github.com/silvabyte/godeploy/internal/auth"
 	"github.com/silvabyte/godeploy/internal/logging"
 )
 
+// Exit codes are part of the CLI's interface for scripts and CI; keep them
+// stable and document new ones in docs/guides/cli-usage.md
+const (
+	exitError       = 1   // any other failure
+	exitAuth        = 3   // not logged in, rejected credentials or no access
+	exitNotFound    = 4   // project, deployment or other resource not found
+	exitQuota       = 5   // plan quota exceeded or rate limited
+	exitTimeout     = 6   // the API didn't answer in time
+	exitUnavailable = 7   // the API couldn't be reached, failed or lacks the endpoint
+	exitInvalid     = 8   // the API rejected the request data
+	exitCancelled   = 130 // interrupted by Ctrl-C or SIGTERM, as shells report SIGINT
+)
+
+// exitCode maps an error returned by a command to its exit code
+func exitCode(err error) int {
+	switch {
+	case errors.Is(err, errCancelled):
+		return exitCancelled
+	case errors.Is(err, api.ErrUnauthorized), errors.Is(err, api.ErrForbidden),
+		errors.Is(err, api.ErrIncorrectPassword), errors.Is(err, api.ErrInvalidResetToken),
+		errors.Is(err, auth.ErrIncorrectPassphrase):
+		return exitAuth
+	case errors.Is(err, api.ErrNotFound):
+		return exitNotFound
+	case errors.Is(err, api.ErrQuotaExceeded), errors.Is(err, api.ErrRateLimited):
+		return exitQuota
+	case errors.Is(err, api.ErrTimeout):
+		return exitTimeout
+	case errors.Is(err, api.ErrUnavailable), errors.Is(err, api.ErrNotImplemented):
+		return exitUnavailable
+	case errors.Is(err, api.ErrInvalidInput), errors.Is(err, api.ErrConflict):
+		return exitInvalid
+	}
+	return exitError
+}
+
 func main() {
 	// Initialize file logger (silent failure OK - don't break CLI if logging fails)
 	// Only show warning if user explicitly set GODEPLOY_LOG_LEVEL
@@ -17,9 +56,18 @@ func main() {
 	}
 	defer logging.Close()
 
-	if err := RunCLI(); err != nil {
+	if err := RunCLI(); errors.Is(err, errCancelled) {
+		logging.Info().Msg("command cancelled")
+		os.Exit(exitCancelled)
+	} else if err != nil {
 		logging.Err(err, "CLI error")
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+
+		// Support needs the request ID to find the failure in server logs
+		var apiErr *api.Error
+		if errors.As(err, &apiErr) && apiErr.RequestID != "" {
+			fmt.Fprintf(os.Stderr, "Request ID: %s\n", apiErr.RequestID)
+		}
+		os.Exit(exitCode(err))
 	}
 }
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/silvabyte/godeploy/internal/api"
+	"github.com/silvabyte/godeploy/internal/api/apitest"
+)
+
+// TestExitCode tests that API errors map to their documented exit codes
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{errors.New("boom"), exitError},
+		{&api.Error{StatusCode: 401, Kind: api.ErrUnauthorized}, exitAuth},
+		{fmt.Errorf("failed to list projects: %w", &api.Error{StatusCode: 404, Kind: api.ErrNotFound}), exitNotFound},
+		{&api.Error{StatusCode: 402, Kind: api.ErrQuotaExceeded}, exitQuota},
+		{withMessage("deployment failed", &api.Error{Kind: api.ErrTimeout}), exitTimeout},
+		{&api.Error{Kind: api.ErrUnavailable}, exitUnavailable},
+		{&api.Error{StatusCode: 501, Kind: api.ErrNotImplemented}, exitUnavailable},
+		{&api.Error{StatusCode: 400, Kind: api.ErrInvalidInput}, exitInvalid},
+		{errCancelled, exitCancelled},
+	}
+
+	for _, tt := range tests {
+		if got := exitCode(tt.err); got != tt.want {
+			t.Errorf("exitCode(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+// TestExitCodeNotLoggedIn tests that a command run without credentials
+// exits with the auth exit code
+func TestExitCodeNotLoggedIn(t *testing.T) {
+	useTestEnv(t)
+	server := apitest.NewServer(t)
+
+	_, err := runCommand(t, server.Client(), "projects")
+	if err == nil {
+		t.Fatal("Expected projects to fail without credentials")
+	}
+	if got := exitCode(err); got != exitAuth {
+		t.Fatalf("exitCode(%v) = %d, want %d", err, got, exitAuth)
+	}
+}
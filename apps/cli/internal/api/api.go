@@ -0,0 +1,65 @@
+package api
+
+import (
+	"context"
+	"time"
+)
+
+// API is the GoDeploy API as the CLI commands use it. Client implements it;
+// commands receive it through a Kong binding so tests can run them against
+// apitest.Server or another implementation.
+type API interface {
+	// APIURL returns the base URL requests are sent to
+	APIURL() string
+	// SetAPIURL sends later requests to baseURL, e.g. for a login with
+	// --api-url
+	SetAPIURL(baseURL string)
+	// HasAPIToken reports whether requests authenticate with an API token
+	// rather than the saved login
+	HasAPIToken() bool
+
+	InitAuth(ctx context.Context, email, redirectURI string) (*AuthInitResponse, error)
+	SignIn(ctx context.Context, email, password string) (*SignInResponse, error)
+	SignUp(ctx context.Context, email, password string) (*SignUpResponse, error)
+	VerifyToken(ctx context.Context, token string) (*VerifyResponse, error)
+	RefreshToken(ctx context.Context, refreshToken string) (*RefreshResponse, error)
+
+	ChangePassword(ctx context.Context, currentPassword, newPassword string) error
+	RequestPasswordReset(ctx context.Context, email, redirectURI string) error
+	ConfirmPasswordReset(ctx context.Context, token, newPassword string) error
+
+	RevokeSession(ctx context.Context, accessToken, refreshToken string, allSessions bool) error
+	QueueSignOut(accessToken, refreshToken string, allSessions bool) error
+	RevokePendingSignOuts(ctx context.Context)
+
+	Deploy(ctx context.Context, project string, spaConfigData []byte, archiveData []byte, commitSHA string, commitBranch string, commitMessage string, commitURL string, clearCache bool) (*DeployResponse, error)
+	ListProjects(ctx context.Context) ([]Project, error)
+	FindProject(ctx context.Context, nameOrID string) (*Project, error)
+	GetProjectStatus(ctx context.Context, projectID string) (*ProjectStatus, error)
+	ListDeployments(ctx context.Context, projectID string, opts *DeploymentListOptions) (*DeploymentList, error)
+	GetProjectLogs(ctx context.Context, projectID string, opts *LogOptions) (*LogList, error)
+	GetBuildLogs(ctx context.Context, projectID, buildID string, opts *LogOptions) (*LogList, error)
+	FollowProjectLogs(ctx context.Context, projectID string, opts *LogOptions, handle LogHandler) error
+	FollowBuildLogs(ctx context.Context, projectID, buildID string, opts *LogOptions, handle LogHandler) error
+
+	ListTokens(ctx context.Context) ([]APIToken, error)
+	CreateToken(ctx context.Context, name string, expiresAt *time.Time) (*CreateTokenResponse, error)
+	RevokeToken(ctx context.Context, id string) error
+}
+
+var _ API = (*Client)(nil)
+
+// APIURL returns the base URL requests are sent to
+func (c *Client) APIURL() string {
+	return c.BaseURL
+}
+
+// SetAPIURL sends later requests to baseURL
+func (c *Client) SetAPIURL(baseURL string) {
+	c.BaseURL = baseURL
+}
+
+// HasAPIToken reports whether requests authenticate with an API token
+func (c *Client) HasAPIToken() bool {
+	return c.Token != ""
+}
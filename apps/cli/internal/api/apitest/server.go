@@ -0,0 +1,24 @@
+// Package apitest provides an in-memory GoDeploy API for tests, so commands
+// and clients can be tested end to end without a network. The API itself is
+// fakeapi.Server; this package starts it on a test server.
+package apitest
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/silvabyte/godeploy/internal/fakeapi"
+)
+
+// Server is an in-memory GoDeploy API; see fakeapi.Server
+type Server = fakeapi.Server
+
+// NewServer starts a server that is closed when the test ends
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+	s := fakeapi.New()
+	httpServer := httptest.NewServer(s)
+	s.URL = httpServer.URL
+	t.Cleanup(httpServer.Close)
+	return s
+}
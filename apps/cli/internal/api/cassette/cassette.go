@@ -0,0 +1,377 @@
+// Package cassette records the HTTP exchanges of API clients in tests and
+// replays them, so client tests run offline against real API responses.
+//
+// Tests replay by default. A request that isn't in the cassette fails the
+// test, as does a recorded exchange the test no longer makes; a test whose
+// cassette wasn't recorded yet is skipped. Cassettes are recorded against
+// a real API, such as staging, never against apitest, whose responses are
+// only what the CLI expects. To record, run the tests with GODEPLOY_RECORD=1:
+//
+//	GODEPLOY_RECORD=1 GODEPLOY_API_URL=https://api.staging.example \
+//	GODEPLOY_TOKEN=... go test ./internal/api -run Cassette
+//
+// Secrets are redacted before anything is written. Headers other than the
+// content type aren't recorded, so credentials never are, and secret JSON
+// fields such as passwords and tokens are replaced with Redacted in request
+// and response bodies. Requests are matched after redaction.
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const (
+	// RecordEnvVar turns on recording when set to 1
+	RecordEnvVar = "GODEPLOY_RECORD"
+	// Redacted replaces secret values in cassettes
+	Redacted = "[REDACTED]"
+	// ReplayURL is the base URL of the API while replaying; requests never
+	// leave the process
+	ReplayURL = "https://api.godeploy.test"
+)
+
+// secretFields are JSON fields whose values are never written
+var secretFields = map[string]bool{
+	"password":        true,
+	"currentPassword": true,
+	"newPassword":     true,
+	"token":           true,
+	"access_token":    true,
+	"refresh_token":   true,
+	"email":           true,
+}
+
+// Cassette is the recorded exchanges of one test, in order
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is one request and the response it got
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a recorded request. URL is the path and query, so a cassette
+// replays against any base URL.
+type Request struct {
+	Method      string `json:"method"`
+	URL         string `json:"url"`
+	ContentType string `json:"content_type,omitempty"`
+	// Body is the redacted JSON body; other bodies, such as deploy
+	// uploads, aren't recorded or matched
+	Body json.RawMessage `json:"body,omitempty"`
+}
+
+// Response is a recorded response
+type Response struct {
+	StatusCode  int             `json:"status_code"`
+	ContentType string          `json:"content_type,omitempty"`
+	Body        json.RawMessage `json:"body,omitempty"`
+	// Text is a body that isn't JSON
+	Text string `json:"text,omitempty"`
+}
+
+// Recorder is an http.RoundTripper that records exchanges to a cassette or
+// replays them from it
+type Recorder struct {
+	t         testing.TB
+	path      string
+	recording bool
+	baseURL   string
+	transport http.RoundTripper
+
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+}
+
+// New returns a recorder for the cassette testdata/cassettes/<name>.json.
+// When recording, requests go to GODEPLOY_API_URL and the cassette is
+// written when the test ends; otherwise the test is skipped when the
+// cassette doesn't exist.
+func New(t testing.TB, name string) *Recorder {
+	t.Helper()
+	r := &Recorder{
+		t:         t,
+		path:      filepath.Join("testdata", "cassettes", name+".json"),
+		recording: os.Getenv(RecordEnvVar) == "1",
+		baseURL:   ReplayURL,
+		transport: http.DefaultTransport,
+	}
+
+	if r.recording {
+		r.baseURL = strings.TrimSuffix(os.Getenv("GODEPLOY_API_URL"), "/")
+		if r.baseURL == "" {
+			t.Fatalf("cassette: set GODEPLOY_API_URL to the API to record %s from", name)
+		}
+		t.Cleanup(r.save)
+		return r
+	}
+
+	data, err := os.ReadFile(r.path)
+	if errors.Is(err, os.ErrNotExist) {
+		t.Skipf("cassette: %s isn't recorded; record it against a real API with %s=1", r.path, RecordEnvVar)
+	}
+	if err != nil {
+		t.Fatalf("cassette: %v", err)
+	}
+	if err := json.Unmarshal(data, &r.cassette); err != nil {
+		t.Fatalf("cassette: invalid %s: %v", r.path, err)
+	}
+	r.used = make([]bool, len(r.cassette.Interactions))
+	t.Cleanup(r.checkUsed)
+	return r
+}
+
+// Recording reports whether the recorder talks to a real API
+func (r *Recorder) Recording() bool {
+	return r.recording
+}
+
+// BaseURL returns the base URL clients should send requests to
+func (r *Recorder) BaseURL() string {
+	return r.baseURL
+}
+
+// Env returns the value of the environment variable name when recording
+// and def when replaying, for credentials only a recording needs
+func (r *Recorder) Env(name, def string) string {
+	if !r.recording {
+		return def
+	}
+	value := os.Getenv(name)
+	if value == "" {
+		r.t.Fatalf("cassette: set %s to record", name)
+	}
+	return value
+}
+
+// HTTPClient returns a client that sends requests through the recorder
+func (r *Recorder) HTTPClient() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// RoundTrip records or replays one exchange
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorded, err := newRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if r.recording {
+		return r.record(req, recorded)
+	}
+	return r.replay(req, recorded)
+}
+
+func (r *Recorder) record(req *http.Request, recorded Request) (*http.Response, error) {
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	response := Response{
+		StatusCode:  resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+	}
+	if isJSON(response.ContentType) && json.Valid(body) {
+		response.Body = redact(body)
+	} else {
+		response.Text = string(body)
+	}
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{Request: recorded, Response: response})
+	r.mu.Unlock()
+	return resp, nil
+}
+
+func (r *Recorder) replay(req *http.Request, recorded Request) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, interaction := range r.cassette.Interactions {
+		if r.used[i] || !interaction.Request.matches(recorded) {
+			continue
+		}
+		r.used[i] = true
+
+		response := interaction.Response
+		body := response.Text
+		if len(response.Body) > 0 {
+			body = string(response.Body)
+		}
+		header := http.Header{}
+		if response.ContentType != "" {
+			header.Set("Content-Type", response.ContentType)
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", response.StatusCode, http.StatusText(response.StatusCode)),
+			StatusCode:    response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(strings.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	}
+
+	r.t.Errorf("cassette: %s %s isn't in %s; record it again with %s=1", recorded.Method, recorded.URL, r.path, RecordEnvVar)
+	return nil, fmt.Errorf("cassette: no recorded response for %s %s", recorded.Method, recorded.URL)
+}
+
+// checkUsed fails the test for recorded exchanges it didn't make
+func (r *Recorder) checkUsed() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, used := range r.used {
+		if !used {
+			request := r.cassette.Interactions[i].Request
+			r.t.Errorf("cassette: recorded %s %s wasn't requested", request.Method, request.URL)
+		}
+	}
+}
+
+// save writes the cassette of a recording
+func (r *Recorder) save() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.t.Failed() {
+		r.t.Logf("cassette: not saving %s of a failed test", r.path)
+		return
+	}
+
+	data, err := marshalIndent(r.cassette)
+	if err != nil {
+		r.t.Errorf("cassette: %v", err)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		r.t.Errorf("cassette: %v", err)
+		return
+	}
+	if err := os.WriteFile(r.path, data, 0o644); err != nil {
+		r.t.Errorf("cassette: %v", err)
+	}
+}
+
+// newRequest returns the recorded form of req, rewinding its body
+func newRequest(req *http.Request) (Request, error) {
+	recorded := Request{
+		Method:      req.Method,
+		URL:         req.URL.EscapedPath(),
+		ContentType: mediaType(req.Header.Get("Content-Type")),
+	}
+	if req.URL.RawQuery != "" {
+		// Encode sorts the parameters; their order doesn't matter to the API
+		recorded.URL += "?" + req.URL.Query().Encode()
+	}
+	if req.Body == nil || req.Body == http.NoBody {
+		return recorded, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return recorded, fmt.Errorf("cassette: failed to read request body: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	if isJSON(recorded.ContentType) && json.Valid(body) {
+		recorded.Body = redact(body)
+	}
+	return recorded, nil
+}
+
+// matches reports whether a request is the recorded one. Bodies are
+// compared compacted, as the cassette file indents them deeper.
+func (r Request) matches(other Request) bool {
+	return r.Method == other.Method && r.URL == other.URL &&
+		r.ContentType == other.ContentType && bytes.Equal(compact(r.Body), compact(other.Body))
+}
+
+// redact returns a JSON document with secret fields replaced, indented
+// for readable diffs
+func redact(data []byte) json.RawMessage {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return data
+	}
+	v = redactValue(v)
+	out, err := marshalIndent(v)
+	if err != nil {
+		return data
+	}
+	return bytes.TrimSuffix(out, []byte("\n"))
+}
+
+// marshalIndent is json.MarshalIndent without escaping &, < and >, which
+// are common in URLs and messages
+func marshalIndent(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func redactValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if secretFields[key] && value != nil && value != "" {
+				v[key] = Redacted
+			} else {
+				v[key] = redactValue(value)
+			}
+		}
+		return v
+	case []any:
+		for i := range v {
+			v[i] = redactValue(v[i])
+		}
+		return v
+	}
+	return v
+}
+
+func compact(data []byte) []byte {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		return data
+	}
+	return buf.Bytes()
+}
+
+func mediaType(contentType string) string {
+	media, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return contentType
+	}
+	return media
+}
+
+func isJSON(contentType string) bool {
+	return mediaType(contentType) == "application/json"
+}
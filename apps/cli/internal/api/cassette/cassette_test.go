@@ -0,0 +1,191 @@
+package cassette
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeTB records what a recorder reports, so failing the test it belongs to
+// can be checked without failing this one
+type fakeTB struct {
+	testing.TB
+	errors   []string
+	cleanups []func()
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Errorf(format string, args ...any) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeTB) Fatalf(format string, args ...any) {
+	panic(fmt.Sprintf(format, args...))
+}
+
+func (f *fakeTB) Logf(string, ...any) {}
+
+func (f *fakeTB) Failed() bool {
+	return len(f.errors) > 0
+}
+
+func (f *fakeTB) Cleanup(fn func()) {
+	f.cleanups = append(f.cleanups, fn)
+}
+
+// finish runs the cleanups, as the end of the test would
+func (f *fakeTB) finish() {
+	for i := len(f.cleanups) - 1; i >= 0; i-- {
+		f.cleanups[i]()
+	}
+}
+
+// inTempDir runs the test in an empty directory, where recorders read and
+// write testdata/cassettes
+func inTempDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	origDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = os.Chdir(origDir)
+	})
+	return dir
+}
+
+// send makes a request through the recorder and returns the response body
+func send(t *testing.T, rec *Recorder, method, path, body string) (*http.Response, string, error) {
+	t.Helper()
+	req, err := http.NewRequest(method, rec.BaseURL()+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := rec.HTTPClient().Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, string(data), nil
+}
+
+// TestRecordRedactsSecrets tests that secrets are redacted from recorded
+// requests and responses while other fields, including error codes, are
+// kept
+func TestRecordRedactsSecrets(t *testing.T) {
+	dir := inTempDir(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		_, _ = w.Write([]byte(`{"token":"access-secret","refresh_token":"refresh-secret","code":"quota_exceeded","user":{"id":"u1","email":"dev@example.com"}}`))
+	}))
+	defer server.Close()
+	t.Setenv(RecordEnvVar, "1")
+	t.Setenv("GODEPLOY_API_URL", server.URL)
+
+	tb := &fakeTB{}
+	rec := New(tb, "signin")
+	_, body, err := send(t, rec, http.MethodPost, "/api/auth/signin", `{"email":"dev@example.com","password":"hunter22","remember":true}`)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if !strings.Contains(body, "access-secret") {
+		t.Fatalf("Expected the client to get the real response, got %s", body)
+	}
+	tb.finish()
+	if len(tb.errors) > 0 {
+		t.Fatalf("Recording failed: %v", tb.errors)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "testdata", "cassettes", "signin.json"))
+	if err != nil {
+		t.Fatalf("Cassette not written: %v", err)
+	}
+	saved := string(data)
+	for _, secret := range []string{"dev@example.com", "hunter22", "access-secret", "refresh-secret"} {
+		if strings.Contains(saved, secret) {
+			t.Errorf("Cassette contains %q:\n%s", secret, saved)
+		}
+	}
+	for _, kept := range []string{`"remember": true`, `"code": "quota_exceeded"`, `"id": "u1"`, Redacted} {
+		if !strings.Contains(saved, kept) {
+			t.Errorf("Cassette is missing %s:\n%s", kept, saved)
+		}
+	}
+}
+
+// writeCassette writes a cassette with one sign-in exchange
+func writeCassette(t *testing.T, name string) {
+	t.Helper()
+	cassette := `{"interactions":[{
+		"request":{"method":"POST","url":"/api/auth/signin","content_type":"application/json","body":{"email":"[REDACTED]","password":"[REDACTED]"}},
+		"response":{"status_code":200,"content_type":"application/json","body":{"success":true}}
+	}]}`
+	path := filepath.Join("testdata", "cassettes", name+".json")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(cassette), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestReplayMatchesRedactedRequest tests that a request matches its
+// recording whatever its secrets are
+func TestReplayMatchesRedactedRequest(t *testing.T) {
+	inTempDir(t)
+	writeCassette(t, "signin")
+
+	tb := &fakeTB{}
+	rec := New(tb, "signin")
+	resp, body, err := send(t, rec, http.MethodPost, "/api/auth/signin", `{"email":"other@example.com","password":"another"}`)
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || !strings.Contains(body, `"success":true`) {
+		t.Fatalf("Unexpected replay: %d %s", resp.StatusCode, body)
+	}
+	tb.finish()
+	if len(tb.errors) > 0 {
+		t.Fatalf("Expected the replay to pass, got %v", tb.errors)
+	}
+}
+
+// TestReplayUnrecordedRequest tests that a request missing from the
+// cassette fails the test, as does the recorded one it didn't make
+func TestReplayUnrecordedRequest(t *testing.T) {
+	inTempDir(t)
+	writeCassette(t, "signin")
+
+	tb := &fakeTB{}
+	rec := New(tb, "signin")
+	if _, _, err := send(t, rec, http.MethodPost, "/api/auth/signup", `{"email":"dev@example.com","password":"hunter22"}`); err == nil {
+		t.Fatal("Expected an unrecorded request to fail")
+	}
+	if len(tb.errors) != 1 || !strings.Contains(tb.errors[0], "POST /api/auth/signup isn't in") {
+		t.Fatalf("Expected the unrecorded request to fail the test, got %v", tb.errors)
+	}
+
+	tb.finish()
+	if len(tb.errors) != 2 || !strings.Contains(tb.errors[1], "POST /api/auth/signin wasn't requested") {
+		t.Fatalf("Expected the unused recording to fail the test, got %v", tb.errors)
+	}
+}
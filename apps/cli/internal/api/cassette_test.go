@@ -0,0 +1,209 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/silvabyte/godeploy/internal/api/cassette"
+	"github.com/silvabyte/godeploy/internal/auth"
+	"github.com/silvabyte/godeploy/pkg/godeploy"
+)
+
+// Credentials a recording authenticates with; replays don't need them
+const (
+	recordEmailEnvVar    = "GODEPLOY_TEST_EMAIL"
+	recordPasswordEnvVar = "GODEPLOY_TEST_PASSWORD"
+)
+
+// newCassetteClient returns a client that records to or replays the named
+// cassette. With withToken it authenticates with an API token.
+func newCassetteClient(t *testing.T, name string, withToken bool) (*Client, *cassette.Recorder) {
+	t.Helper()
+	useTempAuthConfig(t)
+	rec := cassette.New(t, name)
+
+	client := NewClient()
+	client.BaseURL = rec.BaseURL()
+	client.HTTPClient = rec.HTTPClient()
+	client.Retry = NoRetry
+	client.Token = ""
+	if withToken {
+		client.Token = rec.Env(auth.TokenEnvVar, "gdp_replay")
+	}
+	return client, rec
+}
+
+// TestCassetteSignIn tests the sign-in, verify and refresh exchanges
+func TestCassetteSignIn(t *testing.T) {
+	client, rec := newCassetteClient(t, "signin", false)
+	ctx := context.Background()
+
+	email := rec.Env(recordEmailEnvVar, "dev@example.com")
+	signIn, err := client.SignIn(ctx, email, rec.Env(recordPasswordEnvVar, "password"))
+	if err != nil {
+		t.Fatalf("SignIn failed: %v", err)
+	}
+	if !signIn.Success || signIn.Token == "" || signIn.RefreshToken == "" || signIn.User.ID == "" {
+		t.Fatalf("Unexpected sign-in response: %+v", signIn)
+	}
+
+	verify, err := client.VerifyToken(ctx, signIn.Token)
+	if err != nil {
+		t.Fatalf("VerifyToken failed: %v", err)
+	}
+	if !verify.Valid || verify.User.ID != signIn.User.ID {
+		t.Fatalf("Unexpected verify response: %+v", verify)
+	}
+
+	refreshed, err := client.RefreshToken(ctx, signIn.RefreshToken)
+	if err != nil {
+		t.Fatalf("RefreshToken failed: %v", err)
+	}
+	if !refreshed.Success || refreshed.Token == "" {
+		t.Fatalf("Unexpected refresh response: %+v", refreshed)
+	}
+}
+
+// TestCassetteWrongPassword tests how a rejected sign-in is reported
+func TestCassetteWrongPassword(t *testing.T) {
+	client, rec := newCassetteClient(t, "signin_wrong_password", false)
+
+	_, err := client.SignIn(context.Background(), rec.Env(recordEmailEnvVar, "dev@example.com"), "not-the-password")
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("Expected ErrUnauthorized, got %v", err)
+	}
+}
+
+// TestCassetteProjects tests listing and finding projects
+func TestCassetteProjects(t *testing.T) {
+	client, _ := newCassetteClient(t, "projects", true)
+	ctx := context.Background()
+
+	projects, err := client.ListProjects(ctx)
+	if err != nil {
+		t.Fatalf("ListProjects failed: %v", err)
+	}
+	if len(projects) == 0 {
+		t.Fatal("Expected the account to have projects")
+	}
+	for _, p := range projects {
+		if p.ID == "" || p.Name == "" || p.URL == "" {
+			t.Errorf("Incomplete project: %+v", p)
+		}
+	}
+
+	found, err := client.FindProject(ctx, projects[0].Name)
+	if err != nil {
+		t.Fatalf("FindProject failed: %v", err)
+	}
+	if found.ID != projects[0].ID {
+		t.Fatalf("FindProject returned %s, want %s", found.ID, projects[0].ID)
+	}
+}
+
+// TestCassetteTokens tests creating, listing and revoking an API token
+func TestCassetteTokens(t *testing.T) {
+	client, _ := newCassetteClient(t, "tokens", true)
+	ctx := context.Background()
+
+	created, err := client.CreateToken(ctx, "cassette", nil)
+	if err != nil {
+		t.Fatalf("CreateToken failed: %v", err)
+	}
+	if created.ID == "" || created.Token == "" {
+		t.Fatalf("Unexpected created token: %+v", created)
+	}
+
+	tokens, err := client.ListTokens(ctx)
+	if err != nil {
+		t.Fatalf("ListTokens failed: %v", err)
+	}
+	listed := false
+	for _, token := range tokens {
+		listed = listed || token.ID == created.ID
+	}
+	if !listed {
+		t.Fatalf("Created token %s not listed in %+v", created.ID, tokens)
+	}
+
+	if err := client.RevokeToken(ctx, created.ID); err != nil {
+		t.Fatalf("RevokeToken failed: %v", err)
+	}
+}
+
+// TestCassetteDeploy tests uploading a deployment
+func TestCassetteDeploy(t *testing.T) {
+	client, _ := newCassetteClient(t, "deploy", true)
+
+	archive, err := os.ReadFile(filepath.Join("testdata", "site.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := []byte(`{"apps":[{"name":"cassette-site","source_dir":"dist","enabled":true}]}`)
+
+	resp, err := client.Deploy(context.Background(), "cassette-site", config, archive,
+		"0123456789abcdef0123456789abcdef01234567", "main", "Record the deploy cassette", "", false)
+	if err != nil {
+		t.Fatalf("Deploy failed: %v", err)
+	}
+	if !resp.Success || !strings.HasPrefix(resp.URL, "https://") {
+		t.Fatalf("Unexpected deploy response: %+v", resp)
+	}
+}
+
+// responseTypes are the types successful responses decode into, by the
+// method and path of the request
+var responseTypes = map[string]func() any{
+	"POST /api/auth/signin":  func() any { return &SignInResponse{} },
+	"GET /api/auth/verify":   func() any { return &VerifyResponse{} },
+	"POST /api/auth/refresh": func() any { return &RefreshResponse{} },
+	"GET /api/projects":      func() any { return &[]Project{} },
+	"GET /api/tokens":        func() any { return &[]APIToken{} },
+	"POST /api/tokens":       func() any { return &CreateTokenResponse{} },
+	"POST /api/deploy":       func() any { return &godeploy.Deployment{} },
+}
+
+// TestCassetteResponseShapes tests that every field of a recorded response
+// has a place in the struct it decodes into, so fields the API adds or
+// renames show up here instead of being dropped silently
+func TestCassetteResponseShapes(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "cassettes", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Skip("No cassettes recorded")
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var recorded cassette.Cassette
+		if err := json.Unmarshal(data, &recorded); err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+
+		for _, interaction := range recorded.Interactions {
+			route := interaction.Request.Method + " " + strings.SplitN(interaction.Request.URL, "?", 2)[0]
+			newValue, ok := responseTypes[route]
+			status := interaction.Response.StatusCode
+			if !ok || status < 200 || status > 299 {
+				continue
+			}
+
+			decoder := json.NewDecoder(bytes.NewReader(interaction.Response.Body))
+			decoder.DisallowUnknownFields()
+			if err := decoder.Decode(newValue()); err != nil {
+				t.Errorf("%s: response of %s doesn't match its struct: %v", file, route, err)
+			}
+		}
+	}
+}
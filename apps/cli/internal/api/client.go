@@ -1,51 +1,76 @@
 package api
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"io"
-	"mime/multipart"
+	"context"
+	"errors"
 	"net/http"
-	"net/url"
-	"os"
+	"sync/atomic"
 	"time"
 
 	"github.com/silvabyte/godeploy/internal/auth"
+	"github.com/silvabyte/godeploy/internal/settings"
+	"github.com/silvabyte/godeploy/internal/version"
+	"github.com/silvabyte/godeploy/pkg/godeploy"
 )
 
 const (
 	// DefaultAPIBaseURL is the default base URL for the API
-	DefaultAPIBaseURL = "https://api.godeploy.app"
+	DefaultAPIBaseURL = settings.DefaultAPIURL
 
 	// DefaultTimeout is the default timeout for API requests
-	DefaultTimeout = 30 * time.Second
+	DefaultTimeout = godeploy.DefaultTimeout
 	// DefaultAuthTimeout is the timeout for auth operations (login, refresh)
 	// Shorter timeout for better UX when backend is unreachable
 	DefaultAuthTimeout = 10 * time.Second
-	// DefaultDeployTimeout is the default timeout for deploy requests
-	DefaultDeployTimeout = 10 * time.Minute
+	// DefaultDeployTimeout is the default timeout for deploy requests; see
+	// the deploy-timeout setting
+	DefaultDeployTimeout = godeploy.DefaultDeployTimeout
 )
 
 // Client represents an API client
 type Client struct {
-	BaseURL      string
-	HTTPClient   *http.Client
+	BaseURL    string
+	HTTPClient *http.Client
+	// Token is a long-lived API token. When set it is used for every
+	// authenticated request instead of the saved login, and never refreshed.
+	Token string
+	// Retry is the retry policy for requests; see WithRetry
+	Retry        RetryPolicy
 	tokenManager *auth.TokenManager
+	// pendingChecked is set once queued sign-outs were retried. Copies of
+	// the client share it, and requests may finish concurrently.
+	pendingChecked *atomic.Bool
 }
 
-// NewClient creates a new API client
+// NewClient creates a new API client. The API URL comes from
+// GODEPLOY_API_URL or the active profile, and the transport from
+// TransportConfigFromEnv.
 func NewClient() *Client {
+	baseURL, _ := ResolveAPIURL()
 	client := &Client{
-		BaseURL: DefaultAPIBaseURL,
+		BaseURL: baseURL,
 		HTTPClient: &http.Client{
 			Timeout: DefaultTimeout,
 		},
+		Token:          auth.GetAPIToken(),
+		Retry:          DefaultRetryPolicy,
+		pendingChecked: &atomic.Bool{},
 	}
 
-	// Initialize token manager with a refresh function that calls this client
+	// A bad CA bundle or client certificate fails the first request rather
+	// than every command that creates a client
+	transport, err := NewTransport(TransportConfigFromEnv())
+	if err != nil {
+		client.HTTPClient.Transport = failingTransport{err: err}
+	} else {
+		client.HTTPClient.Transport = transport
+	}
+
+	// Initialize token manager with a refresh function that calls this client.
+	// A refresh isn't cancelled with the command: the server rotates the
+	// refresh token, and dropping the response would log the user out.
 	client.tokenManager = auth.NewTokenManager(func(refreshToken string) (string, string, error) {
-		resp, err := client.RefreshToken(refreshToken)
+		resp, err := client.RefreshToken(context.Background(), refreshToken)
 		if err != nil {
 			return "", "", err
 		}
@@ -55,82 +80,18 @@ func NewClient() *Client {
 	return client
 }
 
-// AuthInitRequest represents a request to initialize the authentication flow
-type AuthInitRequest struct {
-	Email       string `json:"email"`
-	RedirectURI string `json:"redirect_uri"`
-}
-
-// AuthInitResponse represents a response from the authentication initialization
-type AuthInitResponse struct {
-	Success bool   `json:"success"`
-	Message string `json:"message"`
-	Error   string `json:"error,omitempty"`
-}
-
-// SignInRequest represents a request to sign in with email and password
-type SignInRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
-}
-
-// SignInResponse represents a response from the signin endpoint
-type SignInResponse struct {
-	Success      bool   `json:"success"`
-	Token        string `json:"token"`
-	RefreshToken string `json:"refresh_token"`
-	User         struct {
-		ID       string `json:"id"`
-		Email    string `json:"email"`
-		TenantID string `json:"tenant_id"`
-	} `json:"user"`
-	Error string `json:"error,omitempty"`
-}
-
-// SignUpRequest represents a request to sign up with email and password
-type SignUpRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
-}
-
-// SignUpResponse represents a response from the signup endpoint
-type SignUpResponse struct {
-	Success      bool   `json:"success"`
-	Token        string `json:"token"`
-	RefreshToken string `json:"refresh_token"`
-	User         struct {
-		ID       string `json:"id"`
-		Email    string `json:"email"`
-		TenantID string `json:"tenant_id"`
-	} `json:"user"`
-	Error string `json:"error,omitempty"`
-}
-
-// VerifyResponse represents a response from the token verification endpoint
-type VerifyResponse struct {
-	Valid bool `json:"valid"`
-	User  struct {
-		ID       string `json:"id"`
-		Email    string `json:"email"`
-		TenantID string `json:"tenant_id"`
-	} `json:"user,omitempty"`
-	Error string `json:"error,omitempty"`
-}
-
-// RefreshResponse represents a response from the refresh endpoint
-type RefreshResponse struct {
-	Success      bool   `json:"success"`
-	Token        string `json:"token"`
-	RefreshToken string `json:"refresh_token"`
-	Error        string `json:"error,omitempty"`
-}
-
-// ErrorResponse represents an error response from the API
-type ErrorResponse struct {
-	Error   string `json:"error"`
-	Message string `json:"message"`
-	Status  int    `json:"status"`
-}
+// Request and response types of the auth endpoints; see the godeploy
+// package
+type (
+	AuthInitRequest  = godeploy.AuthInitRequest
+	AuthInitResponse = godeploy.AuthInitResponse
+	SignInRequest    = godeploy.SignInRequest
+	SignInResponse   = godeploy.SignInResponse
+	SignUpRequest    = godeploy.SignUpRequest
+	SignUpResponse   = godeploy.SignUpResponse
+	VerifyResponse   = godeploy.VerifyResponse
+	RefreshResponse  = godeploy.RefreshResponse
+)
 
 // DeployRequest represents a request to deploy a SPA
 type DeployRequest struct {
@@ -146,499 +107,132 @@ type DeployResponse struct {
 	Error   string `json:"error,omitempty"`
 }
 
-// InitAuth initializes the authentication flow
-func (c *Client) InitAuth(email, redirectURI string) (*AuthInitResponse, error) {
-	// Create the request body
-	reqBody := AuthInitRequest{
-		Email:       email,
-		RedirectURI: redirectURI,
-	}
-
-	// Marshal the request body
-	reqData, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request body: %w", err)
-	}
-
-	// Create the request
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/api/auth/init", c.BaseURL), bytes.NewBuffer(reqData))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set the headers
-	req.Header.Set("Content-Type", "application/json")
-
-	// Send the request
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
-	}
-	defer func() {
-		_ = resp.Body.Close()
-	}()
-
-	// Read the response body
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
-
-	// Check the status code
-	if resp.StatusCode != http.StatusOK {
-		// Try to parse the error response
-		var errResp ErrorResponse
-		if err := json.Unmarshal(body, &errResp); err == nil && errResp.Error != "" {
-			return nil, fmt.Errorf("API error: %s", errResp.Error)
-		}
-		return nil, fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(body))
-	}
-
-	// Decode the response
-	var authResp AuthInitResponse
-	if err := json.Unmarshal(body, &authResp); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	// Check for errors
-	if !authResp.Success {
-		return nil, fmt.Errorf("authentication initialization failed: %s", authResp.Error)
-	}
-
-	return &authResp, nil
+// InitAuth emails a login link to email that leads to redirectURI
+func (c *Client) InitAuth(ctx context.Context, email, redirectURI string) (*AuthInitResponse, error) {
+	return c.authSDK().InitAuth(ctx, email, redirectURI)
 }
 
 // VerifyToken verifies the authentication token with the API
-func (c *Client) VerifyToken(token string) (*VerifyResponse, error) {
-	// Create the request
-	req, err := http.NewRequest("GET", fmt.Sprintf("%s/api/auth/verify", c.BaseURL), nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Add the auth token to the request
-	c.AuthenticatedRequest(req, token)
-
-	// Send the request
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
-	}
-	defer func() {
-		_ = resp.Body.Close()
-	}()
-
-	// Read the response body
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
-
-	// Decode the response
-	var verifyResp VerifyResponse
-	if err := json.Unmarshal(body, &verifyResp); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	// If the status code is 401, the token is invalid
-	if resp.StatusCode == http.StatusUnauthorized {
-		return &verifyResp, nil
-	}
-
-	// Check the status code for other errors
-	if resp.StatusCode != http.StatusOK {
-		// Try to parse the error response
-		var errResp ErrorResponse
-		if err := json.Unmarshal(body, &errResp); err == nil && errResp.Error != "" {
-			return nil, fmt.Errorf("API error: %s", errResp.Error)
-		}
-		return nil, fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(body))
-	}
-
-	return &verifyResp, nil
-}
-
-// AuthenticatedRequest adds authentication headers to a request
-func (c *Client) AuthenticatedRequest(req *http.Request, token string) {
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+func (c *Client) VerifyToken(ctx context.Context, token string) (*VerifyResponse, error) {
+	return c.authSDK().VerifyToken(ctx, token)
 }
 
 // DoAuthenticatedRequest performs an authenticated request with automatic token refresh
 func (c *Client) DoAuthenticatedRequest(req *http.Request) (*http.Response, error) {
-	// Get a valid token (automatically refreshes if expired)
-	token, err := c.tokenManager.EnsureValidToken()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get valid auth token: %w", err)
-	}
-
-	// Add the auth token to the request
-	c.AuthenticatedRequest(req, token)
-
-	// Send the request
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return resp, err
-	}
-
-	// If we get a 401, try to refresh the token and retry once
-	if resp.StatusCode == http.StatusUnauthorized {
-		_ = resp.Body.Close() // Close the first response body
-
-		// Attempt to refresh the token
-		if refreshErr := c.tokenManager.RefreshAccessToken(); refreshErr != nil {
-			// Refresh failed, return the original 401 response
-			return resp, fmt.Errorf("authentication failed and token refresh failed: %w", refreshErr)
-		}
-
-		// Get the new token
-		newToken, err := c.tokenManager.EnsureValidToken()
-		if err != nil {
-			return resp, fmt.Errorf("failed to get token after refresh: %w", err)
-		}
-
-		// Clone the request for retry (we need to create a new request because the body may have been consumed)
-		retryReq, err := cloneRequest(req)
-		if err != nil {
-			return resp, fmt.Errorf("failed to clone request for retry: %w", err)
-		}
-
-		// Add the new token
-		c.AuthenticatedRequest(retryReq, newToken)
-
-		// Retry the request
-		return c.HTTPClient.Do(retryReq)
-	}
-
+	resp, err := c.SDK().Do(req)
+	c.afterRequest(req.Context(), err)
 	return resp, err
 }
 
-// cloneRequest creates a copy of the HTTP request for retry
-func cloneRequest(req *http.Request) (*http.Request, error) {
-	newReq := req.Clone(req.Context())
-	// The Clone method handles copying the body if it's seekable
-	return newReq, nil
-}
-
-// GetAuthToken gets the auth token from the auth package
-func (c *Client) GetAuthToken() (string, error) {
-	return auth.GetAuthToken()
+// SDK returns the public API client configured like c. It authenticates
+// with the API token when one is set, otherwise with the saved login,
+// refreshing it when the API rejects it.
+func (c *Client) SDK() *godeploy.Client {
+	return c.newSDK(c.HTTPClient).With(godeploy.WithTokenSource(c.tokenSource()))
 }
 
-// SignIn authenticates a user with email and password
-func (c *Client) SignIn(email, password string) (*SignInResponse, error) {
-	// Create the request body
-	reqBody := SignInRequest{
-		Email:    email,
-		Password: password,
-	}
-
-	// Marshal the request body
-	reqData, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request body: %w", err)
-	}
-
-	// Create the request
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/api/auth/signin", c.BaseURL), bytes.NewBuffer(reqData))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+// tokenSource returns the API token when one is set, otherwise the saved
+// login
+func (c *Client) tokenSource() godeploy.TokenSource {
+	if c.Token != "" {
+		return godeploy.StaticToken(c.Token)
 	}
+	return savedLogin{c.tokenManager}
+}
 
-	// Set the headers
-	req.Header.Set("Content-Type", "application/json")
-
-	// Use shorter timeout for auth operations
-	authClient := &http.Client{Timeout: DefaultAuthTimeout}
-	resp, err := authClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
-	}
-	defer func() {
-		_ = resp.Body.Close()
-	}()
+// authSDK returns an API client for the auth endpoints, which carry their
+// own credentials. It uses a shorter timeout, for better UX when the backend
+// is unreachable.
+func (c *Client) authSDK() *godeploy.Client {
+	return c.newSDK(c.httpClient(DefaultAuthTimeout)).With(godeploy.WithTokenSource(c.tokenSource()))
+}
 
-	// Read the response body
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+// newSDK returns an unauthenticated API client sending with httpClient
+func (c *Client) newSDK(httpClient *http.Client) *godeploy.Client {
+	// For deploys, use the longer timeout from the deploy-timeout setting
+	deployTimeout := settings.Duration(settings.DeployTimeout)
+	if deployTimeout <= 0 {
+		deployTimeout = DefaultDeployTimeout
 	}
+	return godeploy.New(
+		godeploy.WithBaseURL(c.BaseURL),
+		godeploy.WithHTTPClient(httpClient),
+		godeploy.WithRetry(c.Retry),
+		godeploy.WithDeployTimeout(deployTimeout),
+		godeploy.WithUserAgent("godeploy-cli/"+version.Version),
+	)
+}
 
-	// Check the status code (401 for invalid credentials)
-	if resp.StatusCode == http.StatusUnauthorized {
-		// Try to parse the error response
-		var errResp struct {
-			Success bool   `json:"success"`
-			Error   string `json:"error"`
-		}
-		if err := json.Unmarshal(body, &errResp); err == nil && errResp.Error != "" {
-			return nil, fmt.Errorf("%s", errResp.Error)
-		}
-		return nil, fmt.Errorf("invalid email or password")
+// afterRequest finishes revoking sessions logged out offline once a request
+// got an answer from the API
+func (c *Client) afterRequest(ctx context.Context, err error) {
+	if c.pendingChecked == nil || c.pendingChecked.Load() {
+		return
 	}
-
-	if resp.StatusCode != http.StatusOK {
-		// Try to parse the error response
-		var errResp ErrorResponse
-		if err := json.Unmarshal(body, &errResp); err == nil && errResp.Error != "" {
-			return nil, fmt.Errorf("%s", errResp.Error)
+	var apiErr *Error
+	if err == nil || (errors.As(err, &apiErr) && apiErr.StatusCode != 0) {
+		if c.pendingChecked.CompareAndSwap(false, true) {
+			c.RevokePendingSignOuts(ctx)
 		}
-		return nil, fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(body))
-	}
-
-	// Decode the response
-	var signInResp SignInResponse
-	if err := json.Unmarshal(body, &signInResp); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
-
-	// Check for errors
-	if !signInResp.Success {
-		return nil, fmt.Errorf("authentication failed: %s", signInResp.Error)
-	}
-
-	return &signInResp, nil
 }
 
-// SignUp creates a new account with email and password
-func (c *Client) SignUp(email, password string) (*SignUpResponse, error) {
-	// Create the request body
-	reqBody := SignUpRequest{
-		Email:    email,
-		Password: password,
-	}
-
-	// Marshal the request body
-	reqData, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request body: %w", err)
-	}
-
-	// Create the request
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/api/auth/signup", c.BaseURL), bytes.NewBuffer(reqData))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set the headers
-	req.Header.Set("Content-Type", "application/json")
-
-	// Send the request
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
-	}
-	defer func() {
-		_ = resp.Body.Close()
-	}()
+// savedLogin supplies the access token of the saved login, refreshing it
+// when it expired or was rejected
+type savedLogin struct {
+	manager *auth.TokenManager
+}
 
-	// Read the response body
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
+func (s savedLogin) Token(context.Context) (string, error) {
+	return s.manager.EnsureValidToken()
+}
 
-	// Check the status code (201 for created)
-	if resp.StatusCode != http.StatusCreated {
-		// Try to parse the error response
-		var errResp struct {
-			Success bool   `json:"success"`
-			Error   string `json:"error"`
-		}
-		if err := json.Unmarshal(body, &errResp); err == nil && errResp.Error != "" {
-			return nil, fmt.Errorf("%s", errResp.Error)
-		}
-		return nil, fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(body))
+func (s savedLogin) Refresh(context.Context) (string, error) {
+	if err := s.manager.RefreshAccessToken(); err != nil {
+		return "", err
 	}
+	return s.manager.EnsureValidToken()
+}
 
-	// Decode the response
-	var signUpResp SignUpResponse
-	if err := json.Unmarshal(body, &signUpResp); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+// GetAuthToken returns the API token when one is set, otherwise the saved
+// auth token from the auth package
+func (c *Client) GetAuthToken() (string, error) {
+	if c.Token != "" {
+		return c.Token, nil
 	}
+	return auth.GetAuthToken()
+}
 
-	// Check for errors
-	if !signUpResp.Success {
-		return nil, fmt.Errorf("sign up failed: %s", signUpResp.Error)
-	}
+// SignIn authenticates a user with email and password
+func (c *Client) SignIn(ctx context.Context, email, password string) (*SignInResponse, error) {
+	return c.authSDK().SignIn(ctx, email, password)
+}
 
-	return &signUpResp, nil
+// SignUp creates a new account with email and password
+func (c *Client) SignUp(ctx context.Context, email, password string) (*SignUpResponse, error) {
+	return c.authSDK().SignUp(ctx, email, password)
 }
 
 // RefreshToken exchanges a refresh token for a new access token
-func (c *Client) RefreshToken(refreshToken string) (*RefreshResponse, error) {
-	// Create the request body
-	reqBody := map[string]string{
-		"refresh_token": refreshToken,
-	}
-
-	// Marshal the request body
-	reqData, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request body: %w", err)
-	}
-
-	// Create the request
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/api/auth/refresh", c.BaseURL), bytes.NewBuffer(reqData))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set the headers
-	req.Header.Set("Content-Type", "application/json")
-
-	// Use shorter timeout for auth operations
-	authClient := &http.Client{Timeout: DefaultAuthTimeout}
-	resp, err := authClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
-	}
-	defer func() {
-		_ = resp.Body.Close()
-	}()
-
-	// Read the response body
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
-
-	// Check the status code (401 for invalid/expired refresh token)
-	if resp.StatusCode == http.StatusUnauthorized {
-		return nil, fmt.Errorf("refresh token is invalid or expired, please login again")
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		// Try to parse the error response
-		var errResp ErrorResponse
-		if err := json.Unmarshal(body, &errResp); err == nil && errResp.Error != "" {
-			return nil, fmt.Errorf("%s", errResp.Error)
-		}
-		return nil, fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(body))
-	}
-
-	// Decode the response
-	var refreshResp RefreshResponse
-	if err := json.Unmarshal(body, &refreshResp); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	// Check for errors
-	if !refreshResp.Success {
-		return nil, fmt.Errorf("refresh failed: %s", refreshResp.Error)
-	}
-
-	return &refreshResp, nil
+func (c *Client) RefreshToken(ctx context.Context, refreshToken string) (*RefreshResponse, error) {
+	return c.authSDK().RefreshToken(ctx, refreshToken)
 }
 
 // Deploy deploys a SPA to the GoDeploy service
-func (c *Client) Deploy(project string, spaConfigData []byte, archiveData []byte, commitSHA string, commitBranch string, commitMessage string, commitURL string, clearCache bool) (*DeployResponse, error) {
-	// Create a new multipart writer
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-
-	// Add the project field
-	if err := writer.WriteField("project", project); err != nil {
-		return nil, fmt.Errorf("failed to write project field: %w", err)
-	}
-
-	// Add the spa_config file
-	spaConfigPart, err := writer.CreateFormFile("spa_config", "godeploy.config.json")
-	if err != nil {
-		return nil, fmt.Errorf("failed to create spa_config form file: %w", err)
-	}
-	if _, err := spaConfigPart.Write(spaConfigData); err != nil {
-		return nil, fmt.Errorf("failed to write spa_config data: %w", err)
-	}
-
-	// Add the archive file
-	archivePart, err := writer.CreateFormFile("archive", project+".zip")
-	if err != nil {
-		return nil, fmt.Errorf("failed to create archive form file: %w", err)
-	}
-	if _, err := archivePart.Write(archiveData); err != nil {
-		return nil, fmt.Errorf("failed to write archive data: %w", err)
-	}
-
-	// Close the writer
-	if err := writer.Close(); err != nil {
-		return nil, fmt.Errorf("failed to close multipart writer: %w", err)
-	}
-
-	// Build query parameters including optional commit metadata
-	q := url.Values{}
-	q.Set("project", project)
-	if commitSHA != "" {
-		q.Set("commit_sha", commitSHA)
-	}
-	if commitBranch != "" {
-		q.Set("commit_branch", commitBranch)
-	}
-	if commitMessage != "" {
-		q.Set("commit_message", commitMessage)
-	}
-	if commitURL != "" {
-		q.Set("commit_url", commitURL)
-	}
-	if clearCache {
-		q.Set("clear_cache", "true")
-	}
-
-	// Create the request
-	endpoint := fmt.Sprintf("%s/api/deploy?%s", c.BaseURL, q.Encode())
-	req, err := http.NewRequest("POST", endpoint, body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set the content type
-	req.Header.Set("Content-Type", writer.FormDataContentType())
-
-	// For deploys, use a longer timeout that can be overridden via env var.
-	deployTimeout := DefaultDeployTimeout
-	if v := os.Getenv("GODEPLOY_DEPLOY_TIMEOUT"); v != "" {
-		if d, err := time.ParseDuration(v); err == nil {
-			deployTimeout = d
-		}
-	}
-
-	// Add auth header manually to use a custom HTTP client for this call.
-	token, err := c.GetAuthToken()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get auth token: %w", err)
-	}
-	c.AuthenticatedRequest(req, token)
-
-	httpClient := &http.Client{Timeout: deployTimeout}
-	// Send the request with the extended-timeout client
-	resp, err := httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
-	}
-	defer func() {
-		_ = resp.Body.Close()
-	}()
-
-	// Read the response body
-	respBody, err := io.ReadAll(resp.Body)
+func (c *Client) Deploy(ctx context.Context, project string, spaConfigData []byte, archiveData []byte, commitSHA string, commitBranch string, commitMessage string, commitURL string, clearCache bool) (*DeployResponse, error) {
+	deployment, err := c.SDK().Deploy(ctx, godeploy.DeployRequest{
+		Project:       project,
+		Archive:       archiveData,
+		SpaConfig:     spaConfigData,
+		CommitSHA:     commitSHA,
+		CommitBranch:  commitBranch,
+		CommitMessage: commitMessage,
+		CommitURL:     commitURL,
+		ClearCache:    clearCache,
+	})
+	c.afterRequest(ctx, err)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, err
 	}
-
-	// Check the status code (accept OK/Created/Accepted)
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusAccepted {
-		// Try to parse the error response
-		var errResp ErrorResponse
-		if err := json.Unmarshal(respBody, &errResp); err == nil && errResp.Error != "" {
-			return nil, fmt.Errorf("API error: %s", errResp.Error)
-		}
-		return nil, fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(respBody))
-	}
-
-	// Decode the response
-	var deployResp DeployResponse
-	if err := json.Unmarshal(respBody, &deployResp); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w, body: %s", err, string(respBody))
-	}
-
-	return &deployResp, nil
+	return &DeployResponse{Success: true, URL: deployment.URL}, nil
 }
@@ -0,0 +1,30 @@
+package api
+
+import (
+	"context"
+
+	"github.com/silvabyte/godeploy/pkg/godeploy"
+)
+
+// Deployment statuses
+const (
+	StatusPending = godeploy.StatusPending
+	StatusSuccess = godeploy.StatusSuccess
+	StatusFailed  = godeploy.StatusFailed
+)
+
+// Deployment is one upload of a project's files
+type Deployment = godeploy.Deployment
+
+// DeploymentList is a page of deployments, newest first
+type DeploymentList = godeploy.DeploymentList
+
+// DeploymentListOptions filters and pages ListDeployments
+type DeploymentListOptions = godeploy.DeploymentListOptions
+
+// ListDeployments returns a page of the deployments of a project
+func (c *Client) ListDeployments(ctx context.Context, projectID string, opts *DeploymentListOptions) (*DeploymentList, error) {
+	list, err := c.SDK().ListDeployments(ctx, projectID, opts)
+	c.afterRequest(ctx, err)
+	return list, err
+}
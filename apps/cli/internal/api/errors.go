@@ -0,0 +1,34 @@
+package api
+
+import (
+	"github.com/silvabyte/godeploy/pkg/godeploy"
+)
+
+// Sentinel errors classifying API failures. Every error returned by a Client
+// method for a failed request is an *Error whose Kind is one of these, so
+// callers can use errors.Is instead of matching on the message. They are
+// the errors of the godeploy package, so either package's can be matched.
+var (
+	ErrUnauthorized   = godeploy.ErrUnauthorized
+	ErrForbidden      = godeploy.ErrForbidden
+	ErrNotFound       = godeploy.ErrNotFound
+	ErrConflict       = godeploy.ErrConflict
+	ErrInvalidInput   = godeploy.ErrInvalidInput
+	ErrQuotaExceeded  = godeploy.ErrQuotaExceeded
+	ErrRateLimited    = godeploy.ErrRateLimited
+	ErrTimeout        = godeploy.ErrTimeout
+	ErrUnavailable    = godeploy.ErrUnavailable
+	ErrNotImplemented = godeploy.ErrNotImplemented
+
+	ErrIncorrectPassword = godeploy.ErrIncorrectPassword
+	ErrInvalidResetToken = godeploy.ErrInvalidResetToken
+)
+
+// Error is a failed API request
+type Error = godeploy.Error
+
+// IsUnavailable reports whether err means the API could not be reached or
+// failed on its side, so the same request may succeed later
+func IsUnavailable(err error) bool {
+	return godeploy.IsUnavailable(err)
+}
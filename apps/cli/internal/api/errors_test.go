@@ -0,0 +1,120 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestErrorClassification tests that failed responses map to sentinel errors
+func TestErrorClassification(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		kind      error
+		message   string
+		retryable bool
+	}{
+		{"unauthorized", http.StatusUnauthorized, `{"error":"Invalid token"}`, ErrUnauthorized, "", false},
+		{"not found", http.StatusNotFound, `{"error":"Project not found"}`, ErrNotFound, "Project not found", false},
+		{"quota by status", http.StatusPaymentRequired, `{"error":"Deploy limit reached"}`, ErrQuotaExceeded, "Deploy limit reached", false},
+		{"quota by code", http.StatusTooManyRequests, `{"error":"Monthly limit","code":"quota_exceeded"}`, ErrQuotaExceeded, "Monthly limit", true},
+		{"rate limited", http.StatusTooManyRequests, `{}`, ErrRateLimited, "", true},
+		{"validation", http.StatusBadRequest, `{"statusCode":400,"code":"FST_ERR_VALIDATION","error":"Bad Request","message":"body/name is required"}`, ErrInvalidInput, "body/name is required", false},
+		{"gateway timeout", http.StatusGatewayTimeout, ``, ErrTimeout, "", true},
+		{"server error", http.StatusInternalServerError, `oops`, ErrUnavailable, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Request-Id", "req-42")
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			_, err := newTokenTestClient(server.URL).ListProjects(context.Background())
+			if !errors.Is(err, tt.kind) {
+				t.Fatalf("Expected %v, got %v", tt.kind, err)
+			}
+			var apiErr *Error
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("Expected *Error, got %T", err)
+			}
+			if apiErr.StatusCode != tt.status || apiErr.RequestID != "req-42" || apiErr.Retryable != tt.retryable {
+				t.Fatalf("Unexpected error fields: %+v", apiErr)
+			}
+			if tt.message != "" && apiErr.Message != tt.message {
+				t.Fatalf("Expected message %q, got %q", tt.message, apiErr.Message)
+			}
+		})
+	}
+}
+
+// TestRequestTimeoutIsClassified tests that a client timeout maps to ErrTimeout
+func TestRequestTimeoutIsClassified(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	client := newTokenTestClient(server.URL)
+	client.HTTPClient.Timeout = 20 * time.Millisecond
+
+	_, err := client.ListProjects(context.Background())
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("Expected ErrTimeout, got %v", err)
+	}
+	if !IsUnavailable(err) {
+		t.Fatal("Expected a timeout to be retryable")
+	}
+}
+
+// TestUnreachableAPI tests that a connection failure maps to ErrUnavailable
+func TestUnreachableAPI(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	_, err := newTokenTestClient(server.URL).ListProjects(context.Background())
+	if !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("Expected ErrUnavailable, got %v", err)
+	}
+}
+
+// TestCancelledDeployStopsPromptly tests that cancelling the context aborts
+// an in-flight deploy without retrying it
+func TestCancelledDeployStopsPromptly(t *testing.T) {
+	var attempts atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := newTokenTestClient(server.URL).WithRetry(DefaultRetryPolicy).
+		Deploy(ctx, "web", []byte(`{}`), []byte("zip-bytes"), "", "", "", "", false)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if IsUnavailable(err) {
+		t.Fatal("Expected a cancelled request not to be retryable")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("Deploy took %s to stop", elapsed)
+	}
+	if n := attempts.Load(); n != 1 {
+		t.Fatalf("Expected 1 attempt, got %d", n)
+	}
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"context"
+
+	"github.com/silvabyte/godeploy/pkg/godeploy"
+)
+
+// LogEntry is one line of a deploy or build log
+type LogEntry = godeploy.LogEntry
+
+// LogOptions pages and filters the logs of a project or build
+type LogOptions = godeploy.LogOptions
+
+// LogList is a page of log entries, oldest first
+type LogList = godeploy.LogList
+
+// LogHandler is called with each entry of a followed log
+type LogHandler = godeploy.LogHandler
+
+// GetProjectLogs returns a page of the deploy logs of a project
+func (c *Client) GetProjectLogs(ctx context.Context, projectID string, opts *LogOptions) (*LogList, error) {
+	list, err := c.SDK().GetProjectLogs(ctx, projectID, opts)
+	c.afterRequest(ctx, err)
+	return list, err
+}
+
+// GetBuildLogs returns a page of the logs of a build
+func (c *Client) GetBuildLogs(ctx context.Context, projectID, buildID string, opts *LogOptions) (*LogList, error) {
+	list, err := c.SDK().GetBuildLogs(ctx, projectID, buildID, opts)
+	c.afterRequest(ctx, err)
+	return list, err
+}
+
+// FollowProjectLogs streams the deploy logs of a project to handle until
+// the deployment finishes
+func (c *Client) FollowProjectLogs(ctx context.Context, projectID string, opts *LogOptions, handle LogHandler) error {
+	err := c.SDK().FollowProjectLogs(ctx, projectID, opts, handle)
+	c.afterRequest(ctx, err)
+	return err
+}
+
+// FollowBuildLogs streams the logs of a build to handle until the build
+// finishes
+func (c *Client) FollowBuildLogs(ctx context.Context, projectID, buildID string, opts *LogOptions, handle LogHandler) error {
+	err := c.SDK().FollowBuildLogs(ctx, projectID, buildID, opts, handle)
+	c.afterRequest(ctx, err)
+	return err
+}
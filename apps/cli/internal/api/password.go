@@ -0,0 +1,32 @@
+package api
+
+import (
+	"context"
+
+	"github.com/silvabyte/godeploy/pkg/godeploy"
+)
+
+// Request types of the password endpoints; see the godeploy package
+type (
+	ChangePasswordRequest       = godeploy.ChangePasswordRequest
+	ResetPasswordRequest        = godeploy.ResetPasswordRequest
+	ResetPasswordConfirmRequest = godeploy.ResetPasswordConfirmRequest
+)
+
+// ChangePassword changes the password of the logged-in user. A wrong
+// current password returns an error matching ErrIncorrectPassword.
+func (c *Client) ChangePassword(ctx context.Context, currentPassword, newPassword string) error {
+	return c.authSDK().ChangePassword(ctx, currentPassword, newPassword)
+}
+
+// RequestPasswordReset emails a password reset link to email. redirectURI
+// is where the link leads and may be empty for the default page.
+func (c *Client) RequestPasswordReset(ctx context.Context, email, redirectURI string) error {
+	return c.authSDK().RequestPasswordReset(ctx, email, redirectURI)
+}
+
+// ConfirmPasswordReset sets a new password with the token from the reset
+// email. A rejected token returns an error matching ErrInvalidResetToken.
+func (c *Client) ConfirmPasswordReset(ctx context.Context, token, newPassword string) error {
+	return c.authSDK().ConfirmPasswordReset(ctx, token, newPassword)
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestChangePassword tests the request and the wrong-password mapping
+func TestChangePassword(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Path != "/api/auth/change-password" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer gdp_test_secret" {
+			t.Errorf("Expected token in Authorization header, got %q", got)
+		}
+		var req ChangePasswordRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Invalid body: %v", err)
+		}
+		if req.CurrentPassword != "old-password" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"success":false,"error":"Invalid login credentials"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"success":true,"message":"Password changed successfully"}`))
+	}))
+	defer server.Close()
+
+	client := newTokenTestClient(server.URL)
+	if err := client.ChangePassword(context.Background(), "old-password", "new-password"); err != nil {
+		t.Fatalf("ChangePassword failed: %v", err)
+	}
+
+	err := client.ChangePassword(context.Background(), "wrong", "new-password")
+	if !errors.Is(err, ErrIncorrectPassword) {
+		t.Fatalf("Expected ErrIncorrectPassword, got %v", err)
+	}
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("Expected *Error with status 401, got %#v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("Expected a 401 not to be retried, got %d requests", calls)
+	}
+}
+
+// TestConfirmPasswordReset tests that rejected tokens and validation errors
+// are told apart
+func TestConfirmPasswordReset(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ResetPasswordConfirmRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Invalid body: %v", err)
+		}
+		switch {
+		case len(req.NewPassword) < 8:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"statusCode":400,"code":"FST_ERR_VALIDATION","error":"Bad Request","message":"body/newPassword must NOT have fewer than 8 characters"}`))
+		case req.Token != "reset-token":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"success":false,"error":"Token has expired or is invalid"}`))
+		default:
+			_, _ = w.Write([]byte(`{"success":true,"message":"Password reset successfully"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := newTokenTestClient(server.URL)
+	if err := client.ConfirmPasswordReset(context.Background(), "reset-token", "new-password"); err != nil {
+		t.Fatalf("ConfirmPasswordReset failed: %v", err)
+	}
+	if err := client.ConfirmPasswordReset(context.Background(), "stale", "new-password"); !errors.Is(err, ErrInvalidResetToken) {
+		t.Fatalf("Expected ErrInvalidResetToken, got %v", err)
+	}
+
+	err := client.ConfirmPasswordReset(context.Background(), "reset-token", "short")
+	if errors.Is(err, ErrInvalidResetToken) {
+		t.Fatal("Expected a validation error not to be reported as a bad token")
+	}
+	if err == nil || err.Error() != "body/newPassword must NOT have fewer than 8 characters" {
+		t.Fatalf("Expected the validation message, got %v", err)
+	}
+}
+
+// TestRequestPasswordReset tests the reset email request
+func TestRequestPasswordReset(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ResetPasswordRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Invalid body: %v", err)
+		}
+		if r.URL.Path != "/api/auth/reset-password" || req.Email != "dev@example.com" {
+			t.Errorf("Unexpected request %s %+v", r.URL.Path, req)
+		}
+		_, _ = w.Write([]byte(`{"success":true,"message":"Password reset email sent"}`))
+	}))
+	defer server.Close()
+
+	if err := newTokenTestClient(server.URL).RequestPasswordReset(context.Background(), "dev@example.com", ""); err != nil {
+		t.Fatalf("RequestPasswordReset failed: %v", err)
+	}
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"context"
+
+	"github.com/silvabyte/godeploy/pkg/godeploy"
+)
+
+// Project represents a project returned by the projects endpoints
+type Project = godeploy.Project
+
+// ProjectStatus is the live state of a project
+type ProjectStatus = godeploy.ProjectStatus
+
+// Health is the result of a health check of a project's URL
+type Health = godeploy.Health
+
+// ListProjects returns all projects of the authenticated tenant
+func (c *Client) ListProjects(ctx context.Context) ([]Project, error) {
+	projects, err := c.SDK().ListProjects(ctx)
+	c.afterRequest(ctx, err)
+	return projects, err
+}
+
+// FindProject returns the project whose ID, name or subdomain matches
+// nameOrID. IDs are matched first so a renamed project is still found by ID.
+func (c *Client) FindProject(ctx context.Context, nameOrID string) (*Project, error) {
+	project, err := c.SDK().FindProject(ctx, nameOrID)
+	c.afterRequest(ctx, err)
+	return project, err
+}
+
+// GetProjectStatus returns the active and in-progress deployments, domains
+// and health of a project
+func (c *Client) GetProjectStatus(ctx context.Context, projectID string) (*ProjectStatus, error) {
+	status, err := c.SDK().GetProjectStatus(ctx, projectID)
+	c.afterRequest(ctx, err)
+	return status, err
+}
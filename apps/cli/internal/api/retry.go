@@ -0,0 +1,39 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/silvabyte/godeploy/pkg/godeploy"
+)
+
+// IdempotencyKeyHeader lets the server recognize a retried request, so a
+// retried non-idempotent call like a deploy takes effect only once
+const IdempotencyKeyHeader = godeploy.IdempotencyKeyHeader
+
+// RetryPolicy controls how failed requests are retried; see
+// godeploy.RetryPolicy
+type RetryPolicy = godeploy.RetryPolicy
+
+// DefaultRetryPolicy is used by clients created with NewClient
+var DefaultRetryPolicy = godeploy.DefaultRetryPolicy
+
+// NoRetry sends every request exactly once
+var NoRetry = godeploy.NoRetry
+
+// WithRetry returns a copy of the client that uses policy, for calls that
+// need a different policy than the client's
+func (c *Client) WithRetry(policy RetryPolicy) *Client {
+	clone := *c
+	clone.Retry = policy
+	return &clone
+}
+
+// failingTransport fails every request with err, a bad transport setting
+// that retrying won't fix
+type failingTransport struct {
+	err error
+}
+
+func (t failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, &Error{Message: t.err.Error(), Err: t.err}
+}
@@ -0,0 +1,169 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fastRetry retries quickly so tests don't wait on real backoff
+var fastRetry = RetryPolicy{
+	MaxAttempts: 4,
+	BaseDelay:   time.Millisecond,
+	MaxDelay:    5 * time.Millisecond,
+	Budget:      time.Second,
+}
+
+// flakyServer fails the first failures requests with the given behavior and
+// then succeeds, recording every request body and idempotency key
+type flakyServer struct {
+	mu       sync.Mutex
+	failures int
+	fail     func(w http.ResponseWriter)
+	bodies   []string
+	keys     []string
+}
+
+func (f *flakyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	f.mu.Lock()
+	f.bodies = append(f.bodies, string(body))
+	f.keys = append(f.keys, r.Header.Get(IdempotencyKeyHeader))
+	attempt := len(f.bodies)
+	f.mu.Unlock()
+
+	if attempt <= f.failures {
+		f.fail(w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if r.URL.Path == "/api/projects" {
+		_, _ = w.Write([]byte(`[]`))
+		return
+	}
+	_, _ = w.Write([]byte(`{"success":true,"url":"https://web.godeploy.app"}`))
+}
+
+// badGateway answers with a 502
+func badGateway(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusBadGateway)
+}
+
+// resetConnection drops the connection without a response
+func resetConnection(w http.ResponseWriter) {
+	conn, _, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		return
+	}
+	if tcp, ok := conn.(*net.TCPConn); ok {
+		_ = tcp.SetLinger(0)
+	}
+	_ = conn.Close()
+}
+
+// TestDeployRetriesWithSameIdempotencyKey tests that a deploy survives a 502
+// and a connection reset, resending the full body with one idempotency key
+func TestDeployRetriesWithSameIdempotencyKey(t *testing.T) {
+	for name, fail := range map[string]func(http.ResponseWriter){
+		"bad gateway":      badGateway,
+		"connection reset": resetConnection,
+	} {
+		t.Run(name, func(t *testing.T) {
+			flaky := &flakyServer{failures: 2, fail: fail}
+			server := httptest.NewServer(flaky)
+			defer server.Close()
+
+			client := newTokenTestClient(server.URL).WithRetry(fastRetry)
+			resp, err := client.Deploy(context.Background(), "web", []byte(`{}`), []byte("zip-bytes"), "", "", "", "", false)
+			if err != nil {
+				t.Fatalf("Deploy failed: %v", err)
+			}
+			if resp.URL != "https://web.godeploy.app" {
+				t.Fatalf("Unexpected response: %+v", resp)
+			}
+
+			if len(flaky.bodies) != 3 {
+				t.Fatalf("Expected 3 attempts, got %d", len(flaky.bodies))
+			}
+			for i := range flaky.bodies {
+				if flaky.keys[i] == "" || flaky.keys[i] != flaky.keys[0] {
+					t.Fatalf("Expected one idempotency key for all attempts, got %v", flaky.keys)
+				}
+				if flaky.bodies[i] != flaky.bodies[0] || flaky.bodies[i] == "" {
+					t.Fatalf("Attempt %d sent a different body", i+1)
+				}
+			}
+		})
+	}
+}
+
+// TestPostWithoutIdempotencyKeyIsNotRetried tests that unsafe requests are sent once
+func TestPostWithoutIdempotencyKeyIsNotRetried(t *testing.T) {
+	flaky := &flakyServer{failures: 1, fail: badGateway}
+	server := httptest.NewServer(flaky)
+	defer server.Close()
+
+	client := newTokenTestClient(server.URL).WithRetry(fastRetry)
+	if _, err := client.CreateToken(context.Background(), "ci", nil); err == nil {
+		t.Fatal("Expected the 502 to be returned")
+	}
+	if len(flaky.bodies) != 1 {
+		t.Fatalf("Expected a single attempt, got %d", len(flaky.bodies))
+	}
+}
+
+// TestRetryGivesUpAfterMaxAttempts tests that the last error is returned
+func TestRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	flaky := &flakyServer{failures: 10, fail: badGateway}
+	server := httptest.NewServer(flaky)
+	defer server.Close()
+
+	client := newTokenTestClient(server.URL).WithRetry(fastRetry)
+	_, err := client.ListProjects(context.Background())
+	if !IsUnavailable(err) {
+		t.Fatalf("Expected a retryable error, got %v", err)
+	}
+	if len(flaky.bodies) != fastRetry.MaxAttempts {
+		t.Fatalf("Expected %d attempts, got %d", fastRetry.MaxAttempts, len(flaky.bodies))
+	}
+}
+
+// TestRetryAfterIsHonored tests the Retry-After header and the retry budget
+func TestRetryAfterIsHonored(t *testing.T) {
+	tooMany := func(w http.ResponseWriter) {
+		w.Header().Set("Retry-After", "1")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}
+
+	// Within the budget: wait the requested second, then succeed
+	flaky := &flakyServer{failures: 1, fail: tooMany}
+	server := httptest.NewServer(flaky)
+	defer server.Close()
+
+	policy := fastRetry
+	policy.MaxDelay = 2 * time.Second
+	start := time.Now()
+	if _, err := newTokenTestClient(server.URL).WithRetry(policy).ListProjects(context.Background()); err != nil {
+		t.Fatalf("ListProjects failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Fatalf("Expected to wait for Retry-After, retried after %v", elapsed)
+	}
+
+	// Over the budget: give up immediately with the 429
+	flaky = &flakyServer{failures: 1, fail: tooMany}
+	server2 := httptest.NewServer(flaky)
+	defer server2.Close()
+
+	policy.Budget = 100 * time.Millisecond
+	_, err := newTokenTestClient(server2.URL).WithRetry(policy).ListProjects(context.Background())
+	if err == nil || len(flaky.bodies) != 1 {
+		t.Fatalf("Expected to give up without retrying, got %v after %d attempts", err, len(flaky.bodies))
+	}
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/silvabyte/godeploy/internal/auth"
+	"github.com/silvabyte/godeploy/pkg/godeploy"
+)
+
+// pendingSignOutMaxAge is how long an offline logout keeps being retried
+const pendingSignOutMaxAge = 30 * 24 * time.Hour
+
+// SignOutRequest represents a request to revoke a session
+type SignOutRequest = godeploy.SignOutRequest
+
+// SignOut revokes the session of accessToken and refreshToken on the server.
+// With allSessions every session of the account is revoked. A rejected
+// access token returns an error matching ErrUnauthorized.
+func (c *Client) SignOut(ctx context.Context, accessToken, refreshToken string, allSessions bool) error {
+	return c.authSDK().SignOut(ctx, accessToken, refreshToken, allSessions)
+}
+
+// RevokeSession signs a session out, renewing an expired access token with
+// the refresh token first. A session the server no longer knows is treated
+// as already revoked.
+func (c *Client) RevokeSession(ctx context.Context, accessToken, refreshToken string, allSessions bool) error {
+	err := c.SignOut(ctx, accessToken, refreshToken, allSessions)
+	if !errors.Is(err, ErrUnauthorized) {
+		return err
+	}
+	if refreshToken == "" {
+		return nil
+	}
+
+	// The access token expired; a fresh one is needed to sign out
+	refreshed, err := c.RefreshToken(ctx, refreshToken)
+	if errors.Is(err, ErrUnauthorized) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	err = c.SignOut(ctx, refreshed.Token, refreshed.RefreshToken, allSessions)
+	if errors.Is(err, ErrUnauthorized) {
+		return nil
+	}
+	return err
+}
+
+// QueueSignOut records a session whose revocation failed so it is retried by
+// the next command that reaches the API
+func (c *Client) QueueSignOut(accessToken, refreshToken string, allSessions bool) error {
+	return auth.QueueSignOut(auth.PendingSignOut{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		AllSessions:  allSessions,
+		APIURL:       c.BaseURL,
+		QueuedAt:     time.Now().UTC(),
+	})
+}
+
+// RevokePendingSignOuts retries revocations queued by offline logouts.
+// Ones that still can't reach the server stay queued for up to 30 days;
+// ones the server rejects are dropped, as retrying won't change the answer.
+func (c *Client) RevokePendingSignOuts(ctx context.Context) {
+	pending, err := auth.TakePendingSignOuts()
+	if err != nil || len(pending) == 0 {
+		return
+	}
+
+	var failed []auth.PendingSignOut
+	for _, p := range pending {
+		if time.Since(p.QueuedAt) > pendingSignOutMaxAge {
+			continue
+		}
+		client := *c
+		if p.APIURL != "" {
+			client.BaseURL = p.APIURL
+		}
+		if err := client.RevokeSession(ctx, p.AccessToken, p.RefreshToken, p.AllSessions); IsUnavailable(err) || errors.Is(err, context.Canceled) {
+			failed = append(failed, p)
+		}
+	}
+
+	_ = auth.QueueSignOut(failed...)
+}
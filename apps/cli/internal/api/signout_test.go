@@ -0,0 +1,104 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/silvabyte/godeploy/internal/auth"
+)
+
+// fakeSessions is a server that tracks which sessions were revoked
+type fakeSessions struct {
+	mu      sync.Mutex
+	revoked []SignOutRequest
+	down    bool
+}
+
+func (f *fakeSessions) handler(t *testing.T) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/auth/signout", func(w http.ResponseWriter, r *http.Request) {
+		if f.down {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer fresh-access" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"success":false,"error":"Invalid token"}`))
+			return
+		}
+		var req SignOutRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Invalid body: %v", err)
+		}
+		f.mu.Lock()
+		f.revoked = append(f.revoked, req)
+		f.mu.Unlock()
+		_, _ = w.Write([]byte(`{"success":true,"message":"Signed out successfully"}`))
+	})
+	mux.HandleFunc("/api/auth/refresh", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"success":true,"token":"fresh-access","refresh_token":"fresh-refresh"}`))
+	})
+	return mux
+}
+
+// useTempAuthConfig points the auth config at a fresh directory
+func useTempAuthConfig(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	origGetConfigDir, origGetLegacyConfigDir := auth.GetConfigDir, auth.GetLegacyConfigDir
+	t.Cleanup(func() {
+		auth.GetConfigDir, auth.GetLegacyConfigDir = origGetConfigDir, origGetLegacyConfigDir
+	})
+	auth.GetConfigDir = func() (string, error) { return dir, nil }
+	auth.GetLegacyConfigDir = func() (string, error) { return dir + "/legacy", nil }
+}
+
+// TestRevokeSessionRenewsExpiredToken tests that an expired access token is
+// refreshed so the session can still be revoked
+func TestRevokeSessionRenewsExpiredToken(t *testing.T) {
+	sessions := &fakeSessions{}
+	server := httptest.NewServer(sessions.handler(t))
+	defer server.Close()
+
+	client := &Client{BaseURL: server.URL, HTTPClient: server.Client()}
+	if err := client.RevokeSession(context.Background(), "expired-access", "old-refresh", true); err != nil {
+		t.Fatalf("RevokeSession failed: %v", err)
+	}
+	if len(sessions.revoked) != 1 || sessions.revoked[0].RefreshToken != "fresh-refresh" || sessions.revoked[0].Scope != "global" {
+		t.Fatalf("Unexpected revocations: %+v", sessions.revoked)
+	}
+}
+
+// TestPendingSignOutIsRetried tests that an offline logout is revoked once
+// the API is reachable again
+func TestPendingSignOutIsRetried(t *testing.T) {
+	useTempAuthConfig(t)
+	sessions := &fakeSessions{}
+	server := httptest.NewServer(sessions.handler(t))
+	defer server.Close()
+
+	client := &Client{BaseURL: server.URL, HTTPClient: server.Client()}
+	if err := client.QueueSignOut("fresh-access", "refresh", false); err != nil {
+		t.Fatalf("QueueSignOut failed: %v", err)
+	}
+
+	// Still unavailable: the revocation stays queued
+	sessions.down = true
+	client.RevokePendingSignOuts(context.Background())
+	if queued, _ := auth.HasPendingSignOuts(); !queued {
+		t.Fatal("Expected the sign-out to stay queued while the API is unavailable")
+	}
+
+	sessions.down = false
+	client.RevokePendingSignOuts(context.Background())
+	if queued, _ := auth.HasPendingSignOuts(); queued {
+		t.Fatal("Expected the queue to be empty after revoking")
+	}
+	if len(sessions.revoked) != 1 || sessions.revoked[0].Scope != "local" {
+		t.Fatalf("Unexpected revocations: %+v", sessions.revoked)
+	}
+}
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/silvabyte/godeploy/pkg/godeploy"
+)
+
+// APIToken describes a long-lived API token. The secret itself is only
+// returned once, by CreateToken.
+type APIToken = godeploy.APIToken
+
+// CreateTokenRequest represents a request to create an API token
+type CreateTokenRequest = godeploy.CreateTokenRequest
+
+// CreateTokenResponse represents a response from the create token endpoint
+type CreateTokenResponse = godeploy.CreatedToken
+
+// ListTokens returns the API tokens of the authenticated user
+func (c *Client) ListTokens(ctx context.Context) ([]APIToken, error) {
+	tokens, err := c.SDK().ListTokens(ctx)
+	c.afterRequest(ctx, err)
+	return tokens, err
+}
+
+// CreateToken creates an API token. A nil expiresAt creates a token that
+// does not expire.
+func (c *Client) CreateToken(ctx context.Context, name string, expiresAt *time.Time) (*CreateTokenResponse, error) {
+	created, err := c.SDK().CreateToken(ctx, CreateTokenRequest{Name: name, ExpiresAt: expiresAt})
+	c.afterRequest(ctx, err)
+	return created, err
+}
+
+// RevokeToken revokes the API token with the given ID
+func (c *Client) RevokeToken(ctx context.Context, id string) error {
+	err := c.SDK().RevokeToken(ctx, id)
+	c.afterRequest(ctx, err)
+	return err
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newTokenTestClient returns a client that authenticates with an API token
+func newTokenTestClient(serverURL string) *Client {
+	client := NewClient()
+	client.BaseURL = serverURL
+	client.Token = "gdp_test_secret"
+	// Tests that exercise retries set their own policy
+	client.Retry = NoRetry
+	return client
+}
+
+// TestAPITokenIsSentDirectly tests that an API token bypasses the saved login
+func TestAPITokenIsSentDirectly(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer gdp_test_secret" {
+			t.Errorf("Expected API token in Authorization header, got %q", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"id":"tok_1","name":"ci","created_at":"2025-01-01T00:00:00Z"}]`))
+	}))
+	defer server.Close()
+
+	tokens, err := newTokenTestClient(server.URL).ListTokens(context.Background())
+	if err != nil {
+		t.Fatalf("ListTokens failed: %v", err)
+	}
+	if len(tokens) != 1 || tokens[0].ID != "tok_1" || tokens[0].Name != "ci" {
+		t.Fatalf("Unexpected tokens: %+v", tokens)
+	}
+}
+
+// TestAPITokenRejected tests that a 401 with an API token is not retried
+func TestAPITokenRejected(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	if _, err := newTokenTestClient(server.URL).ListTokens(context.Background()); err == nil {
+		t.Fatal("Expected error for rejected token")
+	}
+	if calls != 1 {
+		t.Fatalf("Expected exactly one request, got %d", calls)
+	}
+}
+
+// TestCreateAndRevokeToken tests the create and revoke token requests
+func TestCreateAndRevokeToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/api/tokens":
+			var req CreateTokenRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Errorf("Failed to decode request: %v", err)
+			}
+			if req.Name != "deploy-bot" || req.ExpiresAt != nil {
+				t.Errorf("Unexpected create request: %+v", req)
+			}
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id":"tok_2","name":"deploy-bot","token":"gdp_new_secret","created_at":"2025-01-01T00:00:00Z"}`))
+		case r.Method == "DELETE" && r.URL.Path == "/api/tokens/tok_2":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := newTokenTestClient(server.URL)
+	created, err := client.CreateToken(context.Background(), "deploy-bot", nil)
+	if err != nil {
+		t.Fatalf("CreateToken failed: %v", err)
+	}
+	if created.Token != "gdp_new_secret" || created.ID != "tok_2" {
+		t.Fatalf("Unexpected create response: %+v", created)
+	}
+
+	if err := client.RevokeToken(context.Background(), "tok_2"); err != nil {
+		t.Fatalf("RevokeToken failed: %v", err)
+	}
+	if err := client.RevokeToken(context.Background(), "missing"); err == nil {
+		t.Fatal("Expected error revoking unknown token")
+	}
+}
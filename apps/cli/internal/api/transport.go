@@ -0,0 +1,120 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/silvabyte/godeploy/internal/auth"
+	"github.com/silvabyte/godeploy/internal/settings"
+)
+
+// Environment variables configuring how the client reaches the API. Proxies
+// are taken from HTTPS_PROXY, HTTP_PROXY and NO_PROXY.
+const (
+	// APIURLEnvVar overrides the API base URL of every profile
+	APIURLEnvVar = "GODEPLOY_API_URL"
+	// CABundleEnvVar names a PEM file of CA certificates trusted in
+	// addition to the system roots, e.g. for a self-hosted API
+	CABundleEnvVar = "GODEPLOY_CA_BUNDLE"
+	// ClientCertEnvVar and ClientKeyEnvVar name the PEM certificate and key
+	// presented for mutual TLS
+	ClientCertEnvVar = "GODEPLOY_CLIENT_CERT"
+	ClientKeyEnvVar  = "GODEPLOY_CLIENT_KEY"
+)
+
+// TransportConfig configures the TLS settings of the client's transport
+type TransportConfig struct {
+	// CABundle is a PEM file of additional trusted CA certificates
+	CABundle string
+	// ClientCert and ClientKey are PEM files for mutual TLS; both or neither
+	// must be set
+	ClientCert string
+	ClientKey  string
+}
+
+// TransportConfigFromEnv reads the transport settings from the environment
+func TransportConfigFromEnv() TransportConfig {
+	return TransportConfig{
+		CABundle:   os.Getenv(CABundleEnvVar),
+		ClientCert: os.Getenv(ClientCertEnvVar),
+		ClientKey:  os.Getenv(ClientKeyEnvVar),
+	}
+}
+
+// NewTransport returns an HTTP transport that uses the proxy from the
+// environment and trusts and presents the certificates in cfg
+func NewTransport(cfg TransportConfig) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	transport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if cfg.CABundle != "" {
+		pem, err := os.ReadFile(cfg.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("CA bundle %s contains no PEM certificates", cfg.CABundle)
+		}
+		transport.TLSClientConfig.RootCAs = pool
+	}
+
+	if cfg.ClientCert != "" || cfg.ClientKey != "" {
+		if cfg.ClientCert == "" || cfg.ClientKey == "" {
+			return nil, fmt.Errorf("mutual TLS needs both %s and %s", ClientCertEnvVar, ClientKeyEnvVar)
+		}
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCert, cfg.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		transport.TLSClientConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return transport, nil
+}
+
+// ResolveAPIURL returns the API base URL and where it came from: the
+// GODEPLOY_API_URL environment variable, the active profile, the api-url
+// setting, or the default
+func ResolveAPIURL() (apiURL, source string) {
+	setting, err := settings.Get(settings.APIURL)
+	if raw := os.Getenv(APIURLEnvVar); err != nil && raw != "" {
+		// Never fall back to the production API because of a typo; the
+		// request fails and shows the URL instead
+		return strings.TrimSuffix(raw, "/"), APIURLEnvVar
+	}
+	if setting.Origin == settings.OriginFlag || setting.Origin == settings.OriginEnv {
+		source = string(setting.Origin)
+		if setting.Origin == settings.OriginEnv {
+			source = APIURLEnvVar
+		}
+		return setting.Value, source
+	}
+	if v, err := auth.GetAPIURL(); err == nil && v != "" {
+		return strings.TrimSuffix(v, "/"), "profile"
+	}
+	if setting.Origin == settings.OriginFile {
+		return setting.Value, "settings"
+	}
+	return DefaultAPIBaseURL, "default"
+}
+
+// httpClient returns a client with the given timeout that shares the
+// transport of c.HTTPClient, for calls needing a different timeout
+func (c *Client) httpClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Transport:     c.HTTPClient.Transport,
+		CheckRedirect: c.HTTPClient.CheckRedirect,
+		Jar:           c.HTTPClient.Jar,
+		Timeout:       timeout,
+	}
+}
@@ -0,0 +1,162 @@
+package api
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// signInHandler answers sign-in requests with a fixed token
+func signInHandler(w http.ResponseWriter, r *http.Request) {
+	_, _ = w.Write([]byte(`{"success":true,"token":"access","refresh_token":"refresh","user":{"email":"dev@example.com"}}`))
+}
+
+// writeServerCA writes the certificate of a TLS test server as a CA bundle
+func writeServerCA(t *testing.T, server *httptest.Server) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// writeClientCert generates a self-signed client certificate and key
+func writeClientCert(t *testing.T) (certPath, keyPath string, cert *x509.Certificate) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "godeploy-cli-test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cert, err = x509.ParseCertificate(der); err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	certPath, keyPath = filepath.Join(dir, "client.pem"), filepath.Join(dir, "client-key.pem")
+	if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certPath, keyPath, cert
+}
+
+// TestCABundleIsUsedBySignIn tests that sign-in, which uses its own timeout,
+// trusts the CA bundle configured for the client
+func TestCABundleIsUsedBySignIn(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(signInHandler))
+	defer server.Close()
+
+	t.Setenv(CABundleEnvVar, "")
+	client := newTokenTestClient(server.URL)
+	if _, err := client.SignIn(context.Background(), "dev@example.com", "password"); err == nil {
+		t.Fatal("Expected an untrusted certificate to fail")
+	}
+
+	t.Setenv(CABundleEnvVar, writeServerCA(t, server))
+	client = newTokenTestClient(server.URL)
+	resp, err := client.SignIn(context.Background(), "dev@example.com", "password")
+	if err != nil {
+		t.Fatalf("SignIn failed: %v", err)
+	}
+	if resp.Token != "access" {
+		t.Fatalf("Unexpected response: %+v", resp)
+	}
+}
+
+// TestClientCertificate tests that the client presents its certificate to a
+// server requiring mutual TLS
+func TestClientCertificate(t *testing.T) {
+	certPath, keyPath, cert := writeClientCert(t)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(cert)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(signInHandler))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+
+	t.Setenv(CABundleEnvVar, writeServerCA(t, server))
+	t.Setenv(ClientCertEnvVar, "")
+	t.Setenv(ClientKeyEnvVar, "")
+	if _, err := newTokenTestClient(server.URL).SignIn(context.Background(), "dev@example.com", "password"); err == nil {
+		t.Fatal("Expected the server to reject a client without a certificate")
+	}
+
+	t.Setenv(ClientCertEnvVar, certPath)
+	t.Setenv(ClientKeyEnvVar, keyPath)
+	if _, err := newTokenTestClient(server.URL).SignIn(context.Background(), "dev@example.com", "password"); err != nil {
+		t.Fatalf("SignIn with a client certificate failed: %v", err)
+	}
+}
+
+// TestInvalidTransportConfig tests that bad TLS settings are reported by
+// requests instead of being ignored
+func TestInvalidTransportConfig(t *testing.T) {
+	tests := map[string]TransportConfig{
+		"missing CA bundle": {CABundle: filepath.Join(t.TempDir(), "missing.pem")},
+		"cert without key":  {ClientCert: "client.pem"},
+	}
+	for name, cfg := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewTransport(cfg); err == nil {
+				t.Fatal("Expected an error")
+			}
+		})
+	}
+
+	t.Setenv(CABundleEnvVar, filepath.Join(t.TempDir(), "missing.pem"))
+	_, err := newTokenTestClient("http://127.0.0.1:1").ListProjects(context.Background())
+	if err == nil || IsUnavailable(err) {
+		t.Fatalf("Expected a configuration error, got %v", err)
+	}
+}
+
+// TestResolveAPIURL tests that GODEPLOY_API_URL overrides the default
+func TestResolveAPIURL(t *testing.T) {
+	useTempAuthConfig(t)
+
+	t.Setenv(APIURLEnvVar, "")
+	if apiURL, source := ResolveAPIURL(); apiURL != DefaultAPIBaseURL || source != "default" {
+		t.Fatalf("Expected the default API URL, got %s (%s)", apiURL, source)
+	}
+
+	t.Setenv(APIURLEnvVar, "http://localhost:8080/")
+	if apiURL, source := ResolveAPIURL(); apiURL != "http://localhost:8080" || source != APIURLEnvVar {
+		t.Fatalf("Expected the URL from %s, got %s (%s)", APIURLEnvVar, apiURL, source)
+	}
+	if client := NewClient(); client.BaseURL != "http://localhost:8080" {
+		t.Fatalf("Expected NewClient to use %s, got %s", APIURLEnvVar, client.BaseURL)
+	}
+}
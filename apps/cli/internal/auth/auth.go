@@ -1,7 +1,6 @@
 package auth
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -10,11 +9,13 @@ import (
 	"github.com/silvabyte/godeploy/internal/paths"
 )
 
-// Config represents the authentication configuration stored on disk
+// Config represents the authentication configuration of one profile
 type Config struct {
 	AuthToken    string `json:"auth_token"`
 	RefreshToken string `json:"refresh_token"`
 	Email        string `json:"email"`
+	// APIURL overrides the API base URL for this profile
+	APIURL string `json:"api_url,omitempty"`
 }
 
 // ConfigDirFunc is a function type for getting the config directory
@@ -105,12 +106,12 @@ func migrateFromLegacyLocation() error {
 		return err
 	}
 
-	if err := os.MkdirAll(xdgConfigDir, 0o755); err != nil {
+	if err := ensureConfigDir(xdgConfigDir); err != nil {
 		return fmt.Errorf("failed to create XDG config directory: %w", err)
 	}
 
-	// Write the config to the XDG location
-	if err := os.WriteFile(xdgConfigPath, data, 0o644); err != nil {
+	// Write the config to the XDG location, readable by the user only
+	if err := writePrivateFile(xdgConfigPath, data); err != nil {
 		return fmt.Errorf("failed to write config to XDG location: %w", err)
 	}
 
@@ -119,66 +120,42 @@ func migrateFromLegacyLocation() error {
 	return nil
 }
 
-// LoadAuthConfig loads the authentication configuration from the config file
+// LoadAuthConfig loads the authentication configuration of the active profile
 // It automatically migrates from legacy locations to XDG-compliant paths
 func LoadAuthConfig() (*Config, error) {
-	// Attempt migration from legacy location if needed
-	if err := migrateFromLegacyLocation(); err != nil {
-		// Log migration error but don't fail - we can still try to load
-		// from the current location
-		fmt.Fprintf(os.Stderr, "Warning: failed to migrate config from legacy location: %v\n", err)
-	}
-
-	configPath, err := GetConfigFilePath()
+	store, err := LoadStore()
 	if err != nil {
 		return nil, err
 	}
 
-	// If the file doesn't exist, return a default config
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+	config, ok := store.Profiles[store.Active()]
+	if !ok {
 		return &Config{}, nil
 	}
-
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read auth config file: %w", err)
-	}
-
-	var config Config
-	if err := json.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse auth config file: %w", err)
-	}
-
-	return &config, nil
+	return config, nil
 }
 
-// SaveAuthConfig saves the authentication configuration to the config file
+// SaveAuthConfig saves the authentication configuration of the active profile
 func SaveAuthConfig(config *Config) error {
-	configDir, err := GetConfigDir()
-	if err != nil {
-		return err
-	}
-
-	// Create the config directory if it doesn't exist
-	if err := os.MkdirAll(configDir, 0o755); err != nil {
-		return fmt.Errorf("failed to create config directory: %w", err)
-	}
-
-	configPath, err := GetConfigFilePath()
-	if err != nil {
-		return err
-	}
-
-	data, err := json.MarshalIndent(config, "", "    ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal auth config: %w", err)
-	}
-
-	if err := os.WriteFile(configPath, data, 0o644); err != nil {
-		return fmt.Errorf("failed to write auth config file: %w", err)
-	}
+	return updateStore(func(store *Store) error {
+		store.Profiles[store.Active()] = config
+		return nil
+	})
+}
 
-	return nil
+// updateAuthConfig applies fn to the active profile's configuration while
+// holding the config lock, so concurrent updates are not lost
+func updateAuthConfig(fn func(config *Config)) error {
+	return updateStore(func(store *Store) error {
+		active := store.Active()
+		config, ok := store.Profiles[active]
+		if !ok {
+			config = &Config{}
+			store.Profiles[active] = config
+		}
+		fn(config)
+		return nil
+	})
 }
 
 // IsAuthenticated checks if the user has an authentication token
@@ -220,33 +197,24 @@ func GetUserEmail() (string, error) {
 
 // SetUserEmail sets the user's email and saves it to the config file
 func SetUserEmail(email string) error {
-	config, err := LoadAuthConfig()
-	if err != nil {
-		return err
-	}
-	config.Email = email
-	return SaveAuthConfig(config)
+	return updateAuthConfig(func(config *Config) {
+		config.Email = email
+	})
 }
 
 // SetAuthToken sets the authentication token and saves it to the config file
 func SetAuthToken(token string) error {
-	config, err := LoadAuthConfig()
-	if err != nil {
-		return err
-	}
-	config.AuthToken = token
-	return SaveAuthConfig(config)
+	return updateAuthConfig(func(config *Config) {
+		config.AuthToken = token
+	})
 }
 
 // ClearAuthToken clears the authentication token and saves the config file
 func ClearAuthToken() error {
-	config, err := LoadAuthConfig()
-	if err != nil {
-		return err
-	}
-	config.AuthToken = ""
-	config.RefreshToken = ""
-	return SaveAuthConfig(config)
+	return updateAuthConfig(func(config *Config) {
+		config.AuthToken = ""
+		config.RefreshToken = ""
+	})
 }
 
 // GetRefreshToken returns the refresh token
@@ -260,11 +228,8 @@ func GetRefreshToken() (string, error) {
 
 // SetTokens sets both access and refresh tokens and saves to config file
 func SetTokens(accessToken, refreshToken string) error {
-	config, err := LoadAuthConfig()
-	if err != nil {
-		return err
-	}
-	config.AuthToken = accessToken
-	config.RefreshToken = refreshToken
-	return SaveAuthConfig(config)
+	return updateAuthConfig(func(config *Config) {
+		config.AuthToken = accessToken
+		config.RefreshToken = refreshToken
+	})
 }
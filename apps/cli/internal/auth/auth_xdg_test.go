@@ -226,26 +226,26 @@ func TestConfigDirectoryPermissions(t *testing.T) {
 		t.Fatalf("Failed to save config: %v", err)
 	}
 
-	// Check directory permissions (should be 0755)
+	// Check directory permissions (should be 0700)
 	configDir := filepath.Join(tempDir, "godeploy")
 	info, err := os.Stat(configDir)
 	if err != nil {
 		t.Fatalf("Failed to stat config directory: %v", err)
 	}
 
-	if info.Mode().Perm() != 0o755 {
-		t.Fatalf("Expected directory permissions 0755, got %o", info.Mode().Perm())
+	if info.Mode().Perm() != 0o700 {
+		t.Fatalf("Expected directory permissions 0700, got %o", info.Mode().Perm())
 	}
 
-	// Check file permissions (should be 0644)
+	// Check file permissions (should be 0600)
 	configPath := filepath.Join(configDir, "config.json")
 	info, err = os.Stat(configPath)
 	if err != nil {
 		t.Fatalf("Failed to stat config file: %v", err)
 	}
 
-	if info.Mode().Perm() != 0o644 {
-		t.Fatalf("Expected file permissions 0644, got %o", info.Mode().Perm())
+	if info.Mode().Perm() != 0o600 {
+		t.Fatalf("Expected file permissions 0600, got %o", info.Mode().Perm())
 	}
 }
 
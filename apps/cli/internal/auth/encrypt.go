@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	// PassphraseEnvVar is the environment variable holding the passphrase
+	// for an encrypted credentials file
+	PassphraseEnvVar = "GODEPLOY_PASSPHRASE"
+
+	// kdfName identifies the key derivation used for encrypted files
+	kdfName = "pbkdf2-sha256"
+	// kdfIterations follows current OWASP guidance for PBKDF2-HMAC-SHA256
+	kdfIterations = 600000
+	saltSize      = 16
+	keySize       = 32
+)
+
+// ErrIncorrectPassphrase is returned when an encrypted file can't be decrypted
+var ErrIncorrectPassphrase = errors.New("incorrect passphrase for encrypted credentials")
+
+// PromptPassphrase asks the user for the passphrase of an encrypted
+// credentials file. The CLI sets it; when nil, only GODEPLOY_PASSPHRASE is used.
+var PromptPassphrase func() (string, error)
+
+// encryptedFile is the on-disk format of an encrypted credentials file
+type encryptedFile struct {
+	Encryption struct {
+		KDF        string `json:"kdf"`
+		Iterations int    `json:"iterations"`
+		Salt       []byte `json:"salt"`
+		Nonce      []byte `json:"nonce"`
+	} `json:"encryption"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// encryptionKey is the key an encrypted store was opened with. It is kept
+// for the life of the process so the passphrase is asked for once and the
+// store can be re-encrypted on save.
+type encryptionKey struct {
+	salt []byte
+	key  []byte
+}
+
+// cachedPassphrase avoids prompting more than once per process
+var cachedPassphrase string
+
+// cachedKey avoids re-deriving the key every time the store is loaded
+var cachedKey struct {
+	passphrase string
+	key        *encryptionKey
+}
+
+// parseEncryptedFile returns the encrypted file in data, or nil when data is
+// a plain credentials file
+func parseEncryptedFile(data []byte) (*encryptedFile, error) {
+	var file encryptedFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse auth config file: %w", err)
+	}
+	if file.Ciphertext == nil {
+		return nil, nil
+	}
+	if file.Encryption.KDF != kdfName {
+		return nil, fmt.Errorf("unsupported credentials encryption '%s'", file.Encryption.KDF)
+	}
+	return &file, nil
+}
+
+// decrypt opens an encrypted file with the passphrase from the environment
+// or the prompt
+func (f *encryptedFile) decrypt() ([]byte, *encryptionKey, error) {
+	passphrase, err := passphrase()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key := cachedKey.key
+	if key == nil || cachedKey.passphrase != passphrase || !bytes.Equal(key.salt, f.Encryption.Salt) {
+		key = &encryptionKey{
+			salt: f.Encryption.Salt,
+			key:  deriveKey(passphrase, f.Encryption.Salt, f.Encryption.Iterations),
+		}
+	}
+	gcm, err := newGCM(key.key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	plaintext, err := gcm.Open(nil, f.Encryption.Nonce, f.Ciphertext, nil)
+	if err != nil {
+		// Don't keep a wrong passphrase around
+		cachedPassphrase = ""
+		return nil, nil, ErrIncorrectPassphrase
+	}
+	cachedKey.passphrase, cachedKey.key = passphrase, key
+	return plaintext, key, nil
+}
+
+// newEncryptionKey derives a key for a newly encrypted file
+func newEncryptionKey(passphrase string) (*encryptionKey, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	return &encryptionKey{salt: salt, key: deriveKey(passphrase, salt, kdfIterations)}, nil
+}
+
+// seal encrypts plaintext into the on-disk format
+func (k *encryptionKey) seal(plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(k.key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	var file encryptedFile
+	file.Encryption.KDF = kdfName
+	file.Encryption.Iterations = kdfIterations
+	file.Encryption.Salt = k.salt
+	file.Encryption.Nonce = nonce
+	file.Ciphertext = gcm.Seal(nil, nonce, plaintext, nil)
+
+	return json.MarshalIndent(file, "", "    ")
+}
+
+// passphrase returns the passphrase from GODEPLOY_PASSPHRASE or the prompt
+func passphrase() (string, error) {
+	if env := os.Getenv(PassphraseEnvVar); env != "" {
+		return env, nil
+	}
+	if cachedPassphrase != "" {
+		return cachedPassphrase, nil
+	}
+	if PromptPassphrase == nil {
+		return "", fmt.Errorf("credentials are encrypted; set %s to unlock them", PassphraseEnvVar)
+	}
+
+	value, err := PromptPassphrase()
+	if err != nil {
+		return "", fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	if value == "" {
+		return "", fmt.Errorf("a passphrase is required to unlock the credentials")
+	}
+	cachedPassphrase = value
+	return value, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// deriveKey derives the AES-256 key with PBKDF2-HMAC-SHA256 (RFC 8018)
+func deriveKey(passphrase string, salt []byte, iterations int) []byte {
+	return pbkdf2.Key([]byte(passphrase), salt, iterations, keySize, sha256.New)
+}
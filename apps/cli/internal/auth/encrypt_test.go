@@ -0,0 +1,158 @@
+package auth
+
+import (
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// TestDeriveKey checks the PBKDF2-HMAC-SHA256 implementation against known vectors
+func TestDeriveKey(t *testing.T) {
+	tests := []struct {
+		iterations int
+		want       string
+	}{
+		{1, "120fb6cffcf8b32c43e7225256c4f837a86548c92ccc35480805987cb70be17b"},
+		{2, "ae4d0c95af6b46d32d0adff928f06dd02a303f8ef3c251dfd6e2d85a95474c43"},
+		{4096, "c5e478d59288c841aa530db6845c4c8d962893a001ce4e11a4963873aa98134a"},
+	}
+	for _, tt := range tests {
+		got := hex.EncodeToString(deriveKey("password", []byte("salt"), tt.iterations))
+		if got != tt.want {
+			t.Errorf("iterations=%d: expected %s, got %s", tt.iterations, tt.want, got)
+		}
+	}
+}
+
+// TestEncryptedStore tests enabling, using and disabling encryption at rest
+func TestEncryptedStore(t *testing.T) {
+	dir := useTempConfigDir(t)
+	t.Cleanup(func() {
+		cachedPassphrase = ""
+		cachedKey.passphrase, cachedKey.key = "", nil
+	})
+
+	if err := SetTokens("secret-access", "secret-refresh"); err != nil {
+		t.Fatalf("SetTokens failed: %v", err)
+	}
+	if err := EnableEncryption("correct horse"); err != nil {
+		t.Fatalf("EnableEncryption failed: %v", err)
+	}
+
+	// Tokens must no longer appear in the file
+	data, err := os.ReadFile(filepath.Join(dir, "config.json"))
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	if strings.Contains(string(data), "secret-access") {
+		t.Fatal("Expected tokens to be encrypted on disk")
+	}
+	if encrypted, err := IsEncrypted(); err != nil || !encrypted {
+		t.Fatalf("Expected IsEncrypted to be true, got %v, %v", encrypted, err)
+	}
+
+	// A fresh process has no cached passphrase; it reads the environment
+	cachedPassphrase = ""
+	t.Setenv(PassphraseEnvVar, "wrong")
+	if _, err := GetAuthToken(); !errors.Is(err, ErrIncorrectPassphrase) {
+		t.Fatalf("Expected ErrIncorrectPassphrase, got %v", err)
+	}
+
+	t.Setenv(PassphraseEnvVar, "correct horse")
+	if token, err := GetAuthToken(); err != nil || token != "secret-access" {
+		t.Fatalf("Expected decrypted token, got %q, %v", token, err)
+	}
+
+	// Saves keep the file encrypted
+	if err := SetAuthToken("rotated-access"); err != nil {
+		t.Fatalf("SetAuthToken failed: %v", err)
+	}
+	if encrypted, _ := IsEncrypted(); !encrypted {
+		t.Fatal("Expected file to stay encrypted after save")
+	}
+
+	if err := DisableEncryption(); err != nil {
+		t.Fatalf("DisableEncryption failed: %v", err)
+	}
+	data, _ = os.ReadFile(filepath.Join(dir, "config.json"))
+	if !strings.Contains(string(data), "rotated-access") {
+		t.Fatal("Expected plain tokens after disabling encryption")
+	}
+}
+
+// TestLoosePermissionsAreFixed tests that an existing world-readable file is tightened on load
+func TestLoosePermissionsAreFixed(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("permission modes are asserted on Linux only")
+	}
+
+	dir := useTempConfigDir(t)
+	if err := os.Chmod(dir, 0o755); err != nil {
+		t.Fatalf("Failed to chmod dir: %v", err)
+	}
+	configPath := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(configPath, []byte(`{"auth_token":"t"}`), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if err := os.Chmod(configPath, 0o644); err != nil {
+		t.Fatalf("Failed to chmod config: %v", err)
+	}
+
+	if _, err := LoadAuthConfig(); err != nil {
+		t.Fatalf("LoadAuthConfig failed: %v", err)
+	}
+
+	for path, want := range map[string]os.FileMode{dir: 0o700, configPath: 0o600} {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("Failed to stat %s: %v", path, err)
+		}
+		if info.Mode().Perm() != want {
+			t.Errorf("Expected %s to have mode %04o, got %04o", path, want, info.Mode().Perm())
+		}
+	}
+}
+
+// TestLegacyMigrationIsPrivate tests that migrated credentials are not world-readable
+func TestLegacyMigrationIsPrivate(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("permission modes are asserted on Linux only")
+	}
+
+	dir := useTempConfigDir(t)
+	legacyDir := filepath.Join(dir, "legacy")
+	if err := os.MkdirAll(legacyDir, 0o755); err != nil {
+		t.Fatalf("Failed to create legacy dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(legacyDir, "config.json"), []byte(`{"auth_token":"t"}`), 0o644); err != nil {
+		t.Fatalf("Failed to write legacy config: %v", err)
+	}
+
+	xdgDir := filepath.Join(dir, "xdg")
+	GetConfigDir = func() (string, error) {
+		return xdgDir, nil
+	}
+
+	if err := migrateFromLegacyLocation(); err != nil {
+		t.Fatalf("Migration failed: %v", err)
+	}
+
+	info, err := os.Stat(filepath.Join(xdgDir, "config.json"))
+	if err != nil {
+		t.Fatalf("Failed to stat migrated config: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Fatalf("Expected migrated config mode 0600, got %04o", info.Mode().Perm())
+	}
+	info, err = os.Stat(xdgDir)
+	if err != nil {
+		t.Fatalf("Failed to stat config dir: %v", err)
+	}
+	if info.Mode().Perm() != 0o700 {
+		t.Fatalf("Expected config dir mode 0700, got %04o", info.Mode().Perm())
+	}
+}
@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// lockFileName is the advisory lock guarding config.json. It is a separate
+	// file because config.json is replaced by rename on every save.
+	lockFileName = "config.json.lock"
+	// lockTimeout bounds how long a process waits for another one's refresh
+	lockTimeout = 30 * time.Second
+	// lockRetryInterval is how often a held lock is retried
+	lockRetryInterval = 50 * time.Millisecond
+)
+
+// errLockHeld is returned by tryLock when another process holds the lock
+var errLockHeld = errors.New("lock is held by another process")
+
+// withConfigLock runs fn while holding an exclusive advisory lock on the
+// credentials file, so concurrent godeploy processes don't interleave their
+// load-modify-save cycles or spend the same refresh token twice
+func withConfigLock(fn func() error) error {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return err
+	}
+	if err := ensureConfigDir(configDir); err != nil {
+		return err
+	}
+
+	lockPath := filepath.Join(configDir, lockFileName)
+	file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, configFileMode)
+	if err != nil {
+		return fmt.Errorf("failed to open lock file: %w", err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		err := tryLock(file)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, errLockHeld) {
+			return fmt.Errorf("failed to lock %s: %w", lockPath, err)
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for another godeploy process to release %s", lockPath)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+	defer func() {
+		_ = unlock(file)
+	}()
+
+	return fn()
+}
+
+// writePrivateFile atomically replaces path with data, readable by the user
+// only. Readers see either the old or the new file, never a partial write.
+func writePrivateFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer func() {
+		// No-op once the rename has succeeded
+		_ = os.Remove(tmpPath)
+	}()
+
+	// CreateTemp already creates the file with mode 0600
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, path)
+}
@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// TestConcurrentRefreshUsesRefreshTokenOnce tests that parallel refreshes
+// don't spend the same single-use refresh token twice
+func TestConcurrentRefreshUsesRefreshTokenOnce(t *testing.T) {
+	useTempConfigDir(t)
+	if err := SetTokens("access-0", "refresh-0"); err != nil {
+		t.Fatalf("SetTokens failed: %v", err)
+	}
+
+	// Fake server: each refresh token is valid exactly once
+	var mu sync.Mutex
+	valid := map[string]bool{"refresh-0": true}
+	calls := 0
+	refresh := func(refreshToken string) (string, string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if !valid[refreshToken] {
+			return "", "", fmt.Errorf("refresh token %s already used", refreshToken)
+		}
+		delete(valid, refreshToken)
+		calls++
+		next := fmt.Sprintf("refresh-%d", calls)
+		valid[next] = true
+		return fmt.Sprintf("access-%d", calls), next, nil
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- NewTokenManager(refresh).RefreshAccessToken()
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("RefreshAccessToken failed: %v", err)
+		}
+	}
+
+	refreshToken, err := GetRefreshToken()
+	if err != nil {
+		t.Fatalf("GetRefreshToken failed: %v", err)
+	}
+	if !valid[refreshToken] {
+		t.Fatalf("Stored refresh token %s is not the latest valid one", refreshToken)
+	}
+}
+
+// TestConcurrentUpdatesAreNotLost tests that writes from parallel callers
+// are serialized rather than overwriting each other
+func TestConcurrentUpdatesAreNotLost(t *testing.T) {
+	useTempConfigDir(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := updateStore(func(store *Store) error {
+				store.Profiles[fmt.Sprintf("p%d", i)] = &Config{Email: "x"}
+				return nil
+			}); err != nil {
+				t.Errorf("update failed: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	store, err := LoadStore()
+	if err != nil {
+		t.Fatalf("LoadStore failed: %v", err)
+	}
+	if len(store.Profiles) != 10 {
+		t.Fatalf("Expected 10 profiles, got %v", store.Names())
+	}
+}
+
+// TestSaveLeavesNoTempFiles tests that atomic saves clean up after themselves
+func TestSaveLeavesNoTempFiles(t *testing.T) {
+	dir := useTempConfigDir(t)
+	if err := SetTokens("access", "refresh"); err != nil {
+		t.Fatalf("SetTokens failed: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir failed: %v", err)
+	}
+	for _, entry := range entries {
+		if entry.Name() != "config.json" && entry.Name() != lockFileName {
+			t.Fatalf("Unexpected file left in config dir: %s", entry.Name())
+		}
+	}
+
+	info, err := os.Stat(filepath.Join(dir, "config.json"))
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if info.Size() == 0 {
+		t.Fatal("Expected config.json to have content")
+	}
+}
@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+)
+
+const (
+	// configDirMode keeps the config directory private to the user
+	configDirMode os.FileMode = 0o700
+	// configFileMode keeps credential files readable by the user only
+	configFileMode os.FileMode = 0o600
+)
+
+// ensureConfigDir creates dir with private permissions, tightening them if
+// the directory already exists with group or other access
+func ensureConfigDir(dir string) error {
+	if err := os.MkdirAll(dir, configDirMode); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	_, err := ensurePrivate(dir, configDirMode)
+	return err
+}
+
+// ensurePrivate changes the mode of path to mode when group or others have
+// any access, and reports whether it did. Windows has no POSIX modes, so it
+// is a no-op there.
+func ensurePrivate(path string, mode os.FileMode) (bool, error) {
+	if runtime.GOOS == "windows" {
+		return false, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+	if info.Mode().Perm()&0o077 == 0 {
+		return false, nil
+	}
+
+	if err := os.Chmod(path, mode); err != nil {
+		return false, fmt.Errorf("failed to restrict permissions on %s: %w", path, err)
+	}
+	return true, nil
+}
+
+// fixPermissions tightens loose permissions on an existing config directory
+// and credentials file, warning when it had to
+func fixPermissions(dir, path string) {
+	for _, target := range []struct {
+		path string
+		mode os.FileMode
+	}{{dir, configDirMode}, {path, configFileMode}} {
+		info, err := os.Stat(target.path)
+		if err != nil {
+			continue
+		}
+		before := info.Mode().Perm()
+		changed, err := ensurePrivate(target.path, target.mode)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %s is accessible by other users and could not be fixed: %v\n", target.path, err)
+			continue
+		}
+		if changed {
+			fmt.Fprintf(os.Stderr, "Warning: %s was accessible by other users (mode %04o); changed to %04o\n", target.path, before, target.mode)
+		}
+	}
+}
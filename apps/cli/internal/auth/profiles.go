@@ -0,0 +1,307 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+)
+
+const (
+	// DefaultProfile is the profile used when none is selected
+	DefaultProfile = "default"
+	// ProfileEnvVar is the environment variable selecting the profile
+	ProfileEnvVar = "GODEPLOY_PROFILE"
+)
+
+// profileOverride is the profile passed on the command line
+var profileOverride string
+
+// profileNamePattern restricts profile names to something safe to type and store
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// Store is the on-disk auth configuration: one Config per named profile
+type Store struct {
+	// CurrentProfile is the profile selected with 'auth profiles use'
+	CurrentProfile string `json:"current_profile,omitempty"`
+	// Profiles maps profile names to their credentials
+	Profiles map[string]*Config `json:"profiles"`
+	// PendingSignOuts are sessions logged out offline whose server-side
+	// revocation has not happened yet
+	PendingSignOuts []PendingSignOut `json:"pending_signouts,omitempty"`
+
+	// key is set when the file is encrypted; saves re-encrypt with it
+	key *encryptionKey
+}
+
+// Encrypted reports whether the store is encrypted at rest
+func (s *Store) Encrypted() bool {
+	return s.key != nil
+}
+
+// storeFile reads both the profile format and the original single-account
+// format, where the Config fields sat at the top level
+type storeFile struct {
+	Store
+	Config
+}
+
+// SetProfile selects the profile given with --profile. It takes precedence
+// over GODEPLOY_PROFILE and the current profile saved in the config file.
+func SetProfile(name string) {
+	profileOverride = name
+}
+
+// ValidateProfileName checks that name can be used as a profile name
+func ValidateProfileName(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid profile name '%s': use letters, digits, '-', '_' and '.'", name)
+	}
+	return nil
+}
+
+// Active returns the profile commands operate on: --profile, then
+// GODEPLOY_PROFILE, then the saved current profile, then "default"
+func (s *Store) Active() string {
+	if profileOverride != "" {
+		return profileOverride
+	}
+	if env := os.Getenv(ProfileEnvVar); env != "" {
+		return env
+	}
+	if s.CurrentProfile != "" {
+		return s.CurrentProfile
+	}
+	return DefaultProfile
+}
+
+// Names returns the profile names, sorted
+func (s *Store) Names() []string {
+	names := make([]string, 0, len(s.Profiles))
+	for name := range s.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadStore loads all profiles from the config file. A config file in the
+// original single-account format is read as the "default" profile and
+// rewritten in the profile format on the next save.
+func LoadStore() (*Store, error) {
+	// Attempt migration from legacy location if needed
+	if err := migrateFromLegacyLocation(); err != nil {
+		// Log migration error but don't fail - we can still try to load
+		// from the current location
+		fmt.Fprintf(os.Stderr, "Warning: failed to migrate config from legacy location: %v\n", err)
+	}
+
+	configPath, err := GetConfigFilePath()
+	if err != nil {
+		return nil, err
+	}
+
+	store := &Store{Profiles: map[string]*Config{}}
+
+	data, err := os.ReadFile(configPath)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read auth config file: %w", err)
+	}
+
+	// Files written by older versions may be readable by other users
+	if configDir, err := GetConfigDir(); err == nil {
+		fixPermissions(configDir, configPath)
+	}
+
+	encrypted, err := parseEncryptedFile(data)
+	if err != nil {
+		return nil, err
+	}
+	if encrypted != nil {
+		data, store.key, err = encrypted.decrypt()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var file storeFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse auth config file: %w", err)
+	}
+
+	store.CurrentProfile = file.CurrentProfile
+	store.PendingSignOuts = file.PendingSignOuts
+	for name, config := range file.Profiles {
+		if config != nil {
+			store.Profiles[name] = config
+		}
+	}
+
+	// Single-account file: its credentials become the default profile
+	if file.Profiles == nil && file.Config != (Config{}) {
+		legacy := file.Config
+		store.Profiles[DefaultProfile] = &legacy
+	}
+
+	return store, nil
+}
+
+// SaveStore writes all profiles to the config file, replacing its contents
+func SaveStore(store *Store) error {
+	return withConfigLock(func() error {
+		return saveStore(store)
+	})
+}
+
+// updateStore re-reads the store under the config lock, applies fn and saves
+// the result. Reading after taking the lock picks up changes, such as a token
+// refresh, that another process made while this one waited.
+func updateStore(fn func(store *Store) error) error {
+	return withConfigLock(func() error {
+		store, err := LoadStore()
+		if err != nil {
+			return err
+		}
+		if err := fn(store); err != nil {
+			return err
+		}
+		return saveStore(store)
+	})
+}
+
+// saveStore writes the store atomically; callers hold the config lock
+func saveStore(store *Store) error {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return err
+	}
+
+	// Create the config directory if it doesn't exist
+	if err := ensureConfigDir(configDir); err != nil {
+		return err
+	}
+
+	configPath, err := GetConfigFilePath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(store, "", "    ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal auth config: %w", err)
+	}
+
+	if store.key != nil {
+		data, err = store.key.seal(data)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt auth config: %w", err)
+		}
+	}
+
+	if err := writePrivateFile(configPath, data); err != nil {
+		return fmt.Errorf("failed to write auth config file: %w", err)
+	}
+
+	return nil
+}
+
+// ActiveProfile returns the name of the profile commands operate on
+func ActiveProfile() (string, error) {
+	store, err := LoadStore()
+	if err != nil {
+		return "", err
+	}
+	return store.Active(), nil
+}
+
+// UseProfile makes name the current profile
+func UseProfile(name string) error {
+	return updateStore(func(store *Store) error {
+		if _, ok := store.Profiles[name]; !ok {
+			return fmt.Errorf("profile '%s' does not exist. Run 'godeploy auth login --profile %s' to create it", name, name)
+		}
+		store.CurrentProfile = name
+		return nil
+	})
+}
+
+// RemoveProfile deletes a profile and its credentials. Removing the current
+// profile switches back to the default profile.
+func RemoveProfile(name string) error {
+	return updateStore(func(store *Store) error {
+		if _, ok := store.Profiles[name]; !ok {
+			return fmt.Errorf("profile '%s' does not exist", name)
+		}
+		delete(store.Profiles, name)
+		if store.CurrentProfile == name {
+			store.CurrentProfile = ""
+		}
+		return nil
+	})
+}
+
+// GetAPIURL returns the API base URL configured for the active profile, or
+// "" to use the default
+func GetAPIURL() (string, error) {
+	config, err := LoadAuthConfig()
+	if err != nil {
+		return "", err
+	}
+	return config.APIURL, nil
+}
+
+// SetAPIURL sets the API base URL of the active profile
+func SetAPIURL(apiURL string) error {
+	return updateAuthConfig(func(config *Config) {
+		config.APIURL = apiURL
+	})
+}
+
+// EnableEncryption encrypts the credentials file with passphrase. Later
+// commands need the passphrase, from GODEPLOY_PASSPHRASE or a prompt.
+func EnableEncryption(passphrase string) error {
+	if passphrase == "" {
+		return fmt.Errorf("passphrase must not be empty")
+	}
+
+	return updateStore(func(store *Store) error {
+		key, err := newEncryptionKey(passphrase)
+		if err != nil {
+			return err
+		}
+		store.key = key
+		cachedPassphrase = passphrase
+		return nil
+	})
+}
+
+// DisableEncryption rewrites an encrypted credentials file in plain JSON
+func DisableEncryption() error {
+	return updateStore(func(store *Store) error {
+		store.key = nil
+		cachedPassphrase = ""
+		return nil
+	})
+}
+
+// IsEncrypted reports whether the credentials file is encrypted, without
+// asking for the passphrase
+func IsEncrypted() (bool, error) {
+	configPath, err := GetConfigFilePath()
+	if err != nil {
+		return false, err
+	}
+	data, err := os.ReadFile(configPath)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read auth config file: %w", err)
+	}
+	encrypted, err := parseEncryptedFile(data)
+	return encrypted != nil, err
+}
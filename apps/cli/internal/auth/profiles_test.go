@@ -0,0 +1,148 @@
+package auth
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// useTempConfigDir points the auth config at a fresh directory for one test
+func useTempConfigDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+
+	origGetConfigDir := GetConfigDir
+	origGetLegacyConfigDir := GetLegacyConfigDir
+	t.Cleanup(func() {
+		GetConfigDir = origGetConfigDir
+		GetLegacyConfigDir = origGetLegacyConfigDir
+		SetProfile("")
+	})
+	GetConfigDir = func() (string, error) {
+		return dir, nil
+	}
+	GetLegacyConfigDir = func() (string, error) {
+		return filepath.Join(dir, "legacy"), nil
+	}
+	t.Setenv(ProfileEnvVar, "")
+	return dir
+}
+
+// TestSingleAccountConfigMigratesToDefaultProfile tests that the original file format still loads
+func TestSingleAccountConfigMigratesToDefaultProfile(t *testing.T) {
+	dir := useTempConfigDir(t)
+	legacy := `{"auth_token":"old-token","refresh_token":"old-refresh","email":"me@example.com"}`
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(legacy), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	config, err := LoadAuthConfig()
+	if err != nil {
+		t.Fatalf("LoadAuthConfig failed: %v", err)
+	}
+	if config.AuthToken != "old-token" || config.Email != "me@example.com" {
+		t.Fatalf("Expected single-account credentials in default profile, got %+v", config)
+	}
+
+	// The next save rewrites the file in the profile format
+	if err := SetUserEmail("me@example.com"); err != nil {
+		t.Fatalf("SetUserEmail failed: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "config.json"))
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("Failed to parse config: %v", err)
+	}
+	if _, ok := raw["auth_token"]; ok {
+		t.Fatal("Expected top-level credentials to be removed after migration")
+	}
+	if _, ok := raw["profiles"]; !ok {
+		t.Fatal("Expected profiles in migrated config")
+	}
+}
+
+// TestProfilesAreIsolated tests that each profile keeps its own credentials
+func TestProfilesAreIsolated(t *testing.T) {
+	useTempConfigDir(t)
+
+	if err := SetTokens("personal-token", "personal-refresh"); err != nil {
+		t.Fatalf("SetTokens failed: %v", err)
+	}
+
+	SetProfile("acme")
+	if token, _ := GetAuthToken(); token != "" {
+		t.Fatalf("Expected new profile to start empty, got %q", token)
+	}
+	if err := SaveAuthConfig(&Config{AuthToken: "acme-token", APIURL: "https://api.acme.test"}); err != nil {
+		t.Fatalf("SaveAuthConfig failed: %v", err)
+	}
+	if url, _ := GetAPIURL(); url != "https://api.acme.test" {
+		t.Fatalf("Expected acme API URL, got %q", url)
+	}
+
+	SetProfile("")
+	if token, _ := GetAuthToken(); token != "personal-token" {
+		t.Fatalf("Expected default profile token, got %q", token)
+	}
+
+	// GODEPLOY_PROFILE selects a profile when --profile is not given
+	t.Setenv(ProfileEnvVar, "acme")
+	if token, _ := GetAuthToken(); token != "acme-token" {
+		t.Fatalf("Expected acme token via %s, got %q", ProfileEnvVar, token)
+	}
+}
+
+// TestUseAndRemoveProfile tests switching and deleting profiles
+func TestUseAndRemoveProfile(t *testing.T) {
+	useTempConfigDir(t)
+
+	if err := UseProfile("missing"); err == nil {
+		t.Fatal("Expected error switching to a missing profile")
+	}
+
+	SetProfile("client")
+	if err := SetAuthToken("client-token"); err != nil {
+		t.Fatalf("SetAuthToken failed: %v", err)
+	}
+	SetProfile("")
+
+	if err := UseProfile("client"); err != nil {
+		t.Fatalf("UseProfile failed: %v", err)
+	}
+	if active, _ := ActiveProfile(); active != "client" {
+		t.Fatalf("Expected active profile 'client', got %q", active)
+	}
+
+	if err := RemoveProfile("client"); err != nil {
+		t.Fatalf("RemoveProfile failed: %v", err)
+	}
+	if active, _ := ActiveProfile(); active != DefaultProfile {
+		t.Fatalf("Expected fallback to default profile, got %q", active)
+	}
+
+	store, err := LoadStore()
+	if err != nil {
+		t.Fatalf("LoadStore failed: %v", err)
+	}
+	if len(store.Profiles) != 0 {
+		t.Fatalf("Expected no profiles left, got %v", store.Names())
+	}
+}
+
+// TestValidateProfileName tests profile name validation
+func TestValidateProfileName(t *testing.T) {
+	for _, name := range []string{"default", "acme", "client-2", "me.work"} {
+		if err := ValidateProfileName(name); err != nil {
+			t.Errorf("Expected %q to be valid: %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "-x", "a b", "../etc"} {
+		if err := ValidateProfileName(name); err == nil {
+			t.Errorf("Expected %q to be invalid", name)
+		}
+	}
+}
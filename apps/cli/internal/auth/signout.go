@@ -0,0 +1,52 @@
+package auth
+
+import "time"
+
+// PendingSignOut is a logout whose server-side revocation failed, usually
+// because the machine was offline. It is retried by the next online command.
+type PendingSignOut struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	// AllSessions revokes every session of the account, not just this one
+	AllSessions bool `json:"all_sessions,omitempty"`
+	// APIURL is the API the session belongs to
+	APIURL   string    `json:"api_url"`
+	QueuedAt time.Time `json:"queued_at"`
+}
+
+// QueueSignOut records revocations to retry later
+func QueueSignOut(pending ...PendingSignOut) error {
+	if len(pending) == 0 {
+		return nil
+	}
+	return updateStore(func(store *Store) error {
+		store.PendingSignOuts = append(store.PendingSignOuts, pending...)
+		return nil
+	})
+}
+
+// TakePendingSignOuts removes and returns all queued revocations. Callers
+// queue the ones that still fail again with QueueSignOut.
+func TakePendingSignOuts() ([]PendingSignOut, error) {
+	// Most runs have nothing queued; don't rewrite the file for them
+	if queued, err := HasPendingSignOuts(); err != nil || !queued {
+		return nil, err
+	}
+
+	var pending []PendingSignOut
+	err := updateStore(func(store *Store) error {
+		pending = store.PendingSignOuts
+		store.PendingSignOuts = nil
+		return nil
+	})
+	return pending, err
+}
+
+// HasPendingSignOuts reports whether any revocations are queued
+func HasPendingSignOuts() (bool, error) {
+	store, err := LoadStore()
+	if err != nil {
+		return false, err
+	}
+	return len(store.PendingSignOuts) > 0, nil
+}
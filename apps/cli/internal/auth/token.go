@@ -0,0 +1,25 @@
+package auth
+
+import "os"
+
+// TokenEnvVar is the environment variable holding a long-lived API token
+const TokenEnvVar = "GODEPLOY_TOKEN"
+
+// apiTokenOverride is the API token passed on the command line
+var apiTokenOverride string
+
+// SetAPIToken sets the API token given with --token. It takes precedence
+// over GODEPLOY_TOKEN.
+func SetAPIToken(token string) {
+	apiTokenOverride = token
+}
+
+// GetAPIToken returns the long-lived API token from --token or
+// GODEPLOY_TOKEN, or "" when neither is set. An API token replaces the saved
+// login: it is sent as-is and never refreshed.
+func GetAPIToken() string {
+	if apiTokenOverride != "" {
+		return apiTokenOverride
+	}
+	return os.Getenv(TokenEnvVar)
+}
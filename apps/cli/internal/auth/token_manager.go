@@ -103,38 +103,66 @@ func (tm *TokenManager) IsTokenExpired(token string, buffer time.Duration) (bool
 	return time.Now().Add(buffer).After(expirationTime), nil
 }
 
-// RefreshAccessToken exchanges the refresh token for a new access token
+// RefreshAccessToken exchanges the refresh token for a new access token.
+// Refresh tokens are single-use, so the exchange runs under the config lock;
+// if another process refreshed while this one waited, its tokens are used
+// instead of spending the (now invalid) refresh token again.
 func (tm *TokenManager) RefreshAccessToken() error {
 	logging.Debug().Msg("starting token refresh")
 
-	refreshToken, err := GetRefreshToken()
+	// Remember which access token we are replacing
+	staleToken, err := GetAuthToken()
 	if err != nil {
-		logging.Err(err, "failed to get refresh token from storage")
-		return fmt.Errorf("failed to get refresh token: %w", err)
+		logging.Err(err, "failed to get auth token from storage")
+		return fmt.Errorf("failed to get auth token: %w", err)
 	}
 
-	if refreshToken == "" {
-		logging.Warn().Msg("no refresh token found in storage")
-		return fmt.Errorf("no refresh token found, please login again")
-	}
+	return withConfigLock(func() error {
+		config, err := LoadAuthConfig()
+		if err != nil {
+			logging.Err(err, "failed to get refresh token from storage")
+			return fmt.Errorf("failed to get refresh token: %w", err)
+		}
 
-	logging.Debug().Msg("calling refresh API")
+		if config.AuthToken != "" && config.AuthToken != staleToken {
+			logging.Info().Msg("tokens were refreshed by another process")
+			return nil
+		}
 
-	// Call the refresh function (injected from API client)
-	newAccessToken, newRefreshToken, err := tm.refreshFunc(refreshToken)
-	if err != nil {
-		logging.Error().Err(err).Msg("refresh API call failed")
-		return fmt.Errorf("failed to refresh token: %w", err)
-	}
+		if config.RefreshToken == "" {
+			logging.Warn().Msg("no refresh token found in storage")
+			return fmt.Errorf("no refresh token found, please login again")
+		}
 
-	logging.Debug().Msg("saving new tokens")
+		logging.Debug().Msg("calling refresh API")
 
-	// Save the new tokens
-	if err := SetTokens(newAccessToken, newRefreshToken); err != nil {
-		logging.Err(err, "failed to save refreshed tokens")
-		return fmt.Errorf("failed to save refreshed tokens: %w", err)
-	}
+		// Call the refresh function (injected from API client)
+		newAccessToken, newRefreshToken, err := tm.refreshFunc(config.RefreshToken)
+		if err != nil {
+			logging.Error().Err(err).Msg("refresh API call failed")
+			return fmt.Errorf("failed to refresh token: %w", err)
+		}
+
+		logging.Debug().Msg("saving new tokens")
+
+		// Save the new tokens; the lock is already held
+		store, err := LoadStore()
+		if err != nil {
+			logging.Err(err, "failed to save refreshed tokens")
+			return fmt.Errorf("failed to save refreshed tokens: %w", err)
+		}
+		active := store.Active()
+		if store.Profiles[active] == nil {
+			store.Profiles[active] = &Config{}
+		}
+		store.Profiles[active].AuthToken = newAccessToken
+		store.Profiles[active].RefreshToken = newRefreshToken
+		if err := saveStore(store); err != nil {
+			logging.Err(err, "failed to save refreshed tokens")
+			return fmt.Errorf("failed to save refreshed tokens: %w", err)
+		}
 
-	logging.Info().Msg("tokens refreshed and saved successfully")
-	return nil
+		logging.Info().Msg("tokens refreshed and saved successfully")
+		return nil
+	})
 }
@@ -0,0 +1,23 @@
+package auth
+
+import "testing"
+
+// TestGetAPIToken tests that --token takes precedence over GODEPLOY_TOKEN
+func TestGetAPIToken(t *testing.T) {
+	t.Setenv(TokenEnvVar, "")
+	defer SetAPIToken("")
+
+	if token := GetAPIToken(); token != "" {
+		t.Fatalf("Expected no API token, got %q", token)
+	}
+
+	t.Setenv(TokenEnvVar, "env-token")
+	if token := GetAPIToken(); token != "env-token" {
+		t.Fatalf("Expected token from environment, got %q", token)
+	}
+
+	SetAPIToken("flag-token")
+	if token := GetAPIToken(); token != "flag-token" {
+		t.Fatalf("Expected token from flag, got %q", token)
+	}
+}
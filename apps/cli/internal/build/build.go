@@ -0,0 +1,220 @@
+// Package build runs an app's configured build command before it is archived
+// and deployed. Build output is streamed line by line with a prefix so it can
+// be told apart from the CLI's own output.
+package build
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Options describes a single build run
+type Options struct {
+	// Command is the shell command to run (e.g. "npm run build")
+	Command string
+	// Dir is the working directory for the command
+	Dir string
+	// Env holds extra environment variables added to the current environment
+	Env map[string]string
+	// OutputDir is the directory the build is expected to produce
+	OutputDir string
+	// Prefix is written before every line of build output
+	Prefix string
+	// Stdout and Stderr receive the prefixed build output (default os.Stdout/os.Stderr)
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// Result contains information about a completed build
+type Result struct {
+	Duration  time.Duration
+	OutputDir string
+}
+
+// Run executes the build command and verifies that it produced fresh output.
+// It returns an error if the command exits non-zero, or if the output
+// directory is missing or was not updated by the build.
+func Run(ctx context.Context, opts Options) (*Result, error) {
+	if opts.Command == "" {
+		return nil, fmt.Errorf("no build command configured")
+	}
+
+	stdout := opts.Stdout
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+	stderr := opts.Stderr
+	if stderr == nil {
+		stderr = os.Stderr
+	}
+
+	outWriter := NewPrefixWriter(stdout, opts.Prefix)
+	errWriter := NewPrefixWriter(stderr, opts.Prefix)
+
+	cmd := shellCommand(ctx, opts.Command)
+	cmd.Dir = opts.Dir
+	cmd.Env = mergeEnv(os.Environ(), opts.Env)
+	cmd.Stdout = outWriter
+	cmd.Stderr = errWriter
+
+	startTime := time.Now()
+	runErr := cmd.Run()
+
+	// Flush any trailing partial lines before reporting the result
+	_ = outWriter.Flush()
+	_ = errWriter.Flush()
+
+	if runErr != nil {
+		var exitErr *exec.ExitError
+		if errors.As(runErr, &exitErr) {
+			return nil, fmt.Errorf("build command failed with exit code %d", exitErr.ExitCode())
+		}
+		return nil, fmt.Errorf("failed to run build command: %w", runErr)
+	}
+
+	result := &Result{
+		Duration:  time.Since(startTime),
+		OutputDir: opts.OutputDir,
+	}
+
+	if opts.OutputDir == "" {
+		return result, nil
+	}
+
+	// Truncate to the second: some filesystems only store coarse mtimes
+	if err := verifyOutput(opts.OutputDir, startTime.Truncate(time.Second)); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// verifyOutput checks that dir exists and contains at least one file modified
+// at or after since
+func verifyOutput(dir string, since time.Time) error {
+	info, err := os.Stat(dir)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("build output directory '%s' was not created", dir)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to stat build output directory: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("build output '%s' is not a directory", dir)
+	}
+
+	var newest time.Time
+	fileCount := 0
+	walkErr := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		fileCount++
+		if info.ModTime().After(newest) {
+			newest = info.ModTime()
+		}
+		return nil
+	})
+	if walkErr != nil {
+		return fmt.Errorf("failed to inspect build output directory: %w", walkErr)
+	}
+
+	if fileCount == 0 {
+		return fmt.Errorf("build output directory '%s' is empty", dir)
+	}
+	if newest.Before(since) {
+		return fmt.Errorf("build output in '%s' is older than the build; check that the build writes to this directory", dir)
+	}
+
+	return nil
+}
+
+// shellCommand wraps command in the platform shell
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
+
+// mergeEnv appends extra variables to base in a stable order
+func mergeEnv(base []string, extra map[string]string) []string {
+	if len(extra) == 0 {
+		return base
+	}
+
+	keys := make([]string, 0, len(extra))
+	for k := range extra {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	env := make([]string, 0, len(base)+len(extra))
+	env = append(env, base...)
+	for _, k := range keys {
+		env = append(env, k+"="+extra[k])
+	}
+	return env
+}
+
+// PrefixWriter writes every complete line it receives to the underlying
+// writer with a prefix. Partial lines are buffered until a newline arrives
+// or Flush is called.
+type PrefixWriter struct {
+	mu     sync.Mutex
+	w      io.Writer
+	prefix string
+	buf    bytes.Buffer
+}
+
+// NewPrefixWriter creates a PrefixWriter
+func NewPrefixWriter(w io.Writer, prefix string) *PrefixWriter {
+	return &PrefixWriter{w: w, prefix: prefix}
+}
+
+// Write implements io.Writer
+func (p *PrefixWriter) Write(data []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.buf.Write(data)
+	for {
+		line, err := p.buf.ReadBytes('\n')
+		if err != nil {
+			// No newline yet; keep the partial line for the next write
+			p.buf.Reset()
+			p.buf.Write(line)
+			break
+		}
+		if _, err := fmt.Fprintf(p.w, "%s%s", p.prefix, line); err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
+
+// Flush writes any buffered partial line
+func (p *PrefixWriter) Flush() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.buf.Len() == 0 {
+		return nil
+	}
+	_, err := fmt.Fprintf(p.w, "%s%s\n", p.prefix, p.buf.String())
+	p.buf.Reset()
+	return err
+}
@@ -0,0 +1,137 @@
+package build
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func skipOnWindows(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("build tests use POSIX shell commands")
+	}
+}
+
+// TestRunStreamsPrefixedOutput tests that build output is prefixed line by line
+func TestRunStreamsPrefixedOutput(t *testing.T) {
+	skipOnWindows(t)
+
+	dir := t.TempDir()
+	var stdout bytes.Buffer
+
+	_, err := Run(context.Background(), Options{
+		Command: "echo one; echo two; printf three",
+		Dir:     dir,
+		Prefix:  "[app] ",
+		Stdout:  &stdout,
+		Stderr:  &stdout,
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	expected := "[app] one\n[app] two\n[app] three\n"
+	if stdout.String() != expected {
+		t.Fatalf("Expected output %q, got %q", expected, stdout.String())
+	}
+}
+
+// TestRunPassesEnv tests that configured environment variables reach the command
+func TestRunPassesEnv(t *testing.T) {
+	skipOnWindows(t)
+
+	var stdout bytes.Buffer
+	_, err := Run(context.Background(), Options{
+		Command: "echo $GODEPLOY_BUILD_TEST",
+		Dir:     t.TempDir(),
+		Env:     map[string]string{"GODEPLOY_BUILD_TEST": "hello"},
+		Stdout:  &stdout,
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if strings.TrimSpace(stdout.String()) != "hello" {
+		t.Fatalf("Expected env value 'hello', got %q", stdout.String())
+	}
+}
+
+// TestRunFailsOnNonZeroExit tests that a failing build returns an error
+func TestRunFailsOnNonZeroExit(t *testing.T) {
+	skipOnWindows(t)
+
+	_, err := Run(context.Background(), Options{
+		Command: "exit 3",
+		Dir:     t.TempDir(),
+		Stdout:  &bytes.Buffer{},
+		Stderr:  &bytes.Buffer{},
+	})
+	if err == nil {
+		t.Fatal("Expected error for non-zero exit")
+	}
+	if !strings.Contains(err.Error(), "exit code 3") {
+		t.Fatalf("Expected exit code in error, got %v", err)
+	}
+}
+
+// TestRunVerifiesOutputDir tests that the output directory must be produced by the build
+func TestRunVerifiesOutputDir(t *testing.T) {
+	skipOnWindows(t)
+
+	dir := t.TempDir()
+	outputDir := filepath.Join(dir, "dist")
+
+	// Missing output directory
+	_, err := Run(context.Background(), Options{
+		Command:   "true",
+		Dir:       dir,
+		OutputDir: outputDir,
+		Stdout:    &bytes.Buffer{},
+	})
+	if err == nil || !strings.Contains(err.Error(), "was not created") {
+		t.Fatalf("Expected missing output error, got %v", err)
+	}
+
+	// Stale output directory
+	if err := os.MkdirAll(outputDir, 0o755); err != nil {
+		t.Fatalf("Failed to create output dir: %v", err)
+	}
+	staleFile := filepath.Join(outputDir, "index.html")
+	if err := os.WriteFile(staleFile, []byte("old"), 0o644); err != nil {
+		t.Fatalf("Failed to write stale file: %v", err)
+	}
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(staleFile, old, old); err != nil {
+		t.Fatalf("Failed to set file times: %v", err)
+	}
+
+	_, err = Run(context.Background(), Options{
+		Command:   "true",
+		Dir:       dir,
+		OutputDir: outputDir,
+		Stdout:    &bytes.Buffer{},
+	})
+	if err == nil || !strings.Contains(err.Error(), "older than the build") {
+		t.Fatalf("Expected stale output error, got %v", err)
+	}
+
+	// Fresh output
+	result, err := Run(context.Background(), Options{
+		Command:   "echo new > dist/index.html",
+		Dir:       dir,
+		OutputDir: outputDir,
+		Stdout:    &bytes.Buffer{},
+	})
+	if err != nil {
+		t.Fatalf("Expected fresh build to succeed, got %v", err)
+	}
+	if result.OutputDir != outputDir {
+		t.Fatalf("Expected output dir %s, got %s", outputDir, result.OutputDir)
+	}
+}
@@ -1,7 +1,9 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -9,9 +11,19 @@ import (
 	"github.com/gosimple/slug"
 )
 
+// DefaultConfigFile is the name of the SPA configuration file
+const DefaultConfigFile = "godeploy.config.json"
+
+// ErrConfigNotFound is returned by FindConfig when no config file exists in
+// the start directory or any of its parents
+var ErrConfigNotFound = errors.New("config file not found")
+
 // SpaConfig represents the configuration for multiple SPAs
 type SpaConfig struct {
 	Apps []App `json:"apps"`
+
+	// Path is the absolute path of the file the config was loaded from
+	Path string `json:"-"`
 }
 
 // App represents a single SPA configuration
@@ -21,6 +33,19 @@ type App struct {
 	SourceDir   string `json:"source_dir"`
 	Description string `json:"description"`
 	Enabled     bool   `json:"enabled"`
+	Build       *Build `json:"build,omitempty"`
+}
+
+// Build describes how to build an app before it is deployed
+type Build struct {
+	// Command is the shell command that builds the app (e.g. "npm run build")
+	Command string `json:"command"`
+	// Env holds extra environment variables for the build command
+	Env map[string]string `json:"env,omitempty"`
+	// WorkingDir is the directory the command runs in (defaults to the config file's directory)
+	WorkingDir string `json:"working_dir,omitempty"`
+	// OutputDir is the directory the build is expected to produce (defaults to source_dir)
+	OutputDir string `json:"output_dir,omitempty"`
 }
 
 // LoadConfig loads the SPA configuration from a file
@@ -35,6 +60,11 @@ func LoadConfig(configPath string) (*SpaConfig, error) {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
+	config.Path, err = filepath.Abs(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve config path: %w", err)
+	}
+
 	for i, app := range config.Apps {
 		slug := slug.Make(app.Name)
 		config.Apps[i].Slug = slug
@@ -48,12 +78,54 @@ func LoadConfig(configPath string) (*SpaConfig, error) {
 	return &config, nil
 }
 
+// FindConfig looks for a file called name in start and each of its parent
+// directories, the way git looks for .git, and returns the first match
+func FindConfig(start, name string) (string, error) {
+	dir, err := filepath.Abs(start)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve directory: %w", err)
+	}
+
+	for {
+		candidate := filepath.Join(dir, name)
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("%w: no %s in %s or any parent directory", ErrConfigNotFound, name, start)
+		}
+		dir = parent
+	}
+}
+
+// Dir returns the directory containing the config file
+func (c *SpaConfig) Dir() string {
+	return filepath.Dir(c.Path)
+}
+
+// ResolvePath makes a path from the config absolute. Relative paths are
+// resolved against the directory containing the config file, so the result
+// doesn't depend on where the CLI was run from.
+func (c *SpaConfig) ResolvePath(path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(c.Dir(), path)
+}
+
 // SaveConfig saves the SPA configuration to a file
 func SaveConfig(config *SpaConfig, configPath string) error {
-	data, err := json.MarshalIndent(config, "", "    ")
-	if err != nil {
+	// Don't HTML-escape values: build commands commonly contain '&&' and '>'
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "    ")
+	if err := encoder.Encode(config); err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
+	data := buf.Bytes()
 
 	if err := os.MkdirAll(filepath.Dir(configPath), 0o755); err != nil {
 		return fmt.Errorf("failed to create config directory: %w", err)
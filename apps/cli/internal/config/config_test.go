@@ -0,0 +1,81 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestFindConfigSearchesParents tests that the config is found from a nested directory
+func TestFindConfigSearchesParents(t *testing.T) {
+	root := t.TempDir()
+	configPath := filepath.Join(root, DefaultConfigFile)
+	if err := os.WriteFile(configPath, []byte(`{"apps":[{"name":"web","source_dir":"dist","enabled":true}]}`), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	nested := filepath.Join(root, "apps", "web", "src")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatalf("Failed to create nested dir: %v", err)
+	}
+
+	found, err := FindConfig(nested, DefaultConfigFile)
+	if err != nil {
+		t.Fatalf("FindConfig failed: %v", err)
+	}
+
+	// Compare resolved paths; the temp dir may sit behind a symlink
+	want, _ := filepath.EvalSymlinks(configPath)
+	got, _ := filepath.EvalSymlinks(found)
+	if got != want {
+		t.Fatalf("Expected %s, got %s", want, got)
+	}
+}
+
+// TestFindConfigNotFound tests the error returned when no config exists
+func TestFindConfigNotFound(t *testing.T) {
+	_, err := FindConfig(t.TempDir(), "godeploy.does-not-exist.json")
+	if !errors.Is(err, ErrConfigNotFound) {
+		t.Fatalf("Expected ErrConfigNotFound, got %v", err)
+	}
+}
+
+// TestResolvePathRelativeToConfig tests that app paths resolve against the config file's directory
+func TestResolvePathRelativeToConfig(t *testing.T) {
+	root := t.TempDir()
+	appDir := filepath.Join(root, "apps", "web")
+	if err := os.MkdirAll(appDir, 0o755); err != nil {
+		t.Fatalf("Failed to create app dir: %v", err)
+	}
+	configPath := filepath.Join(appDir, DefaultConfigFile)
+	if err := os.WriteFile(configPath, []byte(`{"apps":[{"name":"web","source_dir":"dist","enabled":true}]}`), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	// Load from somewhere other than the config directory
+	origDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	if err := os.Chdir(root); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+	defer func() {
+		_ = os.Chdir(origDir)
+	}()
+
+	spaConfig, err := LoadConfig(filepath.Join("apps", "web", DefaultConfigFile))
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	if got := spaConfig.ResolvePath("dist"); got != filepath.Join(appDir, "dist") {
+		t.Fatalf("Expected %s, got %s", filepath.Join(appDir, "dist"), got)
+	}
+
+	abs := filepath.Join(root, "elsewhere")
+	if got := spaConfig.ResolvePath(abs); got != abs {
+		t.Fatalf("Expected absolute path %s to be kept, got %s", abs, got)
+	}
+}
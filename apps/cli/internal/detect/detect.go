@@ -0,0 +1,374 @@
+// Package detect inspects a project directory to work out which framework it
+// uses, how it is built and where the build output ends up. It is used by
+// `godeploy init` to propose a working configuration.
+package detect
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Framework names reported by Detect
+const (
+	FrameworkVite      = "Vite"
+	FrameworkNext      = "Next.js"
+	FrameworkCRA       = "Create React App"
+	FrameworkAngular   = "Angular"
+	FrameworkSvelteKit = "SvelteKit"
+	FrameworkAstro     = "Astro"
+	FrameworkHugo      = "Hugo"
+)
+
+// Result describes what was detected in a project directory
+type Result struct {
+	// AppName is the proposed app name
+	AppName string
+	// Framework is the detected framework, empty if none was recognised
+	Framework string
+	// PackageManager is npm, pnpm, yarn or bun (empty for non-Node projects)
+	PackageManager string
+	// BuildCommand is the proposed build command
+	BuildCommand string
+	// OutputDir is the proposed build output directory, relative to the project
+	OutputDir string
+	// Description is the package.json description, or one naming the
+	// framework when there is none
+	Description string
+	// Warnings lists settings that need attention before the app can deploy
+	Warnings []string
+}
+
+// PackageJSON holds the package.json fields used for detection
+type PackageJSON struct {
+	Name            string            `json:"name"`
+	Description     string            `json:"description"`
+	Scripts         map[string]string `json:"scripts"`
+	Dependencies    map[string]string `json:"dependencies"`
+	DevDependencies map[string]string `json:"devDependencies"`
+	// Workspaces is either a list of globs or an object with a packages list
+	Workspaces json.RawMessage `json:"workspaces"`
+}
+
+// HasDependency reports whether name is a dependency or dev dependency
+func (p *PackageJSON) HasDependency(name string) bool {
+	if _, ok := p.Dependencies[name]; ok {
+		return true
+	}
+	_, ok := p.DevDependencies[name]
+	return ok
+}
+
+// ReadPackageJSON reads dir/package.json. It returns nil without error when
+// the file does not exist.
+func ReadPackageJSON(dir string) (*PackageJSON, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read package.json: %w", err)
+	}
+
+	var pkg PackageJSON
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, fmt.Errorf("failed to parse package.json: %w", err)
+	}
+	return &pkg, nil
+}
+
+// Detect inspects dir and proposes an app name, build command and output
+// directory. A Result is always returned for a readable directory; when no
+// framework is recognised, Framework is empty and generic defaults are used.
+func Detect(dir string) (*Result, error) {
+	return detect(dir, "")
+}
+
+// detect implements Detect. A non-empty packageManager overrides the one
+// implied by lockfiles in dir, which workspace packages don't have.
+func detect(dir, packageManager string) (*Result, error) {
+	pkg, err := ReadPackageJSON(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		AppName:   appName(dir, pkg),
+		OutputDir: "dist",
+	}
+
+	if pkg != nil {
+		result.PackageManager = packageManager
+		if result.PackageManager == "" {
+			result.PackageManager = PackageManager(dir)
+		}
+		if _, ok := pkg.Scripts["build"]; ok {
+			result.BuildCommand = RunScript(result.PackageManager, "build")
+		}
+	}
+
+	switch {
+	case isHugo(dir):
+		result.Framework = FrameworkHugo
+		result.BuildCommand = "hugo --minify"
+		result.OutputDir = "public"
+	case pkg == nil:
+		// Nothing else we recognise works without a package.json
+	case fileExists(filepath.Join(dir, "angular.json")):
+		result.Framework = FrameworkAngular
+		result.OutputDir = angularOutputDir(dir)
+	case pkg.HasDependency("next") || findFile(dir, "next.config", jsExtensions) != "":
+		result.Framework = FrameworkNext
+		result.OutputDir = "out"
+		configFile := findFile(dir, "next.config", jsExtensions)
+		if configFile == "" || !fileMatches(configFile, nextExportPattern) {
+			result.Warnings = append(result.Warnings, "Next.js must use a static export; set output: 'export' in next.config")
+		}
+	case pkg.HasDependency("@sveltejs/kit"):
+		result.Framework = FrameworkSvelteKit
+		result.OutputDir = "build"
+		if !pkg.HasDependency("@sveltejs/adapter-static") {
+			result.Warnings = append(result.Warnings, "SvelteKit needs @sveltejs/adapter-static to produce a static build")
+		}
+		if configFile := findFile(dir, "svelte.config", jsExtensions); configFile != "" {
+			if pages := fileSubmatch(configFile, sveltePagesPattern); pages != "" {
+				result.OutputDir = pages
+			}
+		}
+	case pkg.HasDependency("astro") || findFile(dir, "astro.config", jsExtensions) != "":
+		result.Framework = FrameworkAstro
+		if configFile := findFile(dir, "astro.config", jsExtensions); configFile != "" {
+			if outDir := fileSubmatch(configFile, outDirPattern); outDir != "" {
+				result.OutputDir = outDir
+			}
+		}
+	case pkg.HasDependency("react-scripts"):
+		result.Framework = FrameworkCRA
+		result.OutputDir = "build"
+	case pkg.HasDependency("vite") || findFile(dir, "vite.config", jsExtensions) != "":
+		result.Framework = FrameworkVite
+		if configFile := findFile(dir, "vite.config", jsExtensions); configFile != "" {
+			if outDir := fileSubmatch(configFile, outDirPattern); outDir != "" {
+				result.OutputDir = outDir
+			}
+		}
+	}
+
+	// Fall back to the framework's own CLI when there is no build script
+	if result.BuildCommand == "" {
+		if binary, ok := frameworkBinaries[result.Framework]; ok {
+			result.BuildCommand = execBinary(result.PackageManager, binary) + " build"
+		}
+	}
+
+	result.OutputDir = filepath.ToSlash(filepath.Clean(result.OutputDir))
+	switch {
+	case pkg != nil && pkg.Description != "":
+		result.Description = pkg.Description
+	case result.Framework != "":
+		result.Description = result.Framework + " app"
+	default:
+		result.Description = "Static site"
+	}
+	return result, nil
+}
+
+// PackageManager returns the package manager implied by the lockfile in dir,
+// defaulting to npm
+func PackageManager(dir string) string {
+	switch {
+	case fileExists(filepath.Join(dir, "pnpm-lock.yaml")):
+		return "pnpm"
+	case fileExists(filepath.Join(dir, "yarn.lock")):
+		return "yarn"
+	case fileExists(filepath.Join(dir, "bun.lockb")), fileExists(filepath.Join(dir, "bun.lock")):
+		return "bun"
+	default:
+		return "npm"
+	}
+}
+
+// RunScript returns the command that runs a package.json script with the
+// given package manager
+func RunScript(packageManager, script string) string {
+	if packageManager == "" {
+		packageManager = "npm"
+	}
+	return fmt.Sprintf("%s run %s", packageManager, script)
+}
+
+// execBinary returns the command that runs a locally installed package binary
+func execBinary(packageManager, binary string) string {
+	switch packageManager {
+	case "pnpm":
+		return "pnpm exec " + binary
+	case "yarn":
+		return "yarn " + binary
+	case "bun":
+		return "bunx " + binary
+	default:
+		return "npx " + binary
+	}
+}
+
+// frameworkBinaries maps frameworks to the CLI that builds them
+var frameworkBinaries = map[string]string{
+	FrameworkVite:      "vite",
+	FrameworkNext:      "next",
+	FrameworkCRA:       "react-scripts",
+	FrameworkAngular:   "ng",
+	FrameworkSvelteKit: "vite",
+	FrameworkAstro:     "astro",
+}
+
+// jsExtensions are the extensions tried for JavaScript framework config files
+var jsExtensions = []string{".js", ".mjs", ".cjs", ".ts", ".mts"}
+
+var (
+	nextExportPattern  = regexp.MustCompile(`output\s*:\s*['"]export['"]`)
+	outDirPattern      = regexp.MustCompile(`outDir\s*:\s*['"]([^'"]+)['"]`)
+	sveltePagesPattern = regexp.MustCompile(`pages\s*:\s*['"]([^'"]+)['"]`)
+)
+
+// appName proposes an app name from package.json or the directory name
+func appName(dir string, pkg *PackageJSON) string {
+	if pkg != nil && pkg.Name != "" {
+		name := pkg.Name
+		// Drop the npm scope: @acme/web -> web
+		if i := strings.LastIndex(name, "/"); i >= 0 {
+			name = name[i+1:]
+		}
+		if name != "" {
+			return name
+		}
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return filepath.Base(dir)
+	}
+	return filepath.Base(abs)
+}
+
+// isHugo reports whether dir looks like a Hugo site
+func isHugo(dir string) bool {
+	for _, name := range []string{"hugo.toml", "hugo.yaml", "hugo.json"} {
+		if fileExists(filepath.Join(dir, name)) {
+			return true
+		}
+	}
+	// Older sites use config.toml alongside the standard content/layouts dirs
+	if fileExists(filepath.Join(dir, "config.toml")) {
+		return dirExists(filepath.Join(dir, "content")) || dirExists(filepath.Join(dir, "layouts")) || dirExists(filepath.Join(dir, "themes"))
+	}
+	return false
+}
+
+// angularOutputDir reads the build output path of the first project in angular.json
+func angularOutputDir(dir string) string {
+	data, err := os.ReadFile(filepath.Join(dir, "angular.json"))
+	if err != nil {
+		return "dist"
+	}
+
+	var workspace struct {
+		DefaultProject string `json:"defaultProject"`
+		Projects       map[string]struct {
+			ProjectType string `json:"projectType"`
+			Architect   struct {
+				Build struct {
+					Builder string `json:"builder"`
+					Options struct {
+						OutputPath json.RawMessage `json:"outputPath"`
+					} `json:"options"`
+				} `json:"build"`
+			} `json:"architect"`
+		} `json:"projects"`
+	}
+	if err := json.Unmarshal(data, &workspace); err != nil {
+		return "dist"
+	}
+
+	name := workspace.DefaultProject
+	if _, ok := workspace.Projects[name]; !ok {
+		name = ""
+		for projectName, project := range workspace.Projects {
+			if project.ProjectType == "application" && (name == "" || projectName < name) {
+				name = projectName
+			}
+		}
+	}
+	project, ok := workspace.Projects[name]
+	if !ok {
+		return "dist"
+	}
+
+	build := project.Architect.Build
+	outputPath := filepath.Join("dist", name)
+	var pathString string
+	var pathObject struct {
+		Base    string `json:"base"`
+		Browser string `json:"browser"`
+	}
+	switch {
+	case json.Unmarshal(build.Options.OutputPath, &pathString) == nil && pathString != "":
+		outputPath = pathString
+	case json.Unmarshal(build.Options.OutputPath, &pathObject) == nil && pathObject.Base != "":
+		browser := pathObject.Browser
+		if browser == "" {
+			browser = "browser"
+		}
+		return filepath.Join(pathObject.Base, browser)
+	}
+
+	// The application builder (Angular 17+) writes the browser bundle to a subdirectory
+	if strings.HasSuffix(build.Builder, ":application") {
+		return filepath.Join(outputPath, "browser")
+	}
+	return outputPath
+}
+
+// findFile returns the first existing dir/base+ext, or "" if none exists
+func findFile(dir, base string, extensions []string) string {
+	for _, ext := range extensions {
+		path := filepath.Join(dir, base+ext)
+		if fileExists(path) {
+			return path
+		}
+	}
+	return ""
+}
+
+// fileMatches reports whether the file's contents match pattern
+func fileMatches(path string, pattern *regexp.Regexp) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	return pattern.Match(data)
+}
+
+// fileSubmatch returns the first capture group of pattern in the file, or ""
+func fileSubmatch(path string, pattern *regexp.Regexp) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	match := pattern.FindSubmatch(data)
+	if match == nil {
+		return ""
+	}
+	return string(match[1])
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+func dirExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
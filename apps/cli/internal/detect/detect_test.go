@@ -0,0 +1,226 @@
+package detect
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeFiles creates the given files (path -> contents) under dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+}
+
+// TestDetect tests framework detection for each supported project layout
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name          string
+		files         map[string]string
+		wantFramework string
+		wantAppName   string
+		wantBuild     string
+		wantOutput    string
+		wantWarnings  int
+	}{
+		{
+			name: "vite with pnpm",
+			files: map[string]string{
+				"package.json":   `{"name":"@acme/web","scripts":{"build":"vite build"},"devDependencies":{"vite":"^5.0.0"}}`,
+				"pnpm-lock.yaml": "",
+				"vite.config.ts": "export default { build: { outDir: 'public-dist' } }",
+			},
+			wantFramework: FrameworkVite,
+			wantAppName:   "web",
+			wantBuild:     "pnpm run build",
+			wantOutput:    "public-dist",
+		},
+		{
+			name: "next static export",
+			files: map[string]string{
+				"package.json":   `{"name":"site","scripts":{"build":"next build"},"dependencies":{"next":"14.0.0"}}`,
+				"next.config.js": "module.exports = { output: 'export' }",
+			},
+			wantFramework: FrameworkNext,
+			wantAppName:   "site",
+			wantBuild:     "npm run build",
+			wantOutput:    "out",
+		},
+		{
+			name: "next without static export",
+			files: map[string]string{
+				"package.json": `{"name":"site","dependencies":{"next":"14.0.0"}}`,
+				"yarn.lock":    "",
+			},
+			wantFramework: FrameworkNext,
+			wantAppName:   "site",
+			wantBuild:     "yarn next build",
+			wantOutput:    "out",
+			wantWarnings:  1,
+		},
+		{
+			name: "create react app",
+			files: map[string]string{
+				"package.json":      `{"name":"cra","scripts":{"build":"react-scripts build"},"dependencies":{"react-scripts":"5.0.1"}}`,
+				"package-lock.json": "{}",
+			},
+			wantFramework: FrameworkCRA,
+			wantAppName:   "cra",
+			wantBuild:     "npm run build",
+			wantOutput:    "build",
+		},
+		{
+			name: "angular application builder",
+			files: map[string]string{
+				"package.json": `{"name":"ng-app","scripts":{"build":"ng build"}}`,
+				"angular.json": `{"projects":{"ng-app":{"projectType":"application","architect":{"build":{"builder":"@angular-devkit/build-angular:application","options":{"outputPath":"dist/ng-app"}}}}}}`,
+			},
+			wantFramework: FrameworkAngular,
+			wantAppName:   "ng-app",
+			wantBuild:     "npm run build",
+			wantOutput:    "dist/ng-app/browser",
+		},
+		{
+			name: "sveltekit static",
+			files: map[string]string{
+				"package.json":     `{"name":"kit","scripts":{"build":"vite build"},"devDependencies":{"@sveltejs/kit":"2.0.0","@sveltejs/adapter-static":"3.0.0","vite":"5.0.0"}}`,
+				"svelte.config.js": "adapter({ pages: 'static-out' })",
+			},
+			wantFramework: FrameworkSvelteKit,
+			wantAppName:   "kit",
+			wantBuild:     "npm run build",
+			wantOutput:    "static-out",
+		},
+		{
+			name: "sveltekit without static adapter",
+			files: map[string]string{
+				"package.json": `{"name":"kit","scripts":{"build":"vite build"},"devDependencies":{"@sveltejs/kit":"2.0.0"}}`,
+			},
+			wantFramework: FrameworkSvelteKit,
+			wantAppName:   "kit",
+			wantBuild:     "npm run build",
+			wantOutput:    "build",
+			wantWarnings:  1,
+		},
+		{
+			name: "astro",
+			files: map[string]string{
+				"package.json":     `{"name":"blog","scripts":{"build":"astro build"},"dependencies":{"astro":"4.0.0"}}`,
+				"bun.lockb":        "",
+				"astro.config.mjs": "export default {}",
+			},
+			wantFramework: FrameworkAstro,
+			wantAppName:   "blog",
+			wantBuild:     "bun run build",
+			wantOutput:    "dist",
+		},
+		{
+			name: "hugo",
+			files: map[string]string{
+				"hugo.toml":         "title = 'docs'",
+				"content/_index.md": "# Hello",
+			},
+			wantFramework: FrameworkHugo,
+			wantBuild:     "hugo --minify",
+			wantOutput:    "public",
+		},
+		{
+			name:       "unknown project",
+			files:      map[string]string{"index.html": "<html></html>"},
+			wantOutput: "dist",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tt.files)
+
+			result, err := Detect(dir)
+			if err != nil {
+				t.Fatalf("Detect failed: %v", err)
+			}
+
+			if result.Framework != tt.wantFramework {
+				t.Errorf("Expected framework %q, got %q", tt.wantFramework, result.Framework)
+			}
+			if tt.wantAppName != "" && result.AppName != tt.wantAppName {
+				t.Errorf("Expected app name %q, got %q", tt.wantAppName, result.AppName)
+			}
+			if result.BuildCommand != tt.wantBuild {
+				t.Errorf("Expected build command %q, got %q", tt.wantBuild, result.BuildCommand)
+			}
+			if result.OutputDir != tt.wantOutput {
+				t.Errorf("Expected output dir %q, got %q", tt.wantOutput, result.OutputDir)
+			}
+			if len(result.Warnings) != tt.wantWarnings {
+				t.Errorf("Expected %d warnings, got %v", tt.wantWarnings, result.Warnings)
+			}
+		})
+	}
+}
+
+// TestDetectAppNameFallsBackToDirectory tests that the directory name is used without package.json
+func TestDetectAppNameFallsBackToDirectory(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "my-site")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	result, err := Detect(dir)
+	if err != nil {
+		t.Fatalf("Detect failed: %v", err)
+	}
+	if result.AppName != "my-site" {
+		t.Fatalf("Expected app name 'my-site', got %q", result.AppName)
+	}
+}
+
+// TestDetectDescription tests that the package.json description is used,
+// falling back to the framework
+func TestDetectDescription(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{
+			name:  "package.json",
+			files: map[string]string{"package.json": `{"name":"web","description":"Marketing site","devDependencies":{"vite":"^5.0.0"}}`},
+			want:  "Marketing site",
+		},
+		{
+			name:  "framework",
+			files: map[string]string{"package.json": `{"name":"web","devDependencies":{"vite":"^5.0.0"}}`},
+			want:  "Vite app",
+		},
+		{
+			name:  "static site",
+			files: map[string]string{"index.html": "<h1>Home</h1>"},
+			want:  "Static site",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tt.files)
+
+			result, err := Detect(dir)
+			if err != nil {
+				t.Fatalf("Detect failed: %v", err)
+			}
+			if result.Description != tt.want {
+				t.Errorf("Expected description %q, got %q", tt.want, result.Description)
+			}
+		})
+	}
+}
@@ -0,0 +1 @@
+export default { output: 'export' };
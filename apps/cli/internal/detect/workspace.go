@@ -0,0 +1,397 @@
+package detect
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Workspace kinds reported by FindWorkspace
+const (
+	WorkspacePnpm = "pnpm"
+	WorkspaceNpm  = "npm"
+	WorkspaceYarn = "yarn"
+	WorkspaceNx   = "nx"
+)
+
+// Workspace describes a monorepo and the package directories it contains
+type Workspace struct {
+	// Kind is pnpm, npm, yarn or nx
+	Kind string
+	// Root is the absolute workspace root directory
+	Root string
+	// PackageManager is the package manager used at the root
+	PackageManager string
+	// Packages are the absolute package directories, sorted
+	Packages []string
+}
+
+// WorkspaceApp is a workspace package that looks like a deployable SPA
+type WorkspaceApp struct {
+	Result
+	// Dir is the package directory relative to the workspace root
+	Dir string
+	// WorkingDir is where BuildCommand runs, relative to the workspace root
+	WorkingDir string
+	// OutputPath is the build output relative to the workspace root
+	OutputPath string
+}
+
+// FindWorkspace detects a pnpm, npm, yarn or Nx workspace rooted at root.
+// It returns nil without error when root is not a workspace root.
+func FindWorkspace(root string) (*Workspace, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg, err := ReadPackageJSON(root)
+	if err != nil {
+		return nil, err
+	}
+
+	ws := &Workspace{Root: root, PackageManager: PackageManager(root)}
+	var patterns []string
+
+	switch {
+	case fileExists(filepath.Join(root, "pnpm-workspace.yaml")):
+		ws.Kind = WorkspacePnpm
+		ws.PackageManager = "pnpm"
+		patterns, err = pnpmWorkspacePatterns(filepath.Join(root, "pnpm-workspace.yaml"))
+		if err != nil {
+			return nil, err
+		}
+	case fileExists(filepath.Join(root, "nx.json")):
+		ws.Kind = WorkspaceNx
+		if pkg != nil {
+			patterns = packageJSONWorkspacePatterns(pkg)
+		}
+		if len(patterns) == 0 {
+			patterns = nxProjectPatterns(root)
+		}
+	case pkg != nil && len(packageJSONWorkspacePatterns(pkg)) > 0:
+		ws.Kind = WorkspaceNpm
+		if ws.PackageManager == "yarn" {
+			ws.Kind = WorkspaceYarn
+		}
+		patterns = packageJSONWorkspacePatterns(pkg)
+	default:
+		return nil, nil
+	}
+
+	ws.Packages, err = expandPatterns(root, patterns, ws.Kind == WorkspaceNx)
+	if err != nil {
+		return nil, err
+	}
+	return ws, nil
+}
+
+// DiscoverApps returns the workspace packages that look like deployable SPAs
+func (ws *Workspace) DiscoverApps() ([]WorkspaceApp, error) {
+	var apps []WorkspaceApp
+	for _, dir := range ws.Packages {
+		rel, err := filepath.Rel(ws.Root, dir)
+		if err != nil {
+			return nil, err
+		}
+
+		// Nx projects describe their own build target
+		if ws.Kind == WorkspaceNx {
+			if app, ok := ws.nxApp(dir, rel); ok {
+				apps = append(apps, app)
+				continue
+			}
+		}
+
+		result, err := detect(dir, ws.PackageManager)
+		if err != nil {
+			return nil, fmt.Errorf("failed to inspect %s: %w", rel, err)
+		}
+		if result.Framework == "" {
+			continue
+		}
+
+		apps = append(apps, WorkspaceApp{
+			Result:     *result,
+			Dir:        filepath.ToSlash(rel),
+			WorkingDir: filepath.ToSlash(rel),
+			OutputPath: filepath.ToSlash(filepath.Join(rel, result.OutputDir)),
+		})
+	}
+	return apps, nil
+}
+
+// nxApp builds a WorkspaceApp from an Nx project.json application
+func (ws *Workspace) nxApp(dir, rel string) (WorkspaceApp, bool) {
+	data, err := os.ReadFile(filepath.Join(dir, "project.json"))
+	if err != nil {
+		return WorkspaceApp{}, false
+	}
+
+	var project struct {
+		Name        string `json:"name"`
+		ProjectType string `json:"projectType"`
+		Targets     struct {
+			Build *struct {
+				Options struct {
+					OutputPath string `json:"outputPath"`
+				} `json:"options"`
+			} `json:"build"`
+		} `json:"targets"`
+	}
+	if err := json.Unmarshal(data, &project); err != nil {
+		return WorkspaceApp{}, false
+	}
+	// Only applications with a build target produce something to deploy
+	if project.ProjectType != "application" || project.Targets.Build == nil {
+		return WorkspaceApp{}, false
+	}
+
+	name := project.Name
+	if name == "" {
+		name = filepath.Base(dir)
+	}
+
+	// Pick up the framework from the project itself when it has one
+	result, err := detect(dir, ws.PackageManager)
+	if err != nil {
+		return WorkspaceApp{}, false
+	}
+	result.AppName = name
+	if result.Framework == "" {
+		result.Framework = "Nx"
+	}
+	result.BuildCommand = execBinary(ws.PackageManager, "nx") + " build " + name
+
+	// Nx output paths are relative to the workspace root
+	outputPath := project.Targets.Build.Options.OutputPath
+	if outputPath == "" {
+		outputPath = filepath.Join("dist", rel)
+	}
+
+	return WorkspaceApp{
+		Result:     *result,
+		Dir:        filepath.ToSlash(rel),
+		WorkingDir: ".",
+		OutputPath: filepath.ToSlash(filepath.Clean(outputPath)),
+	}, true
+}
+
+// packageJSONWorkspacePatterns reads the npm/yarn "workspaces" field, which
+// is either a list or an object with a "packages" list
+func packageJSONWorkspacePatterns(pkg *PackageJSON) []string {
+	if len(pkg.Workspaces) == 0 {
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(pkg.Workspaces, &list); err == nil {
+		return list
+	}
+
+	var object struct {
+		Packages []string `json:"packages"`
+	}
+	if err := json.Unmarshal(pkg.Workspaces, &object); err == nil {
+		return object.Packages
+	}
+	return nil
+}
+
+// pnpmWorkspacePatterns reads the packages list from pnpm-workspace.yaml.
+// Only the simple block-list form pnpm documents is supported.
+func pnpmWorkspacePatterns(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pnpm-workspace.yaml: %w", err)
+	}
+
+	var patterns []string
+	inPackages := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+
+		// A new top-level key ends the packages list
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") && !strings.HasPrefix(trimmed, "-") {
+			inPackages = strings.HasPrefix(trimmed, "packages:")
+			continue
+		}
+
+		if inPackages && strings.HasPrefix(trimmed, "-") {
+			pattern := strings.TrimSpace(strings.TrimPrefix(trimmed, "-"))
+			pattern = strings.Trim(pattern, `'"`)
+			if pattern != "" {
+				patterns = append(patterns, pattern)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to parse pnpm-workspace.yaml: %w", err)
+	}
+	return patterns, nil
+}
+
+// nxProjectPatterns returns the directories Nx looks in for projects
+func nxProjectPatterns(root string) []string {
+	appsDir, libsDir := "apps", "libs"
+
+	data, err := os.ReadFile(filepath.Join(root, "nx.json"))
+	if err == nil {
+		var nx struct {
+			WorkspaceLayout struct {
+				AppsDir string `json:"appsDir"`
+				LibsDir string `json:"libsDir"`
+			} `json:"workspaceLayout"`
+		}
+		if json.Unmarshal(data, &nx) == nil {
+			if nx.WorkspaceLayout.AppsDir != "" {
+				appsDir = nx.WorkspaceLayout.AppsDir
+			}
+			if nx.WorkspaceLayout.LibsDir != "" {
+				libsDir = nx.WorkspaceLayout.LibsDir
+			}
+		}
+	}
+
+	return []string{appsDir + "/**", libsDir + "/**"}
+}
+
+// expandPatterns resolves workspace globs to package directories. A
+// directory is a package if it has a package.json (or a project.json when
+// nxProjects is set). Patterns starting with "!" exclude matches.
+func expandPatterns(root string, patterns []string, nxProjects bool) ([]string, error) {
+	included := map[string]bool{}
+	var excludes []string
+
+	for _, pattern := range patterns {
+		if strings.HasPrefix(pattern, "!") {
+			excludes = append(excludes, strings.TrimPrefix(pattern, "!"))
+			continue
+		}
+
+		matches, err := globDirs(root, pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, dir := range matches {
+			if fileExists(filepath.Join(dir, "package.json")) || (nxProjects && fileExists(filepath.Join(dir, "project.json"))) {
+				included[dir] = true
+			}
+		}
+	}
+
+	var packages []string
+	for dir := range included {
+		rel, err := filepath.Rel(root, dir)
+		if err != nil {
+			return nil, err
+		}
+		if !matchesAny(filepath.ToSlash(rel), excludes) {
+			packages = append(packages, dir)
+		}
+	}
+	sort.Strings(packages)
+	return packages, nil
+}
+
+// globDirs expands a workspace glob to directories. "*" matches within a path
+// segment and a trailing or embedded "**" matches any depth.
+func globDirs(root, pattern string) ([]string, error) {
+	pattern = strings.TrimSuffix(strings.TrimPrefix(filepath.ToSlash(pattern), "./"), "/")
+
+	if !strings.Contains(pattern, "**") {
+		matches, err := filepath.Glob(filepath.Join(root, filepath.FromSlash(pattern)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid workspace pattern '%s': %w", pattern, err)
+		}
+		var dirs []string
+		for _, match := range matches {
+			if dirExists(match) {
+				dirs = append(dirs, match)
+			}
+		}
+		return dirs, nil
+	}
+
+	// Walk from the static prefix and match the remainder
+	prefix := pattern[:strings.Index(pattern, "**")]
+	base := filepath.Join(root, filepath.FromSlash(strings.TrimSuffix(prefix, "/")))
+	if !dirExists(base) {
+		return nil, nil
+	}
+
+	var dirs []string
+	err := filepath.WalkDir(base, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		if name := entry.Name(); path != base && (name == "node_modules" || strings.HasPrefix(name, ".")) {
+			return filepath.SkipDir
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if matchGlob(pattern, filepath.ToSlash(rel)) {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand workspace pattern '%s': %w", pattern, err)
+	}
+	return dirs, nil
+}
+
+// matchesAny reports whether rel matches any of the patterns
+func matchesAny(rel string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matchGlob(strings.TrimPrefix(pattern, "./"), rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob matches a slash-separated path against a pattern where "**"
+// matches zero or more segments
+func matchGlob(pattern, path string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(path, "/"))
+}
+
+func matchSegments(pattern, path []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(path); i++ {
+				if matchSegments(pattern[1:], path[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(path) == 0 {
+			return false
+		}
+		if ok, err := filepath.Match(pattern[0], path[0]); err != nil || !ok {
+			return false
+		}
+		pattern, path = pattern[1:], path[1:]
+	}
+	return len(path) == 0
+}
@@ -0,0 +1,130 @@
+package detect
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestDiscoverApps tests workspace discovery against the fixtures in testdata/workspaces
+func TestDiscoverApps(t *testing.T) {
+	type app struct {
+		Name       string
+		Framework  string
+		Build      string
+		WorkingDir string
+		OutputPath string
+	}
+
+	tests := []struct {
+		fixture  string
+		wantKind string
+		wantPkgs int
+		wantApps []app
+	}{
+		{
+			fixture:  "pnpm",
+			wantKind: WorkspacePnpm,
+			wantPkgs: 3, // apps/legacy is excluded by a negated pattern
+			wantApps: []app{
+				{"admin", FrameworkCRA, "pnpm run build", "apps/admin", "apps/admin/build"},
+				{"web", FrameworkVite, "pnpm run build", "apps/web", "apps/web/dist"},
+			},
+		},
+		{
+			fixture:  "npm",
+			wantKind: WorkspaceNpm,
+			wantPkgs: 3,
+			wantApps: []app{
+				{"docs", FrameworkNext, "npm run build", "apps/docs", "apps/docs/out"},
+				{"site", FrameworkAstro, "npm run build", "apps/site", "apps/site/dist"},
+			},
+		},
+		{
+			fixture:  "yarn",
+			wantKind: WorkspaceYarn,
+			wantPkgs: 3,
+			wantApps: []app{
+				{"dashboard", FrameworkAngular, "yarn run build", "frontend/dashboard", "frontend/dashboard/dist/dashboard"},
+				{"marketing", FrameworkSvelteKit, "yarn run build", "frontend/marketing", "frontend/marketing/build"},
+			},
+		},
+		{
+			fixture:  "nx",
+			wantKind: WorkspaceNx,
+			wantPkgs: 3,
+			wantApps: []app{
+				{"store", "Nx", "npx nx build store", ".", "dist/apps/store"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			ws, err := FindWorkspace(filepath.Join("testdata", "workspaces", tt.fixture))
+			if err != nil {
+				t.Fatalf("FindWorkspace failed: %v", err)
+			}
+			if ws == nil {
+				t.Fatal("Expected a workspace, got nil")
+			}
+			if ws.Kind != tt.wantKind {
+				t.Errorf("Expected workspace kind %q, got %q", tt.wantKind, ws.Kind)
+			}
+			if len(ws.Packages) != tt.wantPkgs {
+				t.Errorf("Expected %d packages, got %v", tt.wantPkgs, ws.Packages)
+			}
+
+			discovered, err := ws.DiscoverApps()
+			if err != nil {
+				t.Fatalf("DiscoverApps failed: %v", err)
+			}
+
+			var got []app
+			for _, a := range discovered {
+				got = append(got, app{a.AppName, a.Framework, a.BuildCommand, a.WorkingDir, a.OutputPath})
+			}
+			if !reflect.DeepEqual(got, tt.wantApps) {
+				t.Errorf("Expected apps %+v, got %+v", tt.wantApps, got)
+			}
+		})
+	}
+}
+
+// TestFindWorkspaceNotAWorkspace tests that a plain project is not reported as a workspace
+func TestFindWorkspaceNotAWorkspace(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"package.json": `{"name":"single","devDependencies":{"vite":"5.0.0"}}`,
+	})
+
+	ws, err := FindWorkspace(dir)
+	if err != nil {
+		t.Fatalf("FindWorkspace failed: %v", err)
+	}
+	if ws != nil {
+		t.Fatalf("Expected no workspace, got %+v", ws)
+	}
+}
+
+// TestMatchGlob tests the workspace glob matcher
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"apps/*", "apps/web", true},
+		{"apps/*", "apps/web/nested", false},
+		{"apps/**", "apps/web/nested", true},
+		{"apps/**", "apps", true},
+		{"**/test/**", "packages/ui/test/fixtures", true},
+		{"packages/*", "apps/web", false},
+	}
+
+	for _, tt := range tests {
+		if got := matchGlob(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
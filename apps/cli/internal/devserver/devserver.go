@@ -0,0 +1,179 @@
+// Package devserver implements `godeploy dev-server`, a local GoDeploy API
+// for working on the CLI or deploy scripts without the hosted service. It
+// serves the auth, project, deploy, deployment and log endpoints of
+// fakeapi.Server, stores uploaded archives on disk and serves the active
+// deployment of each project under /sites/<subdomain>/.
+package devserver
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/silvabyte/godeploy/internal/fakeapi"
+)
+
+// SitesPath is the path prefix deployed sites are served under
+const SitesPath = "/sites/"
+
+// Server is a local GoDeploy API. Accounts, projects and deployments are
+// kept in memory; archives are files in its directory.
+type Server struct {
+	// URL is the base URL of the server, set by Listen
+	URL string
+	// API is the API the server answers with, e.g. to create accounts
+	API *fakeapi.Server
+	// Logf, if set, is called for every request served
+	Logf func(format string, args ...any)
+
+	listener net.Listener
+}
+
+// New returns a server that stores archives in dir
+func New(dir string) (*Server, error) {
+	archiveDir := filepath.Join(dir, "archives")
+	if err := os.MkdirAll(archiveDir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create archive directory: %w", err)
+	}
+
+	s := &Server{API: fakeapi.New()}
+	s.API.ArchiveDir = archiveDir
+	s.API.SiteURL = func(subdomain string) string {
+		return s.URL + SitesPath + subdomain + "/"
+	}
+	return s, nil
+}
+
+// Listen listens on addr, e.g. "127.0.0.1:8787", and sets URL. Call it
+// before creating projects so their URLs point at the server.
+func (s *Server) Listen(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	s.listener = listener
+	s.URL = "http://" + listener.Addr().String()
+	s.API.URL = s.URL
+	return nil
+}
+
+// Serve answers requests until the context is done
+func (s *Server) Serve(ctx context.Context) error {
+	if s.listener == nil {
+		return errors.New("devserver: Serve called before Listen")
+	}
+	server := &http.Server{
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+		// Followed logs stream until their request is canceled
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	if err := server.Serve(s.listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// ServeHTTP answers an API request or a request for a deployed site
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	if site, ok := strings.CutPrefix(r.URL.Path, SitesPath); ok {
+		s.serveSite(recorder, r, site)
+	} else {
+		s.API.ServeHTTP(recorder, r)
+	}
+	if s.Logf != nil {
+		s.Logf("%s %s %d", r.Method, r.URL.Path, recorder.status)
+	}
+}
+
+// serveSite serves a file of the active deployment of a project. Paths
+// that aren't files get index.html, so client-side routes of SPAs work.
+func (s *Server) serveSite(w http.ResponseWriter, r *http.Request, site string) {
+	subdomain, name, found := strings.Cut(site, "/")
+	if !found {
+		http.Redirect(w, r, SitesPath+subdomain+"/", http.StatusMovedPermanently)
+		return
+	}
+	archive := s.API.SiteArchive(subdomain)
+	if archive == nil {
+		http.Error(w, fmt.Sprintf("No deployment of %s", subdomain), http.StatusNotFound)
+		return
+	}
+	files, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		http.Error(w, "Invalid archive", http.StatusInternalServerError)
+		return
+	}
+
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+	if !isFile(files, name) {
+		if index := path.Join(name, "index.html"); isFile(files, index) {
+			name = index
+		} else {
+			name = "index.html"
+		}
+	}
+	file, err := files.Open(name)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	info, err := file.Stat()
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	// Zip entries can't seek, which ServeContent needs for range requests
+	data, err := io.ReadAll(file)
+	if err != nil {
+		http.Error(w, "Invalid archive", http.StatusInternalServerError)
+		return
+	}
+	http.ServeContent(w, r, name, info.ModTime(), bytes.NewReader(data))
+}
+
+func isFile(files fs.FS, name string) bool {
+	if name == "" {
+		return false
+	}
+	info, err := fs.Stat(files, name)
+	return err == nil && !info.IsDir()
+}
+
+// statusRecorder remembers the status of a response for the request log
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap lets http.ResponseController flush streamed responses
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,151 @@
+package devserver
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/silvabyte/godeploy/pkg/godeploy"
+)
+
+// startServer runs a dev server with an account until the test ends and
+// returns an SDK client authenticated as it
+func startServer(t *testing.T) (*Server, *godeploy.Client) {
+	t.Helper()
+	server, err := New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := server.Listen("127.0.0.1:0"); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- server.Serve(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Serve failed: %v", err)
+		}
+	})
+
+	server.API.AddUser("dev@godeploy.test", "password")
+	token := server.API.CreateToken("dev@godeploy.test", "test")
+	return server, godeploy.New(godeploy.WithBaseURL(server.URL), godeploy.WithToken(token))
+}
+
+// zipFiles returns a zip archive of files by name
+func zipFiles(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(f, content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func get(t *testing.T, url string) (int, string) {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, string(body)
+}
+
+// TestDeployAndServe tests that a deployed archive is stored on disk and
+// served at the project's URL
+func TestDeployAndServe(t *testing.T) {
+	server, client := startServer(t)
+
+	deployment, err := client.Deploy(context.Background(), godeploy.DeployRequest{
+		Project: "demo",
+		Archive: zipFiles(t, map[string]string{
+			"index.html":      "<h1>Home</h1>",
+			"assets/app.js":   "console.log('app')",
+			"docs/index.html": "<h1>Docs</h1>",
+		}),
+	})
+	if err != nil {
+		t.Fatalf("Deploy failed: %v", err)
+	}
+	if !strings.HasPrefix(deployment.URL, server.URL+SitesPath+"demo-") {
+		t.Fatalf("Deployment URL %s isn't served by %s", deployment.URL, server.URL)
+	}
+	if _, err := os.Stat(filepath.Join(server.API.ArchiveDir, deployment.ID+".zip")); err != nil {
+		t.Fatalf("Archive wasn't stored: %v", err)
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"", "<h1>Home</h1>"},
+		{"assets/app.js", "console.log('app')"},
+		{"docs/", "<h1>Docs</h1>"},
+		{"settings/profile", "<h1>Home</h1>"},
+	}
+	for _, tt := range tests {
+		status, body := get(t, deployment.URL+tt.path)
+		if status != http.StatusOK || body != tt.want {
+			t.Errorf("GET /%s = %d %q, want %q", tt.path, status, body, tt.want)
+		}
+	}
+}
+
+// TestServeLatestDeployment tests that a new deployment replaces the
+// served files
+func TestServeLatestDeployment(t *testing.T) {
+	_, client := startServer(t)
+	ctx := context.Background()
+
+	var url string
+	for _, content := range []string{"v1", "v2"} {
+		deployment, err := client.Deploy(ctx, godeploy.DeployRequest{
+			Project: "demo",
+			Archive: zipFiles(t, map[string]string{"index.html": content}),
+		})
+		if err != nil {
+			t.Fatalf("Deploy failed: %v", err)
+		}
+		url = deployment.URL
+	}
+
+	if _, body := get(t, url); body != "v2" {
+		t.Fatalf("Served %q, want v2", body)
+	}
+}
+
+// TestServeUnknownSite tests that a site without deployments is not found
+func TestServeUnknownSite(t *testing.T) {
+	server, _ := startServer(t)
+
+	if status, _ := get(t, server.URL+SitesPath+"missing/"); status != http.StatusNotFound {
+		t.Fatalf("Status %d, want 404", status)
+	}
+}
@@ -0,0 +1,1292 @@
+// Package fakeapi is an in-memory GoDeploy API. Server implements the auth,
+// project, deploy, log and API token endpoints with the shapes the real API
+// uses. Endpoints it doesn't cover answer 501 like the ones the real API
+// hasn't implemented yet. The dev-server command serves it for offline
+// development, and apitest starts it for tests.
+package fakeapi
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/silvabyte/godeploy/internal/api"
+	"github.com/silvabyte/godeploy/pkg/godeploy"
+)
+
+// maxArchiveSize is the largest deploy upload the server accepts
+const maxArchiveSize = 100 << 20
+
+// Server is an in-memory GoDeploy API. It is safe for concurrent use.
+type Server struct {
+	// URL is the base URL of the server
+	URL string
+	// TokenTTL is how long access tokens are valid; 0 means an hour
+	TokenTTL time.Duration
+	// ArchiveDir is where uploaded archives are stored, as
+	// <deployment ID>.zip; empty keeps them in memory
+	ArchiveDir string
+	// SiteURL returns the URL of the project with a subdomain; nil means
+	// https://<subdomain>.spa.godeploy.app
+	SiteURL func(subdomain string) string
+
+	mux *http.ServeMux
+
+	mu          sync.Mutex
+	users       map[string]*user    // by email
+	sessions    map[string]*session // by access token
+	refresh     map[string]*session // by refresh token
+	tokens      []*apiToken
+	projects    []*godeploy.Project
+	deployments []*godeploy.Deployment // oldest first
+	logs        []logLine              // oldest first
+	archives    map[string][]byte      // by deployment ID, unless ArchiveDir is set
+	resets      map[string]string      // reset token to email
+	failures    map[string]failure     // by route
+	requests    []string
+	seq         int
+}
+
+type user struct {
+	id       string
+	email    string
+	password string
+	tenantID string
+}
+
+type session struct {
+	user         *user
+	accessToken  string
+	refreshToken string
+}
+
+type apiToken struct {
+	godeploy.APIToken
+	secret string
+	user   *user
+}
+
+// logLine is a deploy log entry of a project
+type logLine struct {
+	godeploy.LogEntry
+	projectID string
+}
+
+type failure struct {
+	status  int
+	message string
+}
+
+// New returns a server that isn't listening; serve it with an http.Server
+// and set URL to its address
+func New() *Server {
+	s := &Server{
+		mux:      http.NewServeMux(),
+		users:    map[string]*user{},
+		sessions: map[string]*session{},
+		refresh:  map[string]*session{},
+		archives: map[string][]byte{},
+		resets:   map[string]string{},
+		failures: map[string]failure{},
+	}
+	s.routes()
+	return s
+}
+
+// Client returns an API client for the server that doesn't retry. Like
+// api.NewClient, it uses the API token from --token or GODEPLOY_TOKEN and
+// otherwise the saved login.
+func (s *Server) Client() *api.Client {
+	client := api.NewClient()
+	client.BaseURL = s.URL
+	client.HTTPClient = &http.Client{Timeout: api.DefaultTimeout}
+	client.Retry = api.NoRetry
+	return client
+}
+
+// ServeHTTP answers an API request
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	_, route := s.mux.Handler(r)
+
+	s.mu.Lock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+	f, failing := s.failures[route]
+	s.mu.Unlock()
+
+	if failing {
+		writeError(w, f.status, f.message)
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// Fail makes every request to route, e.g. "POST /api/deploy", fail with
+// status and message
+func (s *Server) Fail(route string, status int, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures[route] = failure{status: status, message: message}
+}
+
+// Recover undoes Fail for route
+func (s *Server) Recover(route string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.failures, route)
+}
+
+// Requests returns the method and path of every request received, in order
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// AddUser creates an account
+func (s *Server) AddUser(email, password string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.addUser(email, password)
+}
+
+// Login starts a session for an account created with AddUser and returns
+// its tokens, e.g. to save them as the CLI's login
+func (s *Server) Login(email string) (accessToken, refreshToken string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u := s.users[email]
+	if u == nil {
+		panic(fmt.Sprintf("fakeapi: no user %s", email))
+	}
+	sess := s.newSession(u)
+	return sess.accessToken, sess.refreshToken
+}
+
+// CreateToken creates an API token for an account and returns its secret
+func (s *Server) CreateToken(email, name string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u := s.users[email]
+	if u == nil {
+		panic(fmt.Sprintf("fakeapi: no user %s", email))
+	}
+	return s.newToken(u, name, nil).secret
+}
+
+// AddProject creates a project owned by an account
+func (s *Server) AddProject(email, name string) godeploy.Project {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u := s.users[email]
+	if u == nil {
+		panic(fmt.Sprintf("fakeapi: no user %s", email))
+	}
+	return *s.newProject(u, name, "")
+}
+
+// RenameProject changes the name of a project, keeping its ID and URL
+func (s *Server) RenameProject(projectID, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, p := range s.projects {
+		if p.ID == projectID {
+			p.Name = name
+			p.UpdatedAt = time.Now().UTC()
+			return
+		}
+	}
+	panic(fmt.Sprintf("fakeapi: no project %s", projectID))
+}
+
+// AddDeployment records a deployment of a project with status, without an
+// archive
+func (s *Server) AddDeployment(projectID, status string) godeploy.Deployment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, p := range s.projects {
+		if p.ID == projectID {
+			now := time.Now().UTC()
+			deployment := &godeploy.Deployment{
+				ID:        s.newID("deploy"),
+				TenantID:  p.TenantID,
+				ProjectID: p.ID,
+				UserID:    p.OwnerID,
+				UserEmail: s.userEmail(p.OwnerID),
+				URL:       p.URL,
+				Status:    status,
+				CreatedAt: now,
+				UpdatedAt: now,
+			}
+			s.deployments = append(s.deployments, deployment)
+			return *deployment
+		}
+	}
+	panic(fmt.Sprintf("fakeapi: no project %s", projectID))
+}
+
+// SetDeploymentStatus changes the status of a deployment, e.g. to finish a
+// pending one
+func (s *Server) SetDeploymentStatus(deploymentID, status string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, d := range s.deployments {
+		if d.ID == deploymentID {
+			d.Status = status
+			d.UpdatedAt = time.Now().UTC()
+			return
+		}
+	}
+	panic(fmt.Sprintf("fakeapi: no deployment %s", deploymentID))
+}
+
+// AddLog appends a line to the deploy log of a deployment. level is
+// "debug", "info", "warn" or "error".
+func (s *Server) AddLog(deploymentID, level, message string) godeploy.LogEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, d := range s.deployments {
+		if d.ID == deploymentID {
+			return s.addLog(d, level, message)
+		}
+	}
+	panic(fmt.Sprintf("fakeapi: no deployment %s", deploymentID))
+}
+
+// Projects returns every project, oldest first
+func (s *Server) Projects() []godeploy.Project {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	projects := make([]godeploy.Project, len(s.projects))
+	for i, p := range s.projects {
+		projects[i] = *p
+	}
+	return projects
+}
+
+// Deployments returns the deployments of a project, oldest first
+func (s *Server) Deployments(projectID string) []godeploy.Deployment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var deployments []godeploy.Deployment
+	for _, d := range s.deployments {
+		if d.ProjectID == projectID {
+			deployments = append(deployments, *d)
+		}
+	}
+	return deployments
+}
+
+// Archive returns the uploaded archive of a deployment
+func (s *Server) Archive(deploymentID string) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.archive(deploymentID)
+}
+
+// SiteArchive returns the archive of the active deployment of the project
+// with a subdomain, or nil if it has none
+func (s *Server) SiteArchive(subdomain string) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, p := range s.projects {
+		if p.Subdomain == subdomain {
+			if active := s.activeDeployment(p.ID); active != nil {
+				return s.archive(active.ID)
+			}
+		}
+	}
+	return nil
+}
+
+// APITokens returns the API tokens that weren't revoked
+func (s *Server) APITokens() []godeploy.APIToken {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tokens := make([]godeploy.APIToken, len(s.tokens))
+	for i, t := range s.tokens {
+		tokens[i] = t.APIToken
+	}
+	return tokens
+}
+
+func (s *Server) routes() {
+	s.mux.HandleFunc("POST /api/auth/init", s.handleInit)
+	s.mux.HandleFunc("POST /api/auth/signin", s.handleSignIn)
+	s.mux.HandleFunc("POST /api/auth/signup", s.handleSignUp)
+	s.mux.HandleFunc("GET /api/auth/verify", s.handleVerify)
+	s.mux.HandleFunc("POST /api/auth/refresh", s.handleRefresh)
+	s.mux.HandleFunc("POST /api/auth/signout", s.handleSignOut)
+	s.mux.HandleFunc("POST /api/auth/change-password", s.handleChangePassword)
+	s.mux.HandleFunc("POST /api/auth/reset-password", s.handleResetPassword)
+	s.mux.HandleFunc("POST /api/auth/reset-password/confirm", s.handleConfirmReset)
+
+	s.mux.HandleFunc("GET /api/projects", s.authed(s.handleListProjects))
+	s.mux.HandleFunc("POST /api/projects", s.authed(s.handleCreateProject))
+	s.mux.HandleFunc("DELETE /api/projects/{id}", s.authed(s.handleDeleteProject))
+	s.mux.HandleFunc("PATCH /api/projects/{id}/domain", s.authed(s.handleSetDomain))
+	s.mux.HandleFunc("GET /api/projects/{id}/status", s.authed(s.handleProjectStatus))
+	s.mux.HandleFunc("GET /api/projects/{id}/deployments", s.authed(s.handleListDeployments))
+	s.mux.HandleFunc("GET /api/projects/{id}/logs", s.authed(s.handleProjectLogs))
+	s.mux.HandleFunc("POST /api/projects/{id}/rollback", s.authed(s.handleRollback))
+	s.mux.HandleFunc("POST /api/deploy", s.authed(s.handleDeploy))
+	s.mux.HandleFunc("GET /api/deploys/{id}", s.authed(s.handleGetDeployment))
+
+	s.mux.HandleFunc("GET /api/tokens", s.authed(s.handleListTokens))
+	s.mux.HandleFunc("POST /api/tokens", s.authed(s.handleCreateToken))
+	s.mux.HandleFunc("GET /api/tokens/{id}", s.authed(s.handleGetToken))
+	s.mux.HandleFunc("DELETE /api/tokens/{id}", s.authed(s.handleRevokeToken))
+
+	s.mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	s.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotImplemented, "Not implemented")
+	})
+}
+
+// authed wraps a handler of an endpoint that needs an access or API token.
+// It is called with the server locked.
+func (s *Server) authed(handler func(w http.ResponseWriter, r *http.Request, u *user)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		u := s.authenticate(r)
+		if u == nil {
+			writeError(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+		handler(w, r, u)
+	}
+}
+
+// authenticate returns the user of the request's bearer token, or nil
+func (s *Server) authenticate(r *http.Request) *user {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return nil
+	}
+	if sess := s.sessions[token]; sess != nil {
+		if tokenExpired(token) {
+			return nil
+		}
+		return sess.user
+	}
+	for _, t := range s.tokens {
+		if t.secret == token {
+			if t.ExpiresAt != nil && time.Now().After(*t.ExpiresAt) {
+				return nil
+			}
+			now := time.Now().UTC()
+			t.LastUsedAt = &now
+			return t.user
+		}
+	}
+	return nil
+}
+
+func (s *Server) handleInit(w http.ResponseWriter, r *http.Request) {
+	var req api.AuthInitRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	if req.Email == "" {
+		writeError(w, http.StatusBadRequest, "Email is required")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"success": true, "message": "Check your email for the login link"})
+}
+
+func (s *Server) handleSignIn(w http.ResponseWriter, r *http.Request) {
+	var req api.SignInRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u := s.users[req.Email]
+	if u == nil || u.password != req.Password {
+		writeError(w, http.StatusUnauthorized, "Invalid email or password")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.newSession(u).response())
+}
+
+func (s *Server) handleSignUp(w http.ResponseWriter, r *http.Request) {
+	var req api.SignUpRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	if req.Email == "" || len(req.Password) < 8 {
+		writeError(w, http.StatusBadRequest, "Email and a password of at least 8 characters are required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.users[req.Email] != nil {
+		writeError(w, http.StatusBadRequest, "User already registered")
+		return
+	}
+	u := s.addUser(req.Email, req.Password)
+	writeJSON(w, http.StatusCreated, s.newSession(u).response())
+}
+
+func (s *Server) handleVerify(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var resp api.VerifyResponse
+	u := s.authenticate(r)
+	if u == nil {
+		resp.Error = "Invalid token"
+		writeJSON(w, http.StatusUnauthorized, resp)
+		return
+	}
+	resp.Valid = true
+	resp.User.ID, resp.User.Email, resp.User.TenantID = u.id, u.email, u.tenantID
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleRefresh(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old := s.refresh[req.RefreshToken]
+	if old == nil {
+		writeError(w, http.StatusUnauthorized, "Invalid refresh token")
+		return
+	}
+	// Refresh tokens are single-use
+	s.endSession(old)
+	sess := s.newSession(old.user)
+	writeJSON(w, http.StatusOK, api.RefreshResponse{Success: true, Token: sess.accessToken, RefreshToken: sess.refreshToken})
+}
+
+func (s *Server) handleSignOut(w http.ResponseWriter, r *http.Request) {
+	var req api.SignOutRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	sess := s.sessions[token]
+	if sess == nil || tokenExpired(token) {
+		writeError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	if req.Scope == "global" {
+		for _, other := range s.sessions {
+			if other.user == sess.user {
+				s.endSession(other)
+			}
+		}
+	} else {
+		s.endSession(sess)
+	}
+	writeJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
+
+func (s *Server) handleChangePassword(w http.ResponseWriter, r *http.Request) {
+	var req api.ChangePasswordRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u := s.authenticate(r)
+	if u == nil || u.password != req.CurrentPassword {
+		writeError(w, http.StatusUnauthorized, "Current password is incorrect")
+		return
+	}
+	if len(req.NewPassword) < 8 {
+		writeValidationError(w, "newPassword must be at least 8 characters")
+		return
+	}
+	u.password = req.NewPassword
+	writeJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
+
+func (s *Server) handleResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req api.ResetPasswordRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Unknown addresses get the same answer, so accounts can't be probed
+	if s.users[req.Email] != nil {
+		s.resets[s.newID("reset")] = req.Email
+	}
+	writeJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
+
+// ResetToken returns the token of the last password reset requested for an
+// account, as the reset email would carry it
+func (s *Server) ResetToken(email string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var last string
+	for token, e := range s.resets {
+		if e == email && token > last {
+			last = token
+		}
+	}
+	return last
+}
+
+func (s *Server) handleConfirmReset(w http.ResponseWriter, r *http.Request) {
+	var req api.ResetPasswordConfirmRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	if len(req.NewPassword) < 8 {
+		writeValidationError(w, "newPassword must be at least 8 characters")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	email, ok := s.resets[req.Token]
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Invalid or expired reset token")
+		return
+	}
+	delete(s.resets, req.Token)
+	s.users[email].password = req.NewPassword
+	writeJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
+
+func (s *Server) handleListProjects(w http.ResponseWriter, r *http.Request, u *user) {
+	projects := []godeploy.Project{}
+	for _, p := range s.projects {
+		if p.TenantID == u.tenantID {
+			projects = append(projects, *p)
+		}
+	}
+	writeJSON(w, http.StatusOK, projects)
+}
+
+func (s *Server) handleCreateProject(w http.ResponseWriter, r *http.Request, u *user) {
+	var req godeploy.CreateProjectRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	name := strings.Trim(req.Name, "-")
+	if len(name) < 3 {
+		writeError(w, http.StatusBadRequest, "Project name is too short. Min 3 characters")
+		return
+	}
+	if s.projectByName(u, name) != nil {
+		writeError(w, http.StatusConflict, fmt.Sprintf("Project '%s' already exists", name))
+		return
+	}
+	writeJSON(w, http.StatusCreated, s.newProject(u, name, req.Description))
+}
+
+func (s *Server) handleDeleteProject(w http.ResponseWriter, r *http.Request, u *user) {
+	project := s.project(w, r, u)
+	if project == nil {
+		return
+	}
+	for i, p := range s.projects {
+		if p == project {
+			s.projects = append(s.projects[:i], s.projects[i+1:]...)
+			break
+		}
+	}
+	kept := s.deployments[:0]
+	for _, d := range s.deployments {
+		if d.ProjectID == project.ID {
+			s.deleteArchive(d.ID)
+			continue
+		}
+		kept = append(kept, d)
+	}
+	s.deployments = kept
+	keptLogs := s.logs[:0]
+	for _, line := range s.logs {
+		if line.projectID != project.ID {
+			keptLogs = append(keptLogs, line)
+		}
+	}
+	s.logs = keptLogs
+	writeJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
+
+func (s *Server) handleSetDomain(w http.ResponseWriter, r *http.Request, u *user) {
+	project := s.project(w, r, u)
+	if project == nil {
+		return
+	}
+	var req struct {
+		Domain *string `json:"domain"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+	project.Domain = req.Domain
+	project.UpdatedAt = time.Now().UTC()
+	writeJSON(w, http.StatusOK, project)
+}
+
+func (s *Server) handleProjectStatus(w http.ResponseWriter, r *http.Request, u *user) {
+	project := s.project(w, r, u)
+	if project == nil {
+		return
+	}
+	status := godeploy.ProjectStatus{
+		Project:    *project,
+		Active:     s.activeDeployment(project.ID),
+		InProgress: s.pendingDeployment(project.ID),
+	}
+	if u, err := url.Parse(project.URL); err == nil {
+		status.Domains = []string{u.Host}
+	}
+	if project.Domain != nil {
+		status.Domains = append(status.Domains, *project.Domain)
+	}
+	if status.Active != nil {
+		status.Health = &godeploy.Health{Status: "healthy", StatusCode: http.StatusOK, CheckedAt: time.Now().UTC()}
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+func (s *Server) handleListDeployments(w http.ResponseWriter, r *http.Request, u *user) {
+	project := s.project(w, r, u)
+	if project == nil {
+		return
+	}
+	query := r.URL.Query()
+	limit := 20
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			writeValidationError(w, "limit must be a positive integer")
+			return
+		}
+		limit = n
+	}
+
+	var since, until time.Time
+	for name, t := range map[string]*time.Time{"since": &since, "until": &until} {
+		if v := query.Get(name); v != "" {
+			parsed, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				writeValidationError(w, name+" must be an RFC 3339 time")
+				return
+			}
+			*t = parsed
+		}
+	}
+	author := query.Get("author")
+
+	// Newest first; the cursor is the ID of the last deployment returned
+	var matching []godeploy.Deployment
+	for i := len(s.deployments) - 1; i >= 0; i-- {
+		d := s.deployments[i]
+		if d.ProjectID != project.ID ||
+			(query.Get("status") != "" && d.Status != query.Get("status")) ||
+			(query.Get("branch") != "" && (d.CommitBranch == nil || *d.CommitBranch != query.Get("branch"))) ||
+			(author != "" && d.UserID != author && (d.UserEmail == nil || *d.UserEmail != author)) ||
+			(!since.IsZero() && d.CreatedAt.Before(since)) ||
+			(!until.IsZero() && d.CreatedAt.After(until)) {
+			continue
+		}
+		matching = append(matching, *d)
+	}
+	if cursor := query.Get("cursor"); cursor != "" {
+		i := 0
+		for i < len(matching) && matching[i].ID != cursor {
+			i++
+		}
+		if i == len(matching) {
+			writeValidationError(w, "invalid cursor")
+			return
+		}
+		matching = matching[i+1:]
+	}
+
+	list := godeploy.DeploymentList{Deployments: matching}
+	if len(matching) > limit {
+		list.Deployments = matching[:limit]
+		list.NextCursor = matching[limit-1].ID
+	}
+	if list.Deployments == nil {
+		list.Deployments = []godeploy.Deployment{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// logFollowInterval is how often a followed log checks for new entries
+const logFollowInterval = 10 * time.Millisecond
+
+// handleProjectLogs answers a page of the deploy log after the cursor, the
+// last page with tail=true, or with follow=true streams it as server-sent
+// events until the deployment ends. The cursor of an entry is its ID.
+func (s *Server) handleProjectLogs(w http.ResponseWriter, r *http.Request, u *user) {
+	project := s.project(w, r, u)
+	if project == nil {
+		return
+	}
+	query := r.URL.Query()
+	limit := 100
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			writeValidationError(w, "limit must be a positive integer")
+			return
+		}
+		limit = n
+	}
+	deploymentID := query.Get("deployment_id")
+	cursor := query.Get("cursor")
+	if cursor == "" {
+		cursor = r.Header.Get("Last-Event-ID")
+	}
+	if cursor != "" && s.logIndex(cursor) < 0 {
+		writeValidationError(w, "invalid cursor")
+		return
+	}
+
+	if query.Get("follow") == "true" {
+		s.followLogs(w, r, project.ID, deploymentID, cursor)
+		return
+	}
+
+	entries := s.logsAfter(project.ID, deploymentID, cursor)
+	switch {
+	case len(entries) <= limit:
+	case query.Get("tail") == "true":
+		entries = entries[len(entries)-limit:]
+	default:
+		entries = entries[:limit]
+	}
+	list := godeploy.LogList{Entries: entries, NextCursor: cursor}
+	if len(entries) > 0 {
+		list.NextCursor = entries[len(entries)-1].Cursor
+	}
+	if list.Entries == nil {
+		list.Entries = []godeploy.LogEntry{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// followLogs streams the log entries after cursor as server-sent events
+// until the deployment with deploymentID, or without one every deployment
+// of the project, is no longer pending. It unlocks the server while
+// waiting for entries.
+func (s *Server) followLogs(w http.ResponseWriter, r *http.Request, projectID, deploymentID, cursor string) {
+	flusher := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	for {
+		for _, entry := range s.logsAfter(projectID, deploymentID, cursor) {
+			data, err := json.Marshal(entry)
+			if err != nil {
+				return
+			}
+			if _, err := fmt.Fprintf(w, "id: %s\nevent: log\ndata: %s\n\n", entry.Cursor, data); err != nil {
+				return
+			}
+			cursor = entry.Cursor
+		}
+		if !s.deploying(projectID, deploymentID) {
+			_, _ = io.WriteString(w, "event: end\ndata: {}\n\n")
+			_ = flusher.Flush()
+			return
+		}
+		if err := flusher.Flush(); err != nil {
+			return
+		}
+
+		s.mu.Unlock()
+		select {
+		case <-r.Context().Done():
+		case <-time.After(logFollowInterval):
+		}
+		s.mu.Lock()
+		if r.Context().Err() != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) handleRollback(w http.ResponseWriter, r *http.Request, u *user) {
+	project := s.project(w, r, u)
+	if project == nil {
+		return
+	}
+	var req struct {
+		DeploymentID string `json:"deployment_id"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+
+	var successful []*godeploy.Deployment
+	for _, d := range s.deployments {
+		if d.ProjectID == project.ID && d.Status == godeploy.StatusSuccess {
+			successful = append(successful, d)
+		}
+	}
+	var target *godeploy.Deployment
+	if req.DeploymentID == "" {
+		if len(successful) < 2 {
+			writeError(w, http.StatusConflict, "No earlier deployment to roll back to")
+			return
+		}
+		target = successful[len(successful)-2]
+	} else {
+		for _, d := range successful {
+			if d.ID == req.DeploymentID {
+				target = d
+			}
+		}
+		if target == nil {
+			writeError(w, http.StatusNotFound, "Deployment not found")
+			return
+		}
+	}
+
+	// Serving an earlier deployment again makes it the newest one
+	rollback := *target
+	rollback.ID = s.newID("deploy")
+	rollback.CreatedAt = time.Now().UTC()
+	rollback.UpdatedAt = rollback.CreatedAt
+	if err := s.storeArchive(rollback.ID, s.archive(target.ID)); err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to store archive")
+		return
+	}
+	s.deployments = append(s.deployments, &rollback)
+	writeJSON(w, http.StatusOK, rollback)
+}
+
+func (s *Server) handleDeploy(w http.ResponseWriter, r *http.Request, u *user) {
+	started := time.Now()
+	name := r.URL.Query().Get("project")
+	if name == "" {
+		writeError(w, http.StatusBadRequest, "Project name is required")
+		return
+	}
+	if err := r.ParseMultipartForm(maxArchiveSize); err != nil {
+		writeError(w, http.StatusBadRequest, "Failed to parse multipart form")
+		return
+	}
+	file, _, err := r.FormFile("archive")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "No files uploaded")
+		return
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	archive, err := io.ReadAll(file)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Failed to read archive")
+		return
+	}
+
+	project := s.projectByName(u, name)
+	if project == nil {
+		if len(name) < 3 {
+			writeError(w, http.StatusBadRequest, "Project name is too short. Min 3 characters")
+			return
+		}
+		project = s.newProject(u, name, "")
+	}
+
+	now := time.Now().UTC()
+	query := r.URL.Query()
+	deployment := &godeploy.Deployment{
+		ID:            s.newID("deploy"),
+		TenantID:      u.tenantID,
+		ProjectID:     project.ID,
+		UserID:        u.id,
+		UserEmail:     optional(u.email),
+		URL:           project.URL,
+		Status:        godeploy.StatusSuccess,
+		CommitSHA:     optional(query.Get("commit_sha")),
+		CommitBranch:  optional(query.Get("commit_branch")),
+		CommitMessage: optional(query.Get("commit_message")),
+		CommitURL:     optional(query.Get("commit_url")),
+		ArchiveSize:   int64(len(archive)),
+		DurationMS:    time.Since(started).Milliseconds(),
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := s.storeArchive(deployment.ID, archive); err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to store archive")
+		return
+	}
+	s.deployments = append(s.deployments, deployment)
+	s.addLog(deployment, "info", fmt.Sprintf("Received archive of %d bytes", len(archive)))
+	s.addLog(deployment, "info", "Deployed to "+deployment.URL)
+	writeJSON(w, http.StatusOK, deployment)
+}
+
+func (s *Server) handleGetDeployment(w http.ResponseWriter, r *http.Request, u *user) {
+	for _, d := range s.deployments {
+		if d.ID == r.PathValue("id") && d.TenantID == u.tenantID {
+			writeJSON(w, http.StatusOK, d)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "Deployment not found")
+}
+
+func (s *Server) handleListTokens(w http.ResponseWriter, r *http.Request, u *user) {
+	tokens := []godeploy.APIToken{}
+	for _, t := range s.tokens {
+		if t.user == u {
+			tokens = append(tokens, t.APIToken)
+		}
+	}
+	writeJSON(w, http.StatusOK, tokens)
+}
+
+func (s *Server) handleCreateToken(w http.ResponseWriter, r *http.Request, u *user) {
+	var req godeploy.CreateTokenRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		writeValidationError(w, "name is required")
+		return
+	}
+	t := s.newToken(u, req.Name, req.ExpiresAt)
+	writeJSON(w, http.StatusCreated, godeploy.CreatedToken{APIToken: t.APIToken, Token: t.secret})
+}
+
+func (s *Server) handleGetToken(w http.ResponseWriter, r *http.Request, u *user) {
+	for _, t := range s.tokens {
+		if t.ID == r.PathValue("id") && t.user == u {
+			writeJSON(w, http.StatusOK, t.APIToken)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "Token not found")
+}
+
+func (s *Server) handleRevokeToken(w http.ResponseWriter, r *http.Request, u *user) {
+	for i, t := range s.tokens {
+		if t.ID == r.PathValue("id") && t.user == u {
+			s.tokens = append(s.tokens[:i], s.tokens[i+1:]...)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "Token not found")
+}
+
+// project returns the project of the request's {id}, or writes a 404
+func (s *Server) project(w http.ResponseWriter, r *http.Request, u *user) *godeploy.Project {
+	for _, p := range s.projects {
+		if p.ID == r.PathValue("id") && p.TenantID == u.tenantID {
+			return p
+		}
+	}
+	writeError(w, http.StatusNotFound, "Project not found")
+	return nil
+}
+
+func (s *Server) projectByName(u *user, name string) *godeploy.Project {
+	for _, p := range s.projects {
+		if p.TenantID == u.tenantID && p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// activeDeployment returns the newest successful deployment of a project
+func (s *Server) activeDeployment(projectID string) *godeploy.Deployment {
+	for i := len(s.deployments) - 1; i >= 0; i-- {
+		if d := s.deployments[i]; d.ProjectID == projectID && d.Status == godeploy.StatusSuccess {
+			active := *d
+			return &active
+		}
+	}
+	return nil
+}
+
+// pendingDeployment returns the newest deployment of a project that hasn't
+// finished
+func (s *Server) pendingDeployment(projectID string) *godeploy.Deployment {
+	for i := len(s.deployments) - 1; i >= 0; i-- {
+		if d := s.deployments[i]; d.ProjectID == projectID && d.Status == godeploy.StatusPending {
+			pending := *d
+			return &pending
+		}
+	}
+	return nil
+}
+
+// deploying reports whether the deployment with deploymentID, or without
+// one any deployment of a project, is pending
+func (s *Server) deploying(projectID, deploymentID string) bool {
+	for _, d := range s.deployments {
+		if d.ProjectID == projectID && (deploymentID == "" || d.ID == deploymentID) && d.Status == godeploy.StatusPending {
+			return true
+		}
+	}
+	return false
+}
+
+// addLog appends a line to the deploy log of a deployment
+func (s *Server) addLog(d *godeploy.Deployment, level, message string) godeploy.LogEntry {
+	entry := godeploy.LogEntry{
+		Time:         time.Now().UTC(),
+		Level:        level,
+		Message:      message,
+		Source:       "deploy",
+		DeploymentID: d.ID,
+		Cursor:       s.newID("log"),
+	}
+	s.logs = append(s.logs, logLine{LogEntry: entry, projectID: d.ProjectID})
+	return entry
+}
+
+// logIndex returns the index of the log entry with a cursor, or -1
+func (s *Server) logIndex(cursor string) int {
+	for i, line := range s.logs {
+		if line.Cursor == cursor {
+			return i
+		}
+	}
+	return -1
+}
+
+// logsAfter returns the log entries of a project after cursor, oldest
+// first, limited to a deployment unless deploymentID is empty
+func (s *Server) logsAfter(projectID, deploymentID, cursor string) []godeploy.LogEntry {
+	var entries []godeploy.LogEntry
+	for _, line := range s.logs[s.logIndex(cursor)+1:] {
+		if line.projectID == projectID && (deploymentID == "" || line.DeploymentID == deploymentID) {
+			entries = append(entries, line.LogEntry)
+		}
+	}
+	return entries
+}
+
+// userEmail returns the email of the user with an ID
+func (s *Server) userEmail(userID string) *string {
+	for _, u := range s.users {
+		if u.id == userID {
+			return optional(u.email)
+		}
+	}
+	return nil
+}
+
+func (s *Server) addUser(email, password string) *user {
+	u := &user{
+		id:       s.newID("user"),
+		email:    email,
+		password: password,
+		tenantID: s.newID("tenant"),
+	}
+	s.users[email] = u
+	return u
+}
+
+func (s *Server) newSession(u *user) *session {
+	ttl := s.TokenTTL
+	if ttl == 0 {
+		ttl = time.Hour
+	}
+	sess := &session{
+		user:         u,
+		accessToken:  newJWT(s.newID("session"), time.Now().Add(ttl)),
+		refreshToken: "refresh-" + randomHex(),
+	}
+	s.sessions[sess.accessToken] = sess
+	s.refresh[sess.refreshToken] = sess
+	return sess
+}
+
+func (s *Server) endSession(sess *session) {
+	delete(s.sessions, sess.accessToken)
+	delete(s.refresh, sess.refreshToken)
+}
+
+func (s *Server) newProject(u *user, name, description string) *godeploy.Project {
+	now := time.Now().UTC()
+	subdomain := strings.ToLower(name) + "-" + randomHex()[:6]
+	project := &godeploy.Project{
+		ID:        s.newID("project"),
+		TenantID:  u.tenantID,
+		OwnerID:   u.id,
+		Name:      name,
+		Subdomain: subdomain,
+		URL:       "https://" + subdomain + ".spa.godeploy.app",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if s.SiteURL != nil {
+		project.URL = s.SiteURL(subdomain)
+	}
+	if description != "" {
+		project.Description = &description
+	}
+	s.projects = append(s.projects, project)
+	return project
+}
+
+// storeArchive saves the archive of a deployment
+func (s *Server) storeArchive(deploymentID string, archive []byte) error {
+	if s.ArchiveDir == "" {
+		s.archives[deploymentID] = archive
+		return nil
+	}
+	return os.WriteFile(s.archivePath(deploymentID), archive, 0o600)
+}
+
+// archive returns the archive of a deployment, or nil if it has none
+func (s *Server) archive(deploymentID string) []byte {
+	if s.ArchiveDir == "" {
+		return s.archives[deploymentID]
+	}
+	data, err := os.ReadFile(s.archivePath(deploymentID))
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+func (s *Server) deleteArchive(deploymentID string) {
+	if s.ArchiveDir == "" {
+		delete(s.archives, deploymentID)
+		return
+	}
+	_ = os.Remove(s.archivePath(deploymentID))
+}
+
+func (s *Server) archivePath(deploymentID string) string {
+	return filepath.Join(s.ArchiveDir, deploymentID+".zip")
+}
+
+func (s *Server) newToken(u *user, name string, expiresAt *time.Time) *apiToken {
+	secret := "gdp_" + randomHex()
+	t := &apiToken{
+		APIToken: godeploy.APIToken{
+			ID:        s.newID("token"),
+			Name:      name,
+			Prefix:    secret[:8],
+			CreatedAt: time.Now().UTC(),
+			ExpiresAt: expiresAt,
+		},
+		secret: secret,
+		user:   u,
+	}
+	s.tokens = append(s.tokens, t)
+	return t
+}
+
+// newID returns a unique ID that sorts in creation order
+func (s *Server) newID(kind string) string {
+	s.seq++
+	return fmt.Sprintf("%s-%06d", kind, s.seq)
+}
+
+func (sess *session) response() api.SignInResponse {
+	resp := api.SignInResponse{Success: true, Token: sess.accessToken, RefreshToken: sess.refreshToken}
+	resp.User.ID, resp.User.Email, resp.User.TenantID = sess.user.id, sess.user.email, sess.user.tenantID
+	return resp
+}
+
+// newJWT returns an unsigned JWT with the claims the CLI reads
+func newJWT(subject string, expires time.Time) string {
+	encode := func(v any) string {
+		data, _ := json.Marshal(v)
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	header := encode(map[string]string{"alg": "none", "typ": "JWT"})
+	claims := encode(map[string]any{"sub": subject, "exp": expires.Unix()})
+	return header + "." + claims + ".fakeapi"
+}
+
+// tokenExpired reports whether the exp claim of a JWT has passed
+func tokenExpired(token string) bool {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return true
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return true
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return true
+	}
+	return time.Now().After(time.Unix(claims.Exp, 0))
+}
+
+func randomHex() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func optional(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// decode reads a JSON request body into v, answering 400 when it can't
+func decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil && err != io.EOF {
+		writeValidationError(w, "body must be valid JSON")
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+// writeValidationError answers 400 the way the API's schema validation does
+func writeValidationError(w http.ResponseWriter, message string) {
+	writeJSON(w, http.StatusBadRequest, map[string]any{
+		"statusCode": http.StatusBadRequest,
+		"code":       "FST_ERR_VALIDATION",
+		"error":      http.StatusText(http.StatusBadRequest),
+		"message":    message,
+	})
+}
@@ -0,0 +1,134 @@
+// Package link manages the per-directory binding between a local checkout and
+// a remote GoDeploy project. The binding lives in .godeploy/project.json and
+// records the project ID, so it keeps working after the project is renamed.
+package link
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// DirName is the directory holding per-directory CLI state
+	DirName = ".godeploy"
+	// FileName is the binding file inside DirName
+	FileName = "project.json"
+)
+
+// ErrNotLinked is returned when no binding is found
+var ErrNotLinked = errors.New("directory is not linked to a project")
+
+// Binding associates a directory with a remote project
+type Binding struct {
+	// ProjectID is the remote project ID and the only field used to resolve it
+	ProjectID string `json:"project_id"`
+	// TenantID is the tenant that owns the project
+	TenantID string `json:"tenant_id"`
+	// ProjectName is the project name at link time, for display only
+	ProjectName string `json:"project_name,omitempty"`
+	// LinkedAt is when the binding was created
+	LinkedAt time.Time `json:"linked_at"`
+
+	// Path is the absolute path of the binding file (not serialized)
+	Path string `json:"-"`
+}
+
+// Dir returns the directory the binding applies to
+func (b *Binding) Dir() string {
+	return filepath.Dir(filepath.Dir(b.Path))
+}
+
+// FilePath returns the binding file path for dir
+func FilePath(dir string) string {
+	return filepath.Join(dir, DirName, FileName)
+}
+
+// Find looks for a binding in start and then each parent directory. It
+// returns ErrNotLinked when none is found.
+func Find(start string) (*Binding, error) {
+	dir, err := filepath.Abs(start)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		binding, err := Load(dir)
+		if err == nil {
+			return binding, nil
+		}
+		if !errors.Is(err, ErrNotLinked) {
+			return nil, err
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, ErrNotLinked
+		}
+		dir = parent
+	}
+}
+
+// Load reads the binding in dir. It returns ErrNotLinked when dir has none.
+func Load(dir string) (*Binding, error) {
+	path := FilePath(dir)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, ErrNotLinked
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var binding Binding
+	if err := json.Unmarshal(data, &binding); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if binding.ProjectID == "" {
+		return nil, fmt.Errorf("%s has no project_id; run 'godeploy link' again", path)
+	}
+
+	binding.Path, err = filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	return &binding, nil
+}
+
+// Save writes the binding to dir/.godeploy/project.json
+func Save(dir string, binding *Binding) error {
+	path := FilePath(dir)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+
+	data, err := json.MarshalIndent(binding, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal binding: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	binding.Path, err = filepath.Abs(path)
+	return err
+}
+
+// Remove deletes the binding in dir, and the .godeploy directory when it is
+// left empty. It returns ErrNotLinked when dir has no binding.
+func Remove(dir string) error {
+	path := FilePath(dir)
+	if err := os.Remove(path); err != nil {
+		if os.IsNotExist(err) {
+			return ErrNotLinked
+		}
+		return fmt.Errorf("failed to remove %s: %w", path, err)
+	}
+
+	// Keep the directory if anything else was stored in it
+	_ = os.Remove(filepath.Dir(path))
+	return nil
+}
@@ -0,0 +1,77 @@
+package link
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestSaveAndFind tests that a binding is found from a nested directory
+func TestSaveAndFind(t *testing.T) {
+	root := t.TempDir()
+	if err := Save(root, &Binding{ProjectID: "proj-123", TenantID: "tenant-1", ProjectName: "web"}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	nested := filepath.Join(root, "src", "components")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatalf("Failed to create nested dir: %v", err)
+	}
+
+	binding, err := Find(nested)
+	if err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+	if binding.ProjectID != "proj-123" || binding.TenantID != "tenant-1" {
+		t.Fatalf("Unexpected binding: %+v", binding)
+	}
+
+	// Compare resolved paths; the temp dir may sit behind a symlink
+	want, _ := filepath.EvalSymlinks(root)
+	got, _ := filepath.EvalSymlinks(binding.Dir())
+	if got != want {
+		t.Fatalf("Expected binding dir %s, got %s", want, got)
+	}
+}
+
+// TestFindNotLinked tests the error returned when no binding exists
+func TestFindNotLinked(t *testing.T) {
+	_, err := Find(t.TempDir())
+	if !errors.Is(err, ErrNotLinked) {
+		t.Fatalf("Expected ErrNotLinked, got %v", err)
+	}
+}
+
+// TestLoadRequiresProjectID tests that a binding without an ID is rejected
+func TestLoadRequiresProjectID(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, DirName), 0o755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(FilePath(dir), []byte(`{"project_name":"web"}`), 0o644); err != nil {
+		t.Fatalf("Failed to write binding: %v", err)
+	}
+
+	if _, err := Load(dir); err == nil || errors.Is(err, ErrNotLinked) {
+		t.Fatalf("Expected an invalid binding error, got %v", err)
+	}
+}
+
+// TestRemove tests that unlinking deletes the binding and the empty directory
+func TestRemove(t *testing.T) {
+	dir := t.TempDir()
+	if err := Save(dir, &Binding{ProjectID: "proj-123"}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	if err := Remove(dir); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, DirName)); !os.IsNotExist(err) {
+		t.Fatalf("Expected %s to be removed, got %v", DirName, err)
+	}
+	if err := Remove(dir); !errors.Is(err, ErrNotLinked) {
+		t.Fatalf("Expected ErrNotLinked on second remove, got %v", err)
+	}
+}
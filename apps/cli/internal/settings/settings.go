@@ -0,0 +1,425 @@
+// Package settings manages the CLI's user settings. Each setting is resolved
+// from a command-line flag, then its environment variable, then the settings
+// file in the config directory, then its default.
+package settings
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/silvabyte/godeploy/internal/paths"
+	"github.com/silvabyte/godeploy/pkg/godeploy"
+)
+
+// FileName is the settings file inside the config directory
+const FileName = "settings.json"
+
+// Setting names
+const (
+	APIURL         = "api-url"
+	DefaultProject = "default-project"
+	Output         = "output"
+	Color          = "color"
+	DeployTimeout  = "deploy-timeout"
+	Telemetry      = "telemetry"
+)
+
+// DefaultAPIURL is the API used when no other is configured
+const DefaultAPIURL = godeploy.DefaultBaseURL
+
+// Origin says where a resolved value came from
+type Origin string
+
+const (
+	OriginFlag    Origin = "flag"
+	OriginEnv     Origin = "env"
+	OriginFile    Origin = "file"
+	OriginDefault Origin = "default"
+)
+
+// ErrUnknownKey is returned for a setting name that doesn't exist
+var ErrUnknownKey = errors.New("unknown setting")
+
+// File is the content of the settings file. Empty fields are unset.
+type File struct {
+	APIURL         string `json:"api-url,omitempty"`
+	DefaultProject string `json:"default-project,omitempty"`
+	Output         string `json:"output,omitempty"`
+	Color          string `json:"color,omitempty"`
+	DeployTimeout  string `json:"deploy-timeout,omitempty"`
+	Telemetry      *bool  `json:"telemetry,omitempty"`
+}
+
+// Key describes a setting
+type Key struct {
+	Name string
+	// EnvVar overrides the settings file when set
+	EnvVar string
+	// Default is used when the setting is set nowhere
+	Default string
+	// Help describes the setting and its accepted values
+	Help string
+
+	normalize func(string) (string, error)
+	get       func(*File) string
+	set       func(*File, string)
+}
+
+// Value is a resolved setting
+type Value struct {
+	Key    *Key
+	Value  string
+	Origin Origin
+}
+
+// Keys lists every setting, in display order
+var Keys = []*Key{
+	{
+		Name:      APIURL,
+		EnvVar:    "GODEPLOY_API_URL",
+		Default:   DefaultAPIURL,
+		Help:      "API base URL (http or https); a profile's own API URL takes precedence over the file",
+		normalize: normalizeURL,
+		get:       func(f *File) string { return f.APIURL },
+		set:       func(f *File, v string) { f.APIURL = v },
+	},
+	{
+		Name:      DefaultProject,
+		EnvVar:    "GODEPLOY_PROJECT",
+		Help:      "Project used when a command gets no project and the directory isn't linked",
+		normalize: normalizeProject,
+		get:       func(f *File) string { return f.DefaultProject },
+		set:       func(f *File, v string) { f.DefaultProject = v },
+	},
+	{
+		Name:      Output,
+		EnvVar:    "GODEPLOY_OUTPUT",
+		Default:   "text",
+		Help:      "Output format of list commands: text or json",
+		normalize: oneOf("text", "json"),
+		get:       func(f *File) string { return f.Output },
+		set:       func(f *File, v string) { f.Output = v },
+	},
+	{
+		Name:      Color,
+		EnvVar:    "GODEPLOY_COLOR",
+		Default:   "auto",
+		Help:      "Colored output: auto, always or never",
+		normalize: oneOf("auto", "always", "never"),
+		get:       func(f *File) string { return f.Color },
+		set:       func(f *File, v string) { f.Color = v },
+	},
+	{
+		Name:      DeployTimeout,
+		EnvVar:    "GODEPLOY_DEPLOY_TIMEOUT",
+		Default:   "10m",
+		Help:      "Timeout of the deploy upload, e.g. 5m or 1h",
+		normalize: normalizeDuration,
+		get:       func(f *File) string { return f.DeployTimeout },
+		set:       func(f *File, v string) { f.DeployTimeout = v },
+	},
+	{
+		Name:      Telemetry,
+		EnvVar:    "GODEPLOY_TELEMETRY",
+		Default:   "false",
+		Help:      "Allow sending anonymous usage statistics: true or false",
+		normalize: normalizeBool,
+		get:       getTelemetry,
+		set:       setTelemetry,
+	},
+}
+
+// flags holds values given on the command line, by setting name
+var flags = map[string]string{}
+
+// Lookup returns the setting called name
+func Lookup(name string) (*Key, error) {
+	for _, key := range Keys {
+		if key.Name == name {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("%w '%s'; known settings: %s", ErrUnknownKey, name, strings.Join(Names(), ", "))
+}
+
+// Names returns the names of all settings
+func Names() []string {
+	names := make([]string, len(Keys))
+	for i, key := range Keys {
+		names[i] = key.Name
+	}
+	return names
+}
+
+// Normalize validates value for the setting and returns its canonical form
+func (k *Key) Normalize(value string) (string, error) {
+	if k.normalize == nil {
+		return value, nil
+	}
+	normalized, err := k.normalize(strings.TrimSpace(value))
+	if err != nil {
+		return "", fmt.Errorf("invalid value for %s: %w", k.Name, err)
+	}
+	return normalized, nil
+}
+
+// SetFlag records a value given on the command line, which takes precedence
+// over every other source for the rest of the process
+func SetFlag(name, value string) error {
+	key, err := Lookup(name)
+	if err != nil {
+		return err
+	}
+	normalized, err := key.Normalize(value)
+	if err != nil {
+		return err
+	}
+	flags[name] = normalized
+	return nil
+}
+
+// Get resolves the setting called name
+func Get(name string) (Value, error) {
+	key, err := Lookup(name)
+	if err != nil {
+		return Value{}, err
+	}
+	file, err := Load()
+	if err != nil {
+		return Value{Key: key, Value: key.Default, Origin: OriginDefault}, err
+	}
+	return resolve(key, file)
+}
+
+// List resolves every setting. Invalid values are reported in the error and
+// listed with their default.
+func List() ([]Value, error) {
+	file, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	values := make([]Value, 0, len(Keys))
+	var errs []error
+	for _, key := range Keys {
+		value, err := resolve(key, file)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		values = append(values, value)
+	}
+	return values, errors.Join(errs...)
+}
+
+// String returns the value of the setting called name, or its default when
+// the setting is invalid or can't be read
+func String(name string) string {
+	value, _ := Get(name)
+	if value.Key == nil {
+		return ""
+	}
+	return value.Value
+}
+
+// Duration returns the value of a duration setting, falling back to its
+// default when it is invalid or can't be read
+func Duration(name string) time.Duration {
+	value, _ := Get(name)
+	if value.Key == nil {
+		return 0
+	}
+	d, err := time.ParseDuration(value.Value)
+	if err != nil {
+		d, _ = time.ParseDuration(value.Key.Default)
+	}
+	return d
+}
+
+// Bool returns the value of a boolean setting, falling back to false when
+// it is invalid or can't be read
+func Bool(name string) bool {
+	b, _ := strconv.ParseBool(String(name))
+	return b
+}
+
+// resolve picks the value of key from the flag, the environment, file and
+// the default, in that order
+func resolve(key *Key, file *File) (Value, error) {
+	if value, ok := flags[key.Name]; ok {
+		return Value{Key: key, Value: value, Origin: OriginFlag}, nil
+	}
+	if raw := os.Getenv(key.EnvVar); key.EnvVar != "" && raw != "" {
+		value, err := key.Normalize(raw)
+		if err != nil {
+			return Value{Key: key, Value: key.Default, Origin: OriginDefault}, fmt.Errorf("%s: %w", key.EnvVar, err)
+		}
+		return Value{Key: key, Value: value, Origin: OriginEnv}, nil
+	}
+	if raw := key.get(file); raw != "" {
+		value, err := key.Normalize(raw)
+		if err != nil {
+			return Value{Key: key, Value: key.Default, Origin: OriginDefault}, fmt.Errorf("%s: %w", Path(), err)
+		}
+		return Value{Key: key, Value: value, Origin: OriginFile}, nil
+	}
+	return Value{Key: key, Value: key.Default, Origin: OriginDefault}, nil
+}
+
+// getTelemetry and setTelemetry store the telemetry setting as a JSON bool
+func getTelemetry(f *File) string {
+	if f.Telemetry == nil {
+		return ""
+	}
+	return strconv.FormatBool(*f.Telemetry)
+}
+
+func setTelemetry(f *File, value string) {
+	f.Telemetry = nil
+	if b, err := strconv.ParseBool(value); err == nil {
+		f.Telemetry = &b
+	}
+}
+
+// Set validates value and saves it in the settings file
+func Set(name, value string) error {
+	key, err := Lookup(name)
+	if err != nil {
+		return err
+	}
+	normalized, err := key.Normalize(value)
+	if err != nil {
+		return err
+	}
+	return update(func(file *File) {
+		key.set(file, normalized)
+	})
+}
+
+// Unset removes the setting called name from the settings file
+func Unset(name string) error {
+	key, err := Lookup(name)
+	if err != nil {
+		return err
+	}
+	return update(func(file *File) {
+		key.set(file, "")
+	})
+}
+
+// Path returns the path of the settings file
+func Path() string {
+	return filepath.Join(paths.GetConfigDir(), FileName)
+}
+
+// Load reads the settings file. A missing file has no settings.
+func Load() (*File, error) {
+	data, err := os.ReadFile(Path())
+	if errors.Is(err, os.ErrNotExist) {
+		return &File{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read settings: %w", err)
+	}
+
+	var file File
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", Path(), err)
+	}
+	return &file, nil
+}
+
+// update applies fn to the settings file and saves it
+func update(fn func(*File)) error {
+	file, err := Load()
+	if err != nil {
+		return err
+	}
+	fn(file)
+	return save(file)
+}
+
+// save writes the settings file atomically
+func save(file *File) error {
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode settings: %w", err)
+	}
+
+	path := Path()
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".settings-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to write settings: %w", err)
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write settings: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write settings: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write settings: %w", err)
+	}
+	return nil
+}
+
+func normalizeURL(value string) (string, error) {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("'%s' is not an http(s)://host URL", value)
+	}
+	return strings.TrimSuffix(u.String(), "/"), nil
+}
+
+func normalizeProject(value string) (string, error) {
+	if value == "" || strings.ContainsAny(value, " \t/") {
+		return "", fmt.Errorf("'%s' is not a project name or ID", value)
+	}
+	return value, nil
+}
+
+func normalizeDuration(value string) (string, error) {
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return "", fmt.Errorf("'%s' is not a positive duration such as 5m or 1h", value)
+	}
+	return value, nil
+}
+
+func normalizeBool(value string) (string, error) {
+	switch strings.ToLower(value) {
+	case "true", "on", "yes", "1":
+		return "true", nil
+	case "false", "off", "no", "0":
+		return "false", nil
+	}
+	return "", fmt.Errorf("'%s' is not true or false", value)
+}
+
+// oneOf accepts one of allowed, case-insensitively
+func oneOf(allowed ...string) func(string) (string, error) {
+	return func(value string) (string, error) {
+		lower := strings.ToLower(value)
+		for _, a := range allowed {
+			if lower == a {
+				return a, nil
+			}
+		}
+		sorted := append([]string(nil), allowed...)
+		sort.Strings(sorted)
+		return "", fmt.Errorf("'%s' must be one of %s", value, strings.Join(sorted, ", "))
+	}
+}
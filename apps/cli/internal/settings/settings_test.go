@@ -0,0 +1,156 @@
+package settings
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/silvabyte/godeploy/internal/paths"
+)
+
+// useTempConfigDir points the settings file at a temporary directory and
+// clears command-line values
+func useTempConfigDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	origGetConfigDir := paths.GetConfigDir
+	t.Cleanup(func() {
+		paths.GetConfigDir = origGetConfigDir
+		flags = map[string]string{}
+	})
+	paths.GetConfigDir = func() string { return dir }
+	flags = map[string]string{}
+	for _, key := range Keys {
+		t.Setenv(key.EnvVar, "")
+	}
+	return dir
+}
+
+// TestResolutionOrder tests that a flag beats the environment, which beats
+// the settings file, which beats the default
+func TestResolutionOrder(t *testing.T) {
+	useTempConfigDir(t)
+
+	check := func(wantValue string, wantOrigin Origin) {
+		t.Helper()
+		value, err := Get(Color)
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if value.Value != wantValue || value.Origin != wantOrigin {
+			t.Fatalf("Expected %s from %s, got %s from %s", wantValue, wantOrigin, value.Value, value.Origin)
+		}
+	}
+
+	check("auto", OriginDefault)
+
+	if err := Set(Color, "never"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	check("never", OriginFile)
+
+	t.Setenv("GODEPLOY_COLOR", "ALWAYS")
+	check("always", OriginEnv)
+
+	if err := SetFlag(Color, "auto"); err != nil {
+		t.Fatalf("SetFlag failed: %v", err)
+	}
+	check("auto", OriginFlag)
+}
+
+// TestSetValidates tests that invalid values are rejected and not saved
+func TestSetValidates(t *testing.T) {
+	useTempConfigDir(t)
+
+	tests := map[string]string{
+		APIURL:         "localhost:8080",
+		DefaultProject: "my app",
+		Output:         "yaml",
+		Color:          "sometimes",
+		DeployTimeout:  "-5m",
+		Telemetry:      "maybe",
+	}
+	for name, value := range tests {
+		if err := Set(name, value); err == nil {
+			t.Errorf("Expected %s=%s to be rejected", name, value)
+		}
+	}
+	if _, err := os.Stat(Path()); !os.IsNotExist(err) {
+		t.Fatalf("Expected no settings file after rejected values, got %v", err)
+	}
+
+	if err := Set("colour", "never"); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("Expected ErrUnknownKey, got %v", err)
+	}
+}
+
+// TestTypedValues tests that values are normalized and stored with their
+// JSON types
+func TestTypedValues(t *testing.T) {
+	useTempConfigDir(t)
+
+	if err := Set(APIURL, "https://api.example.com/"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Set(Telemetry, "on"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Set(DeployTimeout, "20m"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := String(APIURL); got != "https://api.example.com" {
+		t.Fatalf("Expected the trailing slash to be trimmed, got %s", got)
+	}
+	if !Bool(Telemetry) {
+		t.Fatal("Expected telemetry to be enabled")
+	}
+	if got := Duration(DeployTimeout); got != 20*time.Minute {
+		t.Fatalf("Expected 20m, got %s", got)
+	}
+
+	data, err := os.ReadFile(Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"telemetry": true`) {
+		t.Fatalf("Expected telemetry stored as a JSON bool, got %s", data)
+	}
+
+	if err := Unset(Telemetry); err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := Get(Telemetry); value.Origin != OriginDefault || value.Value != "false" {
+		t.Fatalf("Expected the default after unset, got %+v", value)
+	}
+}
+
+// TestInvalidValuesFallBack tests that a bad environment variable or a
+// hand-edited file falls back to the default and is reported
+func TestInvalidValuesFallBack(t *testing.T) {
+	dir := useTempConfigDir(t)
+
+	if err := os.WriteFile(filepath.Join(dir, FileName), []byte(`{"deploy-timeout": "soon"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if got := Duration(DeployTimeout); got != 10*time.Minute {
+		t.Fatalf("Expected the default timeout, got %s", got)
+	}
+
+	t.Setenv("GODEPLOY_OUTPUT", "xml")
+	values, err := List()
+	if err == nil {
+		t.Fatal("Expected List to report the invalid values")
+	}
+	if len(values) != len(Keys) {
+		t.Fatalf("Expected every setting to be listed, got %d", len(values))
+	}
+	for _, v := range values {
+		if (v.Key.Name == Output || v.Key.Name == DeployTimeout) && v.Origin != OriginDefault {
+			t.Fatalf("Expected %s to fall back to its default, got %+v", v.Key.Name, v)
+		}
+	}
+}
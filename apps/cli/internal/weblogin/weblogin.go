@@ -0,0 +1,171 @@
+// Package weblogin implements the browser side of `godeploy auth login --web`.
+// It listens on a random loopback port and waits for the magic link sent by
+// /api/auth/init to redirect the browser back to it with the session tokens.
+package weblogin
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html"
+	"net"
+	"net/http"
+	"time"
+)
+
+// CallbackPath is the path the authorization server redirects back to
+const CallbackPath = "/callback"
+
+// fragmentParam marks a callback whose URL fragment was moved into the
+// query by the page forwardFragment serves
+const fragmentParam = "fragment"
+
+// Callback holds the session the magic link redirected back with
+type Callback struct {
+	AccessToken  string
+	RefreshToken string
+}
+
+// Flow is one in-progress browser login
+type Flow struct {
+	// State guards against callbacks from other logins (CSRF)
+	State string
+
+	listener net.Listener
+	server   *http.Server
+	results  chan result
+}
+
+type result struct {
+	callback *Callback
+	err      error
+}
+
+// Start listens on a random loopback port and serves the callback
+func Start() (*Flow, error) {
+	state, err := randomString(16)
+	if err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to start login listener: %w", err)
+	}
+
+	f := &Flow{
+		State:    state,
+		listener: listener,
+		results:  make(chan result, 1),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(CallbackPath, f.handleCallback)
+	f.server = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		_ = f.server.Serve(listener)
+	}()
+
+	return f, nil
+}
+
+// RedirectURI is the loopback URL the magic link redirects to. The state is
+// part of it, as magic links can't pass a separate state parameter.
+func (f *Flow) RedirectURI() string {
+	return fmt.Sprintf("http://%s%s?state=%s", f.listener.Addr().String(), CallbackPath, f.State)
+}
+
+// Wait blocks until the callback arrives, the context is done or the flow
+// is closed
+func (f *Flow) Wait(ctx context.Context) (*Callback, error) {
+	select {
+	case r := <-f.results:
+		return r.callback, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Close stops the loopback listener
+func (f *Flow) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	return f.server.Shutdown(ctx)
+}
+
+// handleCallback validates the redirect and reports it to Wait. Only the
+// first valid callback counts; later ones are told to close the tab. A
+// callback with the wrong state is answered 400 but doesn't end the login,
+// so another local process can't abort it.
+func (f *Flow) handleCallback(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	if subtle.ConstantTimeCompare([]byte(query.Get("state")), []byte(f.State)) != 1 {
+		f.respond(w, http.StatusBadRequest, "Login failed", "This login link was not started by this terminal.")
+		return
+	}
+
+	if errCode := query.Get("error"); errCode != "" {
+		message := query.Get("error_description")
+		if message == "" {
+			message = errCode
+		}
+		f.respond(w, http.StatusBadRequest, "Login failed", message)
+		f.report(result{err: fmt.Errorf("authorization failed: %s", message)})
+		return
+	}
+
+	callback := &Callback{
+		AccessToken:  query.Get("access_token"),
+		RefreshToken: query.Get("refresh_token"),
+	}
+	if callback.AccessToken == "" && query.Get(fragmentParam) == "" {
+		// The tokens may be in the URL fragment, which browsers don't send
+		f.forwardFragment(w)
+		return
+	}
+	if callback.AccessToken == "" {
+		f.respond(w, http.StatusBadRequest, "Login failed", "The login link did not include a session.")
+		f.report(result{err: errors.New("login callback did not include a session")})
+		return
+	}
+
+	f.respond(w, http.StatusOK, "Logged in", "You can close this tab and return to the terminal.")
+	f.report(result{callback: callback})
+}
+
+// report hands a result to Wait without blocking on repeated callbacks
+func (f *Flow) report(r result) {
+	select {
+	case f.results <- r:
+	default:
+	}
+}
+
+// forwardFragment serves a page that reloads the callback with the URL
+// fragment appended to the query, where handleCallback can read it
+func (f *Flow) forwardFragment(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = fmt.Fprintf(w, "<!doctype html><title>GoDeploy</title><p>Logging in...</p>"+
+		"<script>location.replace(location.pathname + location.search + '&%s=1&' + location.hash.slice(1))</script>",
+		fragmentParam)
+}
+
+func (f *Flow) respond(w http.ResponseWriter, status int, title, message string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	_, _ = fmt.Fprintf(w, "<!doctype html><title>GoDeploy</title><h1>%s</h1><p>%s</p>",
+		html.EscapeString(title), html.EscapeString(message))
+}
+
+// randomString returns n random bytes, base64url encoded
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate random value: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,165 @@
+package weblogin
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/silvabyte/godeploy/internal/api"
+)
+
+// newFakeAuthServer returns a server with the API's /api/auth/init and
+// /magic-link routes. The link it "emails" is sent on the returned channel;
+// opening it redirects with a session token, as the API does.
+func newFakeAuthServer(t *testing.T) (*httptest.Server, <-chan string) {
+	t.Helper()
+	links := make(chan string, 1)
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("POST /api/auth/init", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Email       string `json:"email"`
+			RedirectURI string `json:"redirect_uri"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Email == "" {
+			t.Errorf("Invalid init body: %v", err)
+		}
+		links <- server.URL + "/magic-link?" + url.Values{"redirect_to": {req.RedirectURI}, "token": {"access"}}.Encode()
+		_, _ = w.Write([]byte(`{"success":true,"message":"Check your email for the login link."}`))
+	})
+	mux.HandleFunc("GET /magic-link", func(w http.ResponseWriter, r *http.Request) {
+		redirect, err := url.Parse(r.URL.Query().Get("redirect_to"))
+		if err != nil {
+			t.Errorf("Invalid redirect_to: %v", err)
+			return
+		}
+		params := redirect.Query()
+		params.Set("access_token", r.URL.Query().Get("token"))
+		redirect.RawQuery = params.Encode()
+		http.Redirect(w, r, redirect.String(), http.StatusFound)
+	})
+	return server, links
+}
+
+// startFlow starts a flow that is closed when the test ends
+func startFlow(t *testing.T) *Flow {
+	t.Helper()
+	flow, err := Start()
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = flow.Close()
+	})
+	return flow
+}
+
+// waitFor waits briefly for the callback
+func waitFor(t *testing.T, flow *Flow) (*Callback, error) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return flow.Wait(ctx)
+}
+
+// get requests url like a browser and returns the status and page
+func get(t *testing.T, url string) (int, string) {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("Browser request failed: %v", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, string(body)
+}
+
+// TestMagicLinkFlow drives the browser login against the API's magic-link
+// routes
+func TestMagicLinkFlow(t *testing.T) {
+	server, links := newFakeAuthServer(t)
+	client := &api.Client{BaseURL: server.URL, HTTPClient: server.Client()}
+	flow := startFlow(t)
+
+	if _, err := client.InitAuth(context.Background(), "dev@example.com", flow.RedirectURI()); err != nil {
+		t.Fatalf("InitAuth failed: %v", err)
+	}
+
+	// The "browser" opens the emailed link and follows the redirect back
+	if status, _ := get(t, <-links); status != http.StatusOK {
+		t.Fatalf("Expected callback page to succeed, got %d", status)
+	}
+
+	callback, err := waitFor(t, flow)
+	if err != nil {
+		t.Fatalf("Wait failed: %v", err)
+	}
+	if callback.AccessToken != "access" {
+		t.Fatalf("Unexpected callback: %+v", callback)
+	}
+}
+
+// TestCallbackStateMismatch tests that a callback from another login is
+// rejected without ending the login in progress
+func TestCallbackStateMismatch(t *testing.T) {
+	flow := startFlow(t)
+	forged := "http://" + flow.listener.Addr().String() + CallbackPath + "?state=forged&access_token=forged"
+
+	if status, _ := get(t, forged); status != http.StatusBadRequest {
+		t.Fatalf("Expected 400 for forged state, got %d", status)
+	}
+	if status, _ := get(t, flow.RedirectURI()+"&access_token=access&refresh_token=refresh"); status != http.StatusOK {
+		t.Fatalf("Expected the real callback to succeed, got %d", status)
+	}
+
+	callback, err := waitFor(t, flow)
+	if err != nil {
+		t.Fatalf("Wait failed: %v", err)
+	}
+	if callback.AccessToken != "access" || callback.RefreshToken != "refresh" {
+		t.Fatalf("Unexpected callback: %+v", callback)
+	}
+}
+
+// TestCallbackFragment tests that a callback without tokens in the query
+// gets a page that resends the URL fragment, and fails if there is none
+func TestCallbackFragment(t *testing.T) {
+	flow := startFlow(t)
+
+	status, page := get(t, flow.RedirectURI())
+	if status != http.StatusOK || !strings.Contains(page, "location.hash") {
+		t.Fatalf("Expected the fragment forwarding page, got %d %s", status, page)
+	}
+
+	if status, _ := get(t, flow.RedirectURI()+"&fragment=1&"); status != http.StatusBadRequest {
+		t.Fatalf("Expected 400 for a callback without a session, got %d", status)
+	}
+	if _, err := waitFor(t, flow); err == nil {
+		t.Fatal("Expected the login to fail without a session")
+	}
+}
+
+// TestCallbackError tests that an authorization error is reported
+func TestCallbackError(t *testing.T) {
+	flow := startFlow(t)
+
+	get(t, flow.RedirectURI()+"&error=access_denied&error_description=User+cancelled")
+
+	if _, err := waitFor(t, flow); err == nil || err.Error() != "authorization failed: User cancelled" {
+		t.Fatalf("Expected authorization error, got %v", err)
+	}
+}
@@ -0,0 +1,44 @@
+package godeploy
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Alias is an extra hostname pinned to one deployment of a project
+type Alias struct {
+	Alias        string    `json:"alias"`
+	DeploymentID string    `json:"deployment_id"`
+	URL          string    `json:"url"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// ListAliases returns the aliases of a project
+func (c *Client) ListAliases(ctx context.Context, projectID string) ([]Alias, error) {
+	var aliases []Alias
+	if err := c.call(ctx, http.MethodGet, pathf("/api/projects/%s/aliases", projectID), nil, nil, &aliases); err != nil {
+		return nil, err
+	}
+	return aliases, nil
+}
+
+// CreateAlias points alias at a deployment of a project. An empty
+// deploymentID uses the active deployment.
+func (c *Client) CreateAlias(ctx context.Context, projectID, alias, deploymentID string) (*Alias, error) {
+	body := struct {
+		Alias        string `json:"alias"`
+		DeploymentID string `json:"deployment_id,omitempty"`
+	}{alias, deploymentID}
+
+	var created Alias
+	if err := c.call(ctx, http.MethodPost, pathf("/api/projects/%s/aliases", projectID), nil, body, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// DeleteAlias removes an alias from a project
+func (c *Client) DeleteAlias(ctx context.Context, projectID, alias string) error {
+	return c.call(ctx, http.MethodDelete, pathf("/api/projects/%s/aliases/%s", projectID, alias), nil, nil, nil)
+}
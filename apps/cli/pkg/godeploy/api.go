@@ -0,0 +1,152 @@
+package godeploy
+
+import (
+	"context"
+	"time"
+)
+
+// API is the set of operations of the GoDeploy API. Client implements it;
+// depend on API, or on one of the smaller interfaces it is made of, to
+// substitute a test double. See the package documentation on versioning
+// before implementing it outside this package.
+type API interface {
+	AuthAPI
+	ProjectsAPI
+	DeploymentsAPI
+	EnvAPI
+	DomainsAPI
+	AliasesAPI
+	TeamsAPI
+	TokensAPI
+	CacheAPI
+	BuildsAPI
+	LogsAPI
+	MetricsAPI
+	SubscriptionsAPI
+
+	ServerStatus(ctx context.Context) (*ServerHealth, error)
+}
+
+// AuthAPI logs users in and manages their sessions and passwords. Apart
+// from ChangePassword these endpoints take their credentials as arguments
+// rather than from the client's token source.
+type AuthAPI interface {
+	InitAuth(ctx context.Context, email, redirectURI string) (*AuthInitResponse, error)
+	SignIn(ctx context.Context, email, password string) (*SignInResponse, error)
+	SignUp(ctx context.Context, email, password string) (*SignUpResponse, error)
+	VerifyToken(ctx context.Context, token string) (*VerifyResponse, error)
+	RefreshToken(ctx context.Context, refreshToken string) (*RefreshResponse, error)
+	SignOut(ctx context.Context, accessToken, refreshToken string, allSessions bool) error
+	ChangePassword(ctx context.Context, currentPassword, newPassword string) error
+	RequestPasswordReset(ctx context.Context, email, redirectURI string) error
+	ConfirmPasswordReset(ctx context.Context, token, newPassword string) error
+}
+
+// ProjectsAPI manages projects
+type ProjectsAPI interface {
+	ListProjects(ctx context.Context) ([]Project, error)
+	FindProject(ctx context.Context, nameOrID string) (*Project, error)
+	CreateProject(ctx context.Context, req CreateProjectRequest) (*Project, error)
+	DeleteProject(ctx context.Context, projectID string) error
+	SetProjectDomain(ctx context.Context, projectID, domain string) (*Project, error)
+	GetProjectStatus(ctx context.Context, projectID string) (*ProjectStatus, error)
+	GetProjectHealth(ctx context.Context, projectID string) (*Health, error)
+	GetProjectDiff(ctx context.Context, projectID string) (*Diff, error)
+	Rollback(ctx context.Context, projectID, deploymentID string) (*Deployment, error)
+	Promote(ctx context.Context, sourceProjectID, targetProjectID string) (*Deployment, error)
+}
+
+// DeploymentsAPI uploads and inspects deployments
+type DeploymentsAPI interface {
+	Deploy(ctx context.Context, deploy DeployRequest) (*Deployment, error)
+	CreatePreview(ctx context.Context, deploy DeployRequest) (*Deployment, error)
+	GetDeployment(ctx context.Context, deploymentID string) (*Deployment, error)
+	ListDeployments(ctx context.Context, projectID string, opts *DeploymentListOptions) (*DeploymentList, error)
+	CompareDeployments(ctx context.Context, fromID, toID string) (*Diff, error)
+}
+
+// EnvAPI manages the build environment variables of projects
+type EnvAPI interface {
+	ListEnv(ctx context.Context, projectID string) ([]EnvVar, error)
+	GetEnv(ctx context.Context, projectID, key string) (*EnvVar, error)
+	SetEnv(ctx context.Context, projectID string, v EnvVar) (*EnvVar, error)
+	DeleteEnv(ctx context.Context, projectID, key string) error
+}
+
+// DomainsAPI checks custom domains; SetProjectDomain assigns them
+type DomainsAPI interface {
+	CNAMETarget(ctx context.Context) (string, error)
+	CheckDomainAvailability(ctx context.Context, domain, projectID string) (*DomainAvailability, error)
+	ValidateDomain(ctx context.Context, domain string) (*DomainValidation, error)
+}
+
+// AliasesAPI manages the aliases of projects
+type AliasesAPI interface {
+	ListAliases(ctx context.Context, projectID string) ([]Alias, error)
+	CreateAlias(ctx context.Context, projectID, alias, deploymentID string) (*Alias, error)
+	DeleteAlias(ctx context.Context, projectID, alias string) error
+}
+
+// TeamsAPI manages teams and their members
+type TeamsAPI interface {
+	ListTeams(ctx context.Context) ([]Team, error)
+	GetTeam(ctx context.Context, teamID string) (*Team, error)
+	CreateTeam(ctx context.Context, name string) (*Team, error)
+	RenameTeam(ctx context.Context, teamID, name string) (*Team, error)
+	DeleteTeam(ctx context.Context, teamID string) error
+	ListTeamMembers(ctx context.Context, teamID string) ([]TeamMember, error)
+	AddTeamMember(ctx context.Context, teamID, email, role string) (*TeamMember, error)
+	RemoveTeamMember(ctx context.Context, teamID, userID string) error
+}
+
+// TokensAPI manages API tokens
+type TokensAPI interface {
+	ListTokens(ctx context.Context) ([]APIToken, error)
+	GetToken(ctx context.Context, tokenID string) (*APIToken, error)
+	CreateToken(ctx context.Context, req CreateTokenRequest) (*CreatedToken, error)
+	RenameToken(ctx context.Context, tokenID, name string) (*APIToken, error)
+	RevokeToken(ctx context.Context, tokenID string) error
+}
+
+// CacheAPI manages the CDN cache of projects
+type CacheAPI interface {
+	ClearCache(ctx context.Context, projectID string) error
+	PurgeCache(ctx context.Context, projectID string, paths []string) error
+	GetCacheStats(ctx context.Context, projectID string) (*CacheStats, error)
+}
+
+// BuildsAPI runs and configures builds on the server
+type BuildsAPI interface {
+	GetBuildConfig(ctx context.Context, projectID string) (*BuildConfig, error)
+	SetBuildConfig(ctx context.Context, projectID string, config BuildConfig) (*BuildConfig, error)
+	RunBuild(ctx context.Context, projectID string) (*Build, error)
+	ListBuilds(ctx context.Context, projectID string) ([]Build, error)
+}
+
+// LogsAPI reads deploy and build logs
+type LogsAPI interface {
+	GetProjectLogs(ctx context.Context, projectID string, opts *LogOptions) (*LogList, error)
+	GetBuildLogs(ctx context.Context, projectID, buildID string, opts *LogOptions) (*LogList, error)
+	FollowProjectLogs(ctx context.Context, projectID string, opts *LogOptions, handle LogHandler) error
+	FollowBuildLogs(ctx context.Context, projectID, buildID string, opts *LogOptions, handle LogHandler) error
+}
+
+// MetricsAPI reads project metrics and manages metrics pages
+type MetricsAPI interface {
+	GetProjectMetrics(ctx context.Context, projectID string) (*ProjectMetrics, error)
+	GetAnalytics(ctx context.Context, projectID string, from, to time.Time) (*Analytics, error)
+	ListMetricsPages(ctx context.Context) ([]MetricsPage, error)
+	CreateMetricsPage(ctx context.Context, req MetricsPageRequest) (*MetricsPage, error)
+	UpdateMetricsPage(ctx context.Context, pageID string, req MetricsPageRequest) (*MetricsPage, error)
+	DeleteMetricsPage(ctx context.Context, pageID string) error
+	GetPublicMetricsPage(ctx context.Context, slug string) (*PublicMetricsPage, error)
+	GetDeployFrequency(ctx context.Context, slug string, from, to time.Time) (*DeployFrequency, error)
+}
+
+// SubscriptionsAPI reads and cancels the plan of the tenant
+type SubscriptionsAPI interface {
+	GetSubscription(ctx context.Context) (*Subscription, error)
+	CancelSubscription(ctx context.Context, subscriptionID string) error
+}
+
+var _ API = (*Client)(nil)
@@ -0,0 +1,270 @@
+package godeploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrIncorrectPassword is returned when the current password is wrong
+	ErrIncorrectPassword = errors.New("current password is incorrect")
+	// ErrInvalidResetToken is returned when a password reset token is
+	// unknown, already used or expired
+	ErrInvalidResetToken = errors.New("password reset token is invalid or expired")
+)
+
+// AuthUser is the account a session belongs to
+type AuthUser struct {
+	ID       string `json:"id"`
+	Email    string `json:"email"`
+	TenantID string `json:"tenant_id"`
+}
+
+// AuthInitRequest is the body of InitAuth
+type AuthInitRequest struct {
+	Email       string `json:"email"`
+	RedirectURI string `json:"redirect_uri"`
+}
+
+// AuthInitResponse is the response of InitAuth
+type AuthInitResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Error   string `json:"error,omitempty"`
+}
+
+// SignInRequest is the body of SignIn
+type SignInRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// SignInResponse is a session started by SignIn
+type SignInResponse struct {
+	Success      bool     `json:"success"`
+	Token        string   `json:"token"`
+	RefreshToken string   `json:"refresh_token"`
+	User         AuthUser `json:"user"`
+	Error        string   `json:"error,omitempty"`
+}
+
+// SignUpRequest is the body of SignUp
+type SignUpRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// SignUpResponse is the session of an account created by SignUp
+type SignUpResponse struct {
+	Success      bool     `json:"success"`
+	Token        string   `json:"token"`
+	RefreshToken string   `json:"refresh_token"`
+	User         AuthUser `json:"user"`
+	Error        string   `json:"error,omitempty"`
+}
+
+// VerifyResponse is the response of VerifyToken
+type VerifyResponse struct {
+	Valid bool     `json:"valid"`
+	User  AuthUser `json:"user,omitempty"`
+	Error string   `json:"error,omitempty"`
+}
+
+// RefreshResponse is the renewed session returned by RefreshToken
+type RefreshResponse struct {
+	Success      bool   `json:"success"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	Error        string `json:"error,omitempty"`
+}
+
+// ChangePasswordRequest is the body of ChangePassword
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword"`
+}
+
+// ResetPasswordRequest is the body of RequestPasswordReset
+type ResetPasswordRequest struct {
+	Email       string `json:"email"`
+	RedirectURI string `json:"redirect_uri,omitempty"`
+}
+
+// ResetPasswordConfirmRequest is the body of ConfirmPasswordReset
+type ResetPasswordConfirmRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"newPassword"`
+}
+
+// SignOutRequest is the body of SignOut
+type SignOutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+	// Scope is "local" for this session or "global" for all sessions
+	Scope string `json:"scope"`
+}
+
+// InitAuth emails a login link to email. Opening it redirects to
+// redirectURI with the new session.
+func (c *Client) InitAuth(ctx context.Context, email, redirectURI string) (*AuthInitResponse, error) {
+	var resp AuthInitResponse
+	if err := c.authCall(ctx, http.MethodPost, "/api/auth/init", "", AuthInitRequest{Email: email, RedirectURI: redirectURI}, &resp); err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, fmt.Errorf("authentication initialization failed: %s", resp.Error)
+	}
+	return &resp, nil
+}
+
+// SignIn starts a session with email and password
+func (c *Client) SignIn(ctx context.Context, email, password string) (*SignInResponse, error) {
+	var resp SignInResponse
+	err := c.authCall(ctx, http.MethodPost, "/api/auth/signin", "", SignInRequest{Email: email, Password: password}, &resp)
+	var apiErr *Error
+	if errors.As(err, &apiErr) && apiErr.Message == "" && apiErr.StatusCode == http.StatusUnauthorized {
+		apiErr.Message = "invalid email or password"
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, fmt.Errorf("authentication failed: %s", resp.Error)
+	}
+	return &resp, nil
+}
+
+// SignUp creates an account and starts a session for it. An email that
+// already has an account returns an error matching ErrConflict.
+func (c *Client) SignUp(ctx context.Context, email, password string) (*SignUpResponse, error) {
+	var resp SignUpResponse
+	err := c.authCall(ctx, http.MethodPost, "/api/auth/signup", "", SignUpRequest{Email: email, Password: password}, &resp)
+	var apiErr *Error
+	// The server passes the identity provider's message through without a
+	// code, so this is the one place that looks at the text
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusBadRequest &&
+		(strings.Contains(apiErr.Message, "already registered") || strings.Contains(apiErr.Message, "already exists")) {
+		apiErr.Kind = ErrConflict
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, fmt.Errorf("sign up failed: %s", resp.Error)
+	}
+	return &resp, nil
+}
+
+// VerifyToken checks an access token. A token the server rejects is not an
+// error; the response has Valid false.
+func (c *Client) VerifyToken(ctx context.Context, token string) (*VerifyResponse, error) {
+	var resp VerifyResponse
+	err := c.authCall(ctx, http.MethodGet, "/api/auth/verify", token, nil, &resp)
+	var apiErr *Error
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized {
+		return &VerifyResponse{Error: apiErr.Message}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// RefreshToken exchanges a refresh token for a new session. Refresh tokens
+// are single-use; the response carries the next one.
+func (c *Client) RefreshToken(ctx context.Context, refreshToken string) (*RefreshResponse, error) {
+	body := struct {
+		RefreshToken string `json:"refresh_token"`
+	}{refreshToken}
+
+	var resp RefreshResponse
+	err := c.authCall(ctx, http.MethodPost, "/api/auth/refresh", "", body, &resp)
+	var apiErr *Error
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized {
+		apiErr.Message = "refresh token is invalid or expired, please login again"
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, fmt.Errorf("refresh failed: %s", resp.Error)
+	}
+	return &resp, nil
+}
+
+// ChangePassword changes the password of the authenticated user. A wrong
+// current password returns an error matching ErrIncorrectPassword.
+func (c *Client) ChangePassword(ctx context.Context, currentPassword, newPassword string) error {
+	if c.tokens == nil {
+		return &Error{Message: "changing the password needs credentials", Kind: ErrUnauthorized}
+	}
+	// The server answers a wrong password with 401, so the request is sent
+	// once with the current token instead of refreshing it and retrying
+	token, err := c.tokens.Token(ctx)
+	if err != nil {
+		return authError("failed to get valid auth token", err)
+	}
+
+	err = c.authCall(ctx, http.MethodPost, "/api/auth/change-password", token, ChangePasswordRequest{
+		CurrentPassword: currentPassword,
+		NewPassword:     newPassword,
+	}, nil)
+	var apiErr *Error
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized {
+		apiErr.Kind = ErrIncorrectPassword
+	}
+	return err
+}
+
+// RequestPasswordReset emails a password reset link to email. redirectURI
+// is where the link leads and may be empty for the default page.
+func (c *Client) RequestPasswordReset(ctx context.Context, email, redirectURI string) error {
+	return c.authCall(ctx, http.MethodPost, "/api/auth/reset-password", "", ResetPasswordRequest{
+		Email:       email,
+		RedirectURI: redirectURI,
+	}, nil)
+}
+
+// ConfirmPasswordReset sets a new password with the token from the reset
+// email. A rejected token returns an error matching ErrInvalidResetToken.
+func (c *Client) ConfirmPasswordReset(ctx context.Context, token, newPassword string) error {
+	err := c.authCall(ctx, http.MethodPost, "/api/auth/reset-password/confirm", "", ResetPasswordConfirmRequest{
+		Token:       token,
+		NewPassword: newPassword,
+	}, nil)
+	var apiErr *Error
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusBadRequest && apiErr.Code != "FST_ERR_VALIDATION" {
+		apiErr.Kind = ErrInvalidResetToken
+	}
+	return err
+}
+
+// SignOut revokes the session of accessToken and refreshToken. With
+// allSessions every session of the account is revoked. A rejected access
+// token returns an error matching ErrUnauthorized.
+func (c *Client) SignOut(ctx context.Context, accessToken, refreshToken string, allSessions bool) error {
+	scope := "local"
+	if allSessions {
+		scope = "global"
+	}
+	return c.authCall(ctx, http.MethodPost, "/api/auth/signout", accessToken, SignOutRequest{
+		RefreshToken: refreshToken,
+		Scope:        scope,
+	}, nil)
+}
+
+// authCall sends a request to an auth endpoint. These carry their own
+// credentials, so the token source isn't used: token, when set, is the
+// bearer token, and a rejected one is not refreshed.
+func (c *Client) authCall(ctx context.Context, method, path, token string, body, out any) error {
+	req, err := c.newRequest(ctx, method, path, nil, body)
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return c.With(WithTokenSource(nil)).do(req, out)
+}
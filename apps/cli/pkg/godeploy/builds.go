@@ -0,0 +1,63 @@
+package godeploy
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// BuildConfig is how the server builds a project
+type BuildConfig struct {
+	Command    string            `json:"command"`
+	OutputDir  string            `json:"output_dir"`
+	WorkingDir string            `json:"working_dir,omitempty"`
+	Env        map[string]string `json:"env,omitempty"`
+}
+
+// Build is a run of a project's build on the server
+type Build struct {
+	ID        string `json:"id"`
+	ProjectID string `json:"project_id"`
+	// Status is "queued", "running", "success" or "failed"
+	Status string `json:"status"`
+	// DeploymentID is the deployment the build produced, once it succeeded
+	DeploymentID string     `json:"deployment_id,omitempty"`
+	StartedAt    time.Time  `json:"started_at"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+}
+
+// GetBuildConfig returns the build configuration of a project
+func (c *Client) GetBuildConfig(ctx context.Context, projectID string) (*BuildConfig, error) {
+	var config BuildConfig
+	if err := c.call(ctx, http.MethodGet, pathf("/api/projects/%s/builds/config", projectID), nil, nil, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// SetBuildConfig replaces the build configuration of a project
+func (c *Client) SetBuildConfig(ctx context.Context, projectID string, config BuildConfig) (*BuildConfig, error) {
+	var saved BuildConfig
+	if err := c.call(ctx, http.MethodPost, pathf("/api/projects/%s/builds/config", projectID), nil, config, &saved); err != nil {
+		return nil, err
+	}
+	return &saved, nil
+}
+
+// RunBuild starts a build of a project on the server
+func (c *Client) RunBuild(ctx context.Context, projectID string) (*Build, error) {
+	var build Build
+	if err := c.call(ctx, http.MethodPost, pathf("/api/projects/%s/builds/run", projectID), nil, struct{}{}, &build); err != nil {
+		return nil, err
+	}
+	return &build, nil
+}
+
+// ListBuilds returns the build history of a project, newest first
+func (c *Client) ListBuilds(ctx context.Context, projectID string) ([]Build, error) {
+	var builds []Build
+	if err := c.call(ctx, http.MethodGet, pathf("/api/projects/%s/builds/history", projectID), nil, nil, &builds); err != nil {
+		return nil, err
+	}
+	return builds, nil
+}
@@ -0,0 +1,38 @@
+package godeploy
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// CacheStats describes the CDN cache of a project
+type CacheStats struct {
+	Objects      int        `json:"objects"`
+	SizeBytes    int64      `json:"size_bytes"`
+	HitRatio     float64    `json:"hit_ratio"`
+	LastPurgedAt *time.Time `json:"last_purged_at,omitempty"`
+}
+
+// ClearCache empties the CDN cache of a project
+func (c *Client) ClearCache(ctx context.Context, projectID string) error {
+	return c.call(ctx, http.MethodPost, pathf("/api/projects/%s/cache/clear", projectID), nil, struct{}{}, nil)
+}
+
+// PurgeCache removes paths, such as "/index.html" or "/assets/*", from the
+// CDN cache of a project
+func (c *Client) PurgeCache(ctx context.Context, projectID string, paths []string) error {
+	body := struct {
+		Paths []string `json:"paths"`
+	}{paths}
+	return c.call(ctx, http.MethodPost, pathf("/api/projects/%s/cache/purge", projectID), nil, body, nil)
+}
+
+// GetCacheStats returns statistics of the CDN cache of a project
+func (c *Client) GetCacheStats(ctx context.Context, projectID string) (*CacheStats, error) {
+	var stats CacheStats
+	if err := c.call(ctx, http.MethodGet, pathf("/api/projects/%s/cache/stats", projectID), nil, nil, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
@@ -0,0 +1,268 @@
+package godeploy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultBaseURL is the hosted GoDeploy API
+	DefaultBaseURL = "https://api.godeploy.app"
+	// DefaultTimeout is the default timeout for API requests
+	DefaultTimeout = 30 * time.Second
+	// DefaultDeployTimeout is the default timeout for uploading a deploy
+	DefaultDeployTimeout = 10 * time.Minute
+)
+
+// Client is a GoDeploy API client. It is safe for concurrent use.
+type Client struct {
+	baseURL       string
+	httpClient    *http.Client
+	tokens        TokenSource
+	retry         RetryPolicy
+	userAgent     string
+	deployTimeout time.Duration
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// New returns a client for the API at DefaultBaseURL, configured by opts
+func New(opts ...Option) *Client {
+	c := &Client{
+		baseURL:       DefaultBaseURL,
+		httpClient:    &http.Client{Timeout: DefaultTimeout},
+		retry:         DefaultRetryPolicy,
+		userAgent:     "godeploy-go/" + Version,
+		deployTimeout: DefaultDeployTimeout,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// With returns a copy of the client with opts applied, for calls that need
+// different settings than the client's
+func (c *Client) With(opts ...Option) *Client {
+	clone := *c
+	for _, opt := range opts {
+		opt(&clone)
+	}
+	return &clone
+}
+
+// BaseURL returns the API base URL of the client
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// WithBaseURL sets the API base URL, e.g. for a self-hosted API
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithHTTPClient sets the HTTP client that sends requests. Its Timeout
+// applies to every request but deploys; see WithDeployTimeout.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithToken authenticates requests with an API token
+func WithToken(token string) Option {
+	return WithTokenSource(StaticToken(token))
+}
+
+// WithTokenSource authenticates requests with tokens from ts
+func WithTokenSource(ts TokenSource) Option {
+	return func(c *Client) {
+		c.tokens = ts
+	}
+}
+
+// WithRetry sets the retry policy; NoRetry disables retries
+func WithRetry(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// WithUserAgent sets the User-Agent header of requests
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithDeployTimeout sets the timeout of deploy uploads, which take longer
+// than other requests
+func WithDeployTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.deployTimeout = timeout
+	}
+}
+
+// TokenSource supplies the bearer token of authenticated requests
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// TokenRefresher is a TokenSource whose tokens expire. When the API rejects
+// a token, the client calls Refresh once and retries with the new token.
+type TokenRefresher interface {
+	TokenSource
+	Refresh(ctx context.Context) (string, error)
+}
+
+// StaticToken is a TokenSource that always returns the same token, such as
+// an API token. It is never refreshed.
+type StaticToken string
+
+// Token returns the token
+func (t StaticToken) Token(context.Context) (string, error) {
+	return string(t), nil
+}
+
+// Do sends req with the client's retry policy and credentials, for
+// endpoints this package doesn't cover. A request that already carries an
+// Authorization header is sent as it is. A non-2xx response is returned
+// without error; pass it to ResponseError. The caller closes the body.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	if c.userAgent != "" && req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	if c.tokens == nil || req.Header.Get("Authorization") != "" {
+		return c.send(req)
+	}
+
+	token, err := c.tokens.Token(req.Context())
+	if err != nil {
+		return nil, authError("failed to get valid auth token", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := c.send(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	refresher, ok := c.tokens.(TokenRefresher)
+	if !ok {
+		// Nothing to refresh; the token is bad
+		_ = resp.Body.Close()
+		return nil, &Error{
+			StatusCode: resp.StatusCode,
+			Message:    "API token was rejected; check that it is valid and has not been revoked",
+			RequestID:  resp.Header.Get("X-Request-Id"),
+			Kind:       ErrUnauthorized,
+		}
+	}
+	_ = resp.Body.Close()
+
+	token, err = refresher.Refresh(req.Context())
+	if err != nil {
+		return nil, authError("authentication failed and token refresh failed", err)
+	}
+
+	// The body of the first attempt was consumed; send a rewound copy
+	retryReq, err := cloneRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone request for retry: %w", err)
+	}
+	retryReq.Header.Set("Authorization", "Bearer "+token)
+	return c.send(retryReq)
+}
+
+// newRequest creates a request for path relative to the base URL. A non-nil
+// body is sent as JSON.
+func (c *Client) newRequest(ctx context.Context, method, path string, query url.Values, body any) (*http.Request, error) {
+	endpoint := c.baseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	return req, nil
+}
+
+// do sends req and decodes a successful JSON response into out, which may
+// be nil. A non-2xx response is returned as an *Error.
+func (c *Client) do(req *http.Request, out any) error {
+	resp, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return ResponseError(resp, body)
+	}
+
+	if out == nil || len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// call builds and sends a request in one step
+func (c *Client) call(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	req, err := c.newRequest(ctx, method, path, query, body)
+	if err != nil {
+		return err
+	}
+	return c.do(req, out)
+}
+
+// withTimeout returns a copy of the client whose requests time out after
+// timeout, sharing the transport
+func (c *Client) withTimeout(timeout time.Duration) *Client {
+	httpClient := *c.httpClient
+	httpClient.Timeout = timeout
+	return c.With(WithHTTPClient(&httpClient))
+}
+
+// pathf formats an API path, escaping each argument as a path segment
+func pathf(format string, args ...string) string {
+	escaped := make([]any, len(args))
+	for i, arg := range args {
+		escaped[i] = url.PathEscape(arg)
+	}
+	return fmt.Sprintf(format, escaped...)
+}
@@ -0,0 +1,310 @@
+package godeploy
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// refreshingTokens hands out "old" until refreshed, then "new"
+type refreshingTokens struct {
+	refreshed int
+}
+
+func (r *refreshingTokens) Token(context.Context) (string, error) {
+	if r.refreshed > 0 {
+		return "new", nil
+	}
+	return "old", nil
+}
+
+func (r *refreshingTokens) Refresh(context.Context) (string, error) {
+	r.refreshed++
+	return "new", nil
+}
+
+// TestRejectedTokenIsRefreshed tests that a 401 refreshes the token once and
+// resends the request with its body
+func TestRejectedTokenIsRefreshed(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if r.Header.Get("Authorization") != "Bearer new" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":"p1","name":"web"}`))
+	}))
+	defer server.Close()
+
+	tokens := &refreshingTokens{}
+	client := New(WithBaseURL(server.URL), WithTokenSource(tokens))
+	project, err := client.CreateProject(context.Background(), CreateProjectRequest{Name: "web"})
+	if err != nil {
+		t.Fatalf("CreateProject failed: %v", err)
+	}
+	if project.ID != "p1" || tokens.refreshed != 1 {
+		t.Fatalf("Expected one refresh and the project, got %+v after %d refreshes", project, tokens.refreshed)
+	}
+	if len(bodies) != 2 || bodies[0] != bodies[1] || !strings.Contains(bodies[1], `"name":"web"`) {
+		t.Fatalf("Expected the body to be resent, got %q", bodies)
+	}
+}
+
+// TestStaticTokenRejected tests that a rejected API token fails without a
+// second request
+func TestStaticTokenRejected(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	_, err := New(WithBaseURL(server.URL), WithToken("gdp_revoked")).ListProjects(context.Background())
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("Expected ErrUnauthorized, got %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("Expected one request, got %d", calls)
+	}
+}
+
+// TestAuthEndpointsSkipTokenSource tests that auth endpoints send their own
+// credentials and that a 401 is answered without a refresh
+func TestAuthEndpointsSkipTokenSource(t *testing.T) {
+	var auths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auths = append(auths, r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"valid":false,"error":"Invalid token"}`))
+	}))
+	defer server.Close()
+
+	tokens := &refreshingTokens{}
+	client := New(WithBaseURL(server.URL), WithTokenSource(tokens))
+
+	if _, err := client.SignIn(context.Background(), "dev@example.com", "wrong"); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("Expected ErrUnauthorized from SignIn, got %v", err)
+	}
+	verified, err := client.VerifyToken(context.Background(), "expired")
+	if err != nil || verified.Valid || verified.Error != "Invalid token" {
+		t.Fatalf("Expected an invalid token without error, got %+v, %v", verified, err)
+	}
+	if err := client.ChangePassword(context.Background(), "wrong", "new-password"); !errors.Is(err, ErrIncorrectPassword) {
+		t.Fatalf("Expected ErrIncorrectPassword, got %v", err)
+	}
+
+	if want := []string{"", "Bearer expired", "Bearer old"}; strings.Join(auths, ",") != strings.Join(want, ",") {
+		t.Fatalf("Expected Authorization headers %q, got %q", want, auths)
+	}
+	if tokens.refreshed != 0 {
+		t.Fatalf("Expected no refresh, got %d", tokens.refreshed)
+	}
+}
+
+// TestNotImplementedEndpoint tests that a 501 maps to ErrNotImplemented and
+// isn't retried
+func TestNotImplementedEndpoint(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusNotImplemented)
+		_, _ = w.Write([]byte(`{"error":"Not implemented yet","message":"Aliases list endpoint coming soon"}`))
+	}))
+	defer server.Close()
+
+	_, err := New(WithBaseURL(server.URL)).ListAliases(context.Background(), "p1")
+	if !errors.Is(err, ErrNotImplemented) || IsUnavailable(err) {
+		t.Fatalf("Expected a final ErrNotImplemented, got %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("Expected one request, got %d", calls)
+	}
+}
+
+// TestUndecodableResponseIsNotUnavailable tests that a response that can't
+// be decoded isn't reported as the API being unavailable
+func TestUndecodableResponseIsNotUnavailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"tokens":`))
+	}))
+	defer server.Close()
+
+	_, err := New(WithBaseURL(server.URL)).ListTokens(context.Background())
+	if err == nil || IsUnavailable(err) {
+		t.Fatalf("Expected a decode error that isn't unavailable, got %v", err)
+	}
+	if !IsUnavailable(&net.OpError{Op: "dial", Err: errors.New("connection refused")}) {
+		t.Fatal("Expected a network error to be unavailable")
+	}
+}
+
+// TestListProjectsFollowsPages tests that paginated project lists are
+// fetched in full
+func TestListProjectsFollowsPages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("offset") == "" {
+			_, _ = w.Write([]byte(`{"data":[{"id":"p1"},{"id":"p2"}],"meta":{"total":3,"limit":2,"offset":0}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[{"id":"p3"}],"meta":{"total":3,"limit":2,"offset":2}}`))
+	}))
+	defer server.Close()
+
+	projects, err := New(WithBaseURL(server.URL)).ListProjects(context.Background())
+	if err != nil {
+		t.Fatalf("ListProjects failed: %v", err)
+	}
+	if len(projects) != 3 || projects[2].ID != "p3" {
+		t.Fatalf("Expected all 3 projects, got %+v", projects)
+	}
+}
+
+// TestRequestShape tests paths, escaping, headers and bodies of requests
+func TestRequestShape(t *testing.T) {
+	var got *http.Request
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		got, body = r, string(data)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := New(WithBaseURL(server.URL+"/"), WithToken("gdp_secret"), WithUserAgent("tool/1.0"))
+
+	if _, err := client.SetProjectDomain(context.Background(), "p1", ""); err != nil {
+		t.Fatal(err)
+	}
+	if got.Method != http.MethodPatch || got.URL.Path != "/api/projects/p1/domain" || body != `{"domain":null}` {
+		t.Fatalf("Unexpected request: %s %s %s", got.Method, got.URL.Path, body)
+	}
+	if got.Header.Get("Authorization") != "Bearer gdp_secret" || got.Header.Get("User-Agent") != "tool/1.0" {
+		t.Fatalf("Unexpected headers: %v", got.Header)
+	}
+
+	if _, err := client.GetEnv(context.Background(), "p1", "API/KEY"); err != nil {
+		t.Fatal(err)
+	}
+	if got.URL.EscapedPath() != "/api/projects/p1/env/API%2FKEY" {
+		t.Fatalf("Expected the key to be escaped, got %s", got.URL.EscapedPath())
+	}
+}
+
+// TestDeployUpload tests the multipart form and query of a deploy
+func TestDeployUpload(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/deploy" || r.Header.Get(IdempotencyKeyHeader) == "" {
+			t.Errorf("Unexpected request: %s %v", r.URL.Path, r.Header)
+		}
+		q := r.URL.Query()
+		if q.Get("project") != "web" || q.Get("commit_sha") != "abc123" || q.Get("clear_cache") != "true" {
+			t.Errorf("Unexpected query: %v", q)
+		}
+		file, _, err := r.FormFile("archive")
+		if err != nil {
+			t.Errorf("Expected an archive: %v", err)
+		} else if data, _ := io.ReadAll(file); string(data) != "zip-bytes" {
+			t.Errorf("Unexpected archive %q", data)
+		}
+		_, _ = w.Write([]byte(`{"id":"d1","project_id":"p1","url":"https://web.godeploy.app","status":"success"}`))
+	}))
+	defer server.Close()
+
+	deployment, err := New(WithBaseURL(server.URL)).Deploy(context.Background(), DeployRequest{
+		Project:    "web",
+		Archive:    []byte("zip-bytes"),
+		CommitSHA:  "abc123",
+		ClearCache: true,
+	})
+	if err != nil {
+		t.Fatalf("Deploy failed: %v", err)
+	}
+	if deployment.ID != "d1" || deployment.Status != StatusSuccess {
+		t.Fatalf("Unexpected deployment: %+v", deployment)
+	}
+}
+
+// TestFollowLogsReconnects tests that a dropped log stream is resumed from
+// the last event received and ends with the server's end event
+func TestFollowLogsReconnects(t *testing.T) {
+	var cursors []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("follow") != "true" || q.Get("deployment_id") != "d1" {
+			t.Errorf("Unexpected query: %v", q)
+		}
+		cursors = append(cursors, q.Get("cursor"))
+		w.Header().Set("Content-Type", "text/event-stream")
+		if len(cursors) == 1 {
+			// Drop the connection in the middle of the third event
+			_, _ = io.WriteString(w, ": connected\n\n"+
+				"id: c1\nevent: log\ndata: {\"level\":\"info\",\"message\":\"one\"}\n\n"+
+				"id: c2\ndata: {\"level\":\"warn\",\"message\":\"two\"}\n\n"+
+				"id: c3\nevent: log\ndata: {\"level\":")
+			return
+		}
+		_, _ = io.WriteString(w, "id: c3\nevent: log\ndata: {\"level\":\"info\",\n"+
+			"data: \"message\":\"three\"}\n\nevent: end\ndata: {}\n\n")
+	}))
+	defer server.Close()
+
+	client := New(WithBaseURL(server.URL), WithRetry(RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}))
+	var messages []string
+	err := client.FollowProjectLogs(context.Background(), "p1", &LogOptions{Cursor: "c0", DeploymentID: "d1"}, func(entry LogEntry) error {
+		messages = append(messages, entry.Message)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("FollowProjectLogs failed: %v", err)
+	}
+	if strings.Join(cursors, ",") != "c0,c2" {
+		t.Fatalf("Connected with cursors %v, want c0 and c2", cursors)
+	}
+	if strings.Join(messages, ",") != "one,two,three" {
+		t.Fatalf("Received %v, want one, two and three", messages)
+	}
+}
+
+// TestFollowLogsJSONLines tests newline-delimited JSON streams and that an
+// error from the handler stops following without reconnecting
+func TestFollowLogsJSONLines(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Path != "/api/projects/p1/builds/b1/logs" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		_, _ = io.WriteString(w, `{"message":"one","cursor":"c1"}`+"\n\n"+
+			`{"message":"two","cursor":"c2"}`+"\n"+
+			`{"message":"three","cursor":"c3"}`+"\n")
+	}))
+	defer server.Close()
+
+	errStop := errors.New("stop")
+	var messages []string
+	err := New(WithBaseURL(server.URL)).FollowBuildLogs(context.Background(), "p1", "b1", nil, func(entry LogEntry) error {
+		messages = append(messages, entry.Message)
+		if entry.Cursor == "c2" {
+			return errStop
+		}
+		return nil
+	})
+	if !errors.Is(err, errStop) {
+		t.Fatalf("Expected the handler's error, got %v", err)
+	}
+	if calls != 1 || strings.Join(messages, ",") != "one,two" {
+		t.Fatalf("Received %v in %d requests, want one and two in one", messages, calls)
+	}
+}
@@ -0,0 +1,213 @@
+package godeploy
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Deployment statuses
+const (
+	StatusPending = "pending"
+	StatusSuccess = "success"
+	StatusFailed  = "failed"
+)
+
+// Deployment is one upload of a project's files
+type Deployment struct {
+	ID        string `json:"id"`
+	TenantID  string `json:"tenant_id"`
+	ProjectID string `json:"project_id"`
+	UserID    string `json:"user_id"`
+	// UserEmail is the email of the user who deployed, when the API
+	// includes it
+	UserEmail *string `json:"user_email,omitempty"`
+	URL       string  `json:"url"`
+	// Status is StatusPending, StatusSuccess or StatusFailed
+	Status        string  `json:"status"`
+	CommitSHA     *string `json:"commit_sha,omitempty"`
+	CommitBranch  *string `json:"commit_branch,omitempty"`
+	CommitMessage *string `json:"commit_message,omitempty"`
+	CommitURL     *string `json:"commit_url,omitempty"`
+	// ArchiveSize is the size of the uploaded archive in bytes
+	ArchiveSize int64 `json:"archive_size,omitempty"`
+	// DurationMS is how long the deployment took to finish; 0 while it
+	// is pending
+	DurationMS int64     `json:"duration_ms,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// DeployRequest is an upload of a built site
+type DeployRequest struct {
+	// Project is the name of the project; it is created if it doesn't exist
+	Project string
+	// Archive is the zip of the build output
+	Archive []byte
+	// SpaConfig is the godeploy.config.json of the project, if any
+	SpaConfig []byte
+
+	// Commit metadata shown in the deployment history; all optional
+	CommitSHA     string
+	CommitBranch  string
+	CommitMessage string
+	CommitURL     string
+
+	// ClearCache purges the CDN cache once the deployment succeeds
+	ClearCache bool
+}
+
+// DeploymentListOptions filters and pages ListDeployments
+type DeploymentListOptions struct {
+	// Cursor is the NextCursor of the previous page; empty for the first
+	Cursor string
+	// Limit is the page size; 0 uses the server's default
+	Limit int
+	// Status keeps deployments with this status
+	Status string
+	// Branch keeps deployments of this commit branch
+	Branch string
+	// Author keeps deployments by the user with this email or ID
+	Author string
+	// Since and Until keep deployments created in this range; zero values
+	// leave it open
+	Since time.Time
+	Until time.Time
+}
+
+// DeploymentList is a page of deployments, newest first
+type DeploymentList struct {
+	Deployments []Deployment `json:"deployments"`
+	// NextCursor fetches the next page; empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// Deploy uploads a site. Retries of the upload carry the same idempotency
+// key, so the server creates the deployment once. The upload uses the
+// deploy timeout rather than the client's.
+func (c *Client) Deploy(ctx context.Context, deploy DeployRequest) (*Deployment, error) {
+	return c.upload(ctx, "/api/deploy", deploy)
+}
+
+// CreatePreview uploads a site as a temporary preview deployment that
+// doesn't replace the active one
+func (c *Client) CreatePreview(ctx context.Context, deploy DeployRequest) (*Deployment, error) {
+	return c.upload(ctx, "/api/deploys/preview", deploy)
+}
+
+// GetDeployment returns a deployment by ID
+func (c *Client) GetDeployment(ctx context.Context, deploymentID string) (*Deployment, error) {
+	var deployment Deployment
+	if err := c.call(ctx, http.MethodGet, pathf("/api/deploys/%s", deploymentID), nil, nil, &deployment); err != nil {
+		return nil, err
+	}
+	return &deployment, nil
+}
+
+// ListDeployments returns a page of a project's deployments. opts may be
+// nil.
+func (c *Client) ListDeployments(ctx context.Context, projectID string, opts *DeploymentListOptions) (*DeploymentList, error) {
+	query := url.Values{}
+	if opts != nil {
+		setQuery(query, "cursor", opts.Cursor)
+		setQuery(query, "status", opts.Status)
+		setQuery(query, "branch", opts.Branch)
+		setQuery(query, "author", opts.Author)
+		if !opts.Since.IsZero() {
+			query.Set("since", opts.Since.UTC().Format(time.RFC3339Nano))
+		}
+		if !opts.Until.IsZero() {
+			query.Set("until", opts.Until.UTC().Format(time.RFC3339Nano))
+		}
+		if opts.Limit > 0 {
+			query.Set("limit", strconv.Itoa(opts.Limit))
+		}
+	}
+
+	var list DeploymentList
+	if err := c.call(ctx, http.MethodGet, pathf("/api/projects/%s/deployments", projectID), query, nil, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// CompareDeployments lists the files that differ between two deployments
+func (c *Client) CompareDeployments(ctx context.Context, fromID, toID string) (*Diff, error) {
+	query := url.Values{"from": {fromID}, "to": {toID}}
+
+	var diff Diff
+	if err := c.call(ctx, http.MethodGet, "/api/deploys/compare", query, nil, &diff); err != nil {
+		return nil, err
+	}
+	return &diff, nil
+}
+
+// upload sends deploy as a multipart form to path
+func (c *Client) upload(ctx context.Context, path string, deploy DeployRequest) (*Deployment, error) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	if err := writer.WriteField("project", deploy.Project); err != nil {
+		return nil, fmt.Errorf("failed to write project field: %w", err)
+	}
+	if deploy.SpaConfig != nil {
+		part, err := writer.CreateFormFile("spa_config", "godeploy.config.json")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create spa_config form file: %w", err)
+		}
+		if _, err := part.Write(deploy.SpaConfig); err != nil {
+			return nil, fmt.Errorf("failed to write spa_config data: %w", err)
+		}
+	}
+	part, err := writer.CreateFormFile("archive", deploy.Project+".zip")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create archive form file: %w", err)
+	}
+	if _, err := part.Write(deploy.Archive); err != nil {
+		return nil, fmt.Errorf("failed to write archive data: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close multipart writer: %w", err)
+	}
+
+	query := url.Values{}
+	query.Set("project", deploy.Project)
+	setQuery(query, "commit_sha", deploy.CommitSHA)
+	setQuery(query, "commit_branch", deploy.CommitBranch)
+	setQuery(query, "commit_message", deploy.CommitMessage)
+	setQuery(query, "commit_url", deploy.CommitURL)
+	if deploy.ClearCache {
+		query.Set("clear_cache", "true")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path+"?"+query.Encode(), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Accept", "application/json")
+
+	idempotencyKey, err := newIdempotencyKey()
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(IdempotencyKeyHeader, idempotencyKey)
+
+	var deployment Deployment
+	if err := c.withTimeout(c.deployTimeout).do(req, &deployment); err != nil {
+		return nil, err
+	}
+	return &deployment, nil
+}
+
+// setQuery sets key in query unless value is empty
+func setQuery(query url.Values, key, value string) {
+	if value != "" {
+		query.Set(key, value)
+	}
+}
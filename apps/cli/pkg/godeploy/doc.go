@@ -0,0 +1,35 @@
+// Package godeploy is the Go client for the GoDeploy API. It is what the
+// godeploy CLI is built on, and the supported way to deploy and manage
+// projects from other Go programs.
+//
+// Create a Client with New and authenticate it with an API token, created
+// with `godeploy tokens create`:
+//
+//	client := godeploy.New(godeploy.WithToken(os.Getenv("GODEPLOY_TOKEN")))
+//	projects, err := client.ListProjects(ctx)
+//
+// Code that should be testable without a server depends on the API
+// interface, which Client implements, instead of on *Client.
+//
+// # Errors
+//
+// Every failed request returns an *Error. Its Kind is one of the sentinel
+// errors of this package, so callers branch with errors.Is:
+//
+//	if errors.Is(err, godeploy.ErrNotFound) { ... }
+//
+// Endpoints the server doesn't implement yet fail with ErrNotImplemented.
+//
+// # Versioning
+//
+// The package follows semantic versioning, reported by Version. Within a
+// major version, exported functions, methods and fields are not removed or
+// changed; minor versions add endpoints, options and response fields.
+// Because new endpoints add methods to API, implementations outside this
+// package, such as test doubles, should embed API so they keep compiling.
+// Responses ignore unknown JSON fields, so an older client keeps working
+// against a newer server.
+package godeploy
+
+// Version is the version of this package, sent in the User-Agent header
+const Version = "1.0.0"
@@ -0,0 +1,62 @@
+package godeploy
+
+import (
+	"context"
+	"net/http"
+)
+
+// DomainAvailability is the result of CheckDomainAvailability
+type DomainAvailability struct {
+	Available bool `json:"available"`
+	// Reason says why the domain isn't available
+	Reason string `json:"reason,omitempty"`
+}
+
+// DomainValidation is the result of ValidateDomain
+type DomainValidation struct {
+	IsValid bool `json:"isValid"`
+	// CNAMERecord is the CNAME the domain currently points at
+	CNAMERecord string `json:"cnameRecord,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// CNAMETarget returns the hostname a custom domain's CNAME record must
+// point at
+func (c *Client) CNAMETarget(ctx context.Context) (string, error) {
+	var resp struct {
+		Target string `json:"target"`
+	}
+	if err := c.call(ctx, http.MethodGet, "/api/domains/cname-target", nil, nil, &resp); err != nil {
+		return "", err
+	}
+	return resp.Target, nil
+}
+
+// CheckDomainAvailability reports whether domain can be used as a custom
+// domain. projectID excludes that project's own domain and may be empty.
+func (c *Client) CheckDomainAvailability(ctx context.Context, domain, projectID string) (*DomainAvailability, error) {
+	body := struct {
+		Domain    string `json:"domain"`
+		ProjectID string `json:"projectId,omitempty"`
+	}{domain, projectID}
+
+	var availability DomainAvailability
+	if err := c.call(ctx, http.MethodPost, "/api/domains/check-availability", nil, body, &availability); err != nil {
+		return nil, err
+	}
+	return &availability, nil
+}
+
+// ValidateDomain checks that the CNAME record of domain points at
+// CNAMETarget
+func (c *Client) ValidateDomain(ctx context.Context, domain string) (*DomainValidation, error) {
+	body := struct {
+		Domain string `json:"domain"`
+	}{domain}
+
+	var validation DomainValidation
+	if err := c.call(ctx, http.MethodPost, "/api/domains/validate", nil, body, &validation); err != nil {
+		return nil, err
+	}
+	return &validation, nil
+}
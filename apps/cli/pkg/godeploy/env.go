@@ -0,0 +1,54 @@
+package godeploy
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// EnvVar is an environment variable available to a project's builds
+type EnvVar struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	// Secret values are write-only and returned empty
+	Secret    bool      `json:"secret,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ListEnv returns the environment variables of a project
+func (c *Client) ListEnv(ctx context.Context, projectID string) ([]EnvVar, error) {
+	var vars []EnvVar
+	if err := c.call(ctx, http.MethodGet, pathf("/api/projects/%s/env", projectID), nil, nil, &vars); err != nil {
+		return nil, err
+	}
+	return vars, nil
+}
+
+// GetEnv returns one environment variable of a project
+func (c *Client) GetEnv(ctx context.Context, projectID, key string) (*EnvVar, error) {
+	var v EnvVar
+	if err := c.call(ctx, http.MethodGet, pathf("/api/projects/%s/env/%s", projectID, key), nil, nil, &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// SetEnv creates or replaces an environment variable of a project
+func (c *Client) SetEnv(ctx context.Context, projectID string, v EnvVar) (*EnvVar, error) {
+	body := struct {
+		Key    string `json:"key"`
+		Value  string `json:"value"`
+		Secret bool   `json:"secret,omitempty"`
+	}{v.Key, v.Value, v.Secret}
+
+	var saved EnvVar
+	if err := c.call(ctx, http.MethodPost, pathf("/api/projects/%s/env", projectID), nil, body, &saved); err != nil {
+		return nil, err
+	}
+	return &saved, nil
+}
+
+// DeleteEnv removes an environment variable from a project
+func (c *Client) DeleteEnv(ctx context.Context, projectID, key string) error {
+	return c.call(ctx, http.MethodDelete, pathf("/api/projects/%s/env/%s", projectID, key), nil, nil, nil)
+}
@@ -0,0 +1,213 @@
+package godeploy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// Sentinel errors classifying API failures. Every error returned by a Client
+// method for a failed request is an *Error whose Kind is one of these, so
+// callers can use errors.Is instead of matching on the message.
+var (
+	// ErrUnauthorized is returned when the server rejects the credentials
+	ErrUnauthorized = errors.New("not authenticated")
+	// ErrForbidden is returned when the credentials lack access
+	ErrForbidden = errors.New("permission denied")
+	// ErrNotFound is returned when the requested resource doesn't exist
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is returned when the resource already exists or changed
+	ErrConflict = errors.New("conflict")
+	// ErrInvalidInput is returned when the server rejects the request data
+	ErrInvalidInput = errors.New("invalid request")
+	// ErrQuotaExceeded is returned when a plan limit has been reached
+	ErrQuotaExceeded = errors.New("quota exceeded")
+	// ErrRateLimited is returned when too many requests were made
+	ErrRateLimited = errors.New("rate limited")
+	// ErrTimeout is returned when the API didn't answer in time
+	ErrTimeout = errors.New("request timed out")
+	// ErrUnavailable is returned when the API can't be reached or failed
+	ErrUnavailable = errors.New("API unavailable")
+	// ErrNotImplemented is returned by endpoints the server doesn't
+	// implement yet
+	ErrNotImplemented = errors.New("not implemented by the server")
+)
+
+// Error is a failed API request
+type Error struct {
+	// StatusCode is the HTTP status, or 0 when no response was received
+	StatusCode int
+	// Code is the machine-readable error code sent by the server, if any
+	Code string
+	// Message is the human-readable error
+	Message string
+	// RequestID identifies the request in server logs, if the server sent one
+	RequestID string
+	// Retryable reports whether the same request may succeed later
+	Retryable bool
+	// Kind is the sentinel error classifying the failure
+	Kind error
+	// Err is the underlying error when no response was received
+	Err error
+
+	// body is the raw response, shown when the server sent no message
+	body string
+}
+
+func (e *Error) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("unexpected status code: %d, body: %s", e.StatusCode, e.body)
+	}
+	if e.Kind != nil {
+		return e.Kind.Error()
+	}
+	return "API request failed"
+}
+
+// Unwrap lets errors.Is match Kind and the underlying error
+func (e *Error) Unwrap() []error {
+	var errs []error
+	if e.Kind != nil {
+		errs = append(errs, e.Kind)
+	}
+	if e.Err != nil {
+		errs = append(errs, e.Err)
+	}
+	return errs
+}
+
+// IsUnavailable reports whether err means the API could not be reached or
+// failed on its side, so the same request may succeed later. Other errors,
+// such as a response that can't be decoded, are not.
+func IsUnavailable(err error) bool {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Retryable
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// ResponseError builds the Error for a non-success response, classified by
+// the server's error code and the status code. body is the response body.
+// Message is left empty when the server didn't send one, so callers can
+// supply their own.
+func ResponseError(resp *http.Response, body []byte) *Error {
+	apiErr := &Error{
+		StatusCode: resp.StatusCode,
+		RequestID:  resp.Header.Get("X-Request-Id"),
+		body:       string(body),
+	}
+
+	var errResp struct {
+		Error     string `json:"error"`
+		Message   string `json:"message"`
+		Code      string `json:"code"`
+		RequestID string `json:"request_id"`
+	}
+	if err := json.Unmarshal(body, &errResp); err == nil {
+		apiErr.Code = errResp.Code
+		apiErr.Message = errResp.Error
+		// Validation failures put the generic status text in "error" and
+		// the useful part in "message"
+		if errResp.Message != "" && (errResp.Error == "" || errResp.Error == http.StatusText(resp.StatusCode)) {
+			apiErr.Message = errResp.Message
+		}
+		if apiErr.RequestID == "" {
+			apiErr.RequestID = errResp.RequestID
+		}
+	}
+	apiErr.Kind = kindFor(resp.StatusCode, apiErr.Code)
+	apiErr.Retryable = retryableStatus(resp.StatusCode)
+	return apiErr
+}
+
+// requestError wraps a failure to get any response from the API. A
+// transport may fail with an *Error to report a failure of its own, such
+// as a bad TLS setting; it is returned as is.
+func requestError(err error) *Error {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+
+	apiErr = &Error{
+		Message:   fmt.Sprintf("failed to send request: %v", err),
+		Retryable: true,
+		Kind:      ErrUnavailable,
+		Err:       err,
+	}
+
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.Canceled):
+		apiErr.Kind, apiErr.Retryable = nil, false
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		apiErr.Kind = ErrTimeout
+	}
+	return apiErr
+}
+
+// authError reports that no usable credentials are available
+func authError(message string, err error) *Error {
+	return &Error{
+		Message: fmt.Sprintf("%s: %v", message, err),
+		Kind:    ErrUnauthorized,
+		Err:     err,
+	}
+}
+
+// kindFor classifies a response by server error code, then status code
+func kindFor(statusCode int, code string) error {
+	switch code {
+	case "quota_exceeded":
+		return ErrQuotaExceeded
+	case "rate_limited":
+		return ErrRateLimited
+	case "FST_ERR_VALIDATION":
+		return ErrInvalidInput
+	}
+
+	switch statusCode {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity, http.StatusRequestEntityTooLarge:
+		return ErrInvalidInput
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusPaymentRequired:
+		return ErrQuotaExceeded
+	case http.StatusForbidden:
+		return ErrForbidden
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusConflict:
+		return ErrConflict
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return ErrTimeout
+	case http.StatusNotImplemented:
+		return ErrNotImplemented
+	}
+	if statusCode >= http.StatusInternalServerError {
+		return ErrUnavailable
+	}
+	return nil
+}
+
+// retryableStatus reports whether a request failing with statusCode may
+// succeed when sent again
+func retryableStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests,
+		http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package godeploy_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"time"
+
+	"github.com/silvabyte/godeploy/pkg/godeploy"
+)
+
+// exampleServer stands in for the API in the examples
+func exampleServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/projects":
+			_, _ = w.Write([]byte(`[{"id":"p1","name":"web","subdomain":"web","url":"https://web.godeploy.app"}]`))
+		case "/api/deploy":
+			_, _ = w.Write([]byte(`{"id":"d1","project_id":"p1","url":"https://web.godeploy.app","status":"success"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"Project not found"}`))
+		}
+	}))
+}
+
+func Example() {
+	server := exampleServer()
+	defer server.Close()
+
+	client := godeploy.New(
+		godeploy.WithBaseURL(server.URL),
+		godeploy.WithToken("gdp_example"),
+	)
+
+	projects, err := client.ListProjects(context.Background())
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	for _, p := range projects {
+		fmt.Println(p.Name, p.URL)
+	}
+	// Output: web https://web.godeploy.app
+}
+
+func ExampleClient_Deploy() {
+	server := exampleServer()
+	defer server.Close()
+
+	client := godeploy.New(godeploy.WithBaseURL(server.URL), godeploy.WithToken("gdp_example"))
+
+	// Archive is the zip of the build output, e.g. from archive/zip
+	deployment, err := client.Deploy(context.Background(), godeploy.DeployRequest{
+		Project:   "web",
+		Archive:   []byte("zip"),
+		CommitSHA: "4f2c1e9",
+	})
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	fmt.Println(deployment.Status, deployment.URL)
+	// Output: success https://web.godeploy.app
+}
+
+func ExampleNew_options() {
+	client := godeploy.New(
+		godeploy.WithBaseURL("https://godeploy.internal.example.com"),
+		godeploy.WithToken(os.Getenv("GODEPLOY_TOKEN")),
+		godeploy.WithHTTPClient(&http.Client{Timeout: 10 * time.Second}),
+		godeploy.WithRetry(godeploy.NoRetry),
+		godeploy.WithDeployTimeout(30*time.Minute),
+	)
+	fmt.Println(client.BaseURL())
+	// Output: https://godeploy.internal.example.com
+}
+
+func ExampleError() {
+	server := exampleServer()
+	defer server.Close()
+
+	client := godeploy.New(godeploy.WithBaseURL(server.URL), godeploy.WithToken("gdp_example"))
+
+	_, err := client.GetDeployment(context.Background(), "missing")
+	if errors.Is(err, godeploy.ErrNotFound) {
+		var apiErr *godeploy.Error
+		errors.As(err, &apiErr)
+		fmt.Println(apiErr.StatusCode, apiErr.Message)
+	}
+	// Output: 404 Project not found
+}
+
+// stubProjects is a test double that implements only the methods a test
+// needs; embedding the interface keeps it compiling as methods are added
+type stubProjects struct {
+	godeploy.API
+}
+
+func (stubProjects) ListProjects(context.Context) ([]godeploy.Project, error) {
+	return []godeploy.Project{{Name: "stub"}}, nil
+}
+
+func ExampleAPI() {
+	// Code under test depends on godeploy.API rather than *godeploy.Client
+	countProjects := func(api godeploy.API) int {
+		projects, _ := api.ListProjects(context.Background())
+		return len(projects)
+	}
+
+	fmt.Println(countProjects(stubProjects{}))
+	// Output: 1
+}
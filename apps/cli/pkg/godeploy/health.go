@@ -0,0 +1,23 @@
+package godeploy
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// ServerHealth is the status of the API server
+type ServerHealth struct {
+	Status    string    `json:"status"`
+	Timestamp time.Time `json:"timestamp"`
+	Version   string    `json:"version"`
+}
+
+// ServerStatus checks that the API server is up. It needs no credentials.
+func (c *Client) ServerStatus(ctx context.Context) (*ServerHealth, error) {
+	var health ServerHealth
+	if err := c.call(ctx, http.MethodGet, "/health", nil, nil, &health); err != nil {
+		return nil, err
+	}
+	return &health, nil
+}
@@ -0,0 +1,299 @@
+package godeploy
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LogEntry is one line of a project or build log
+type LogEntry struct {
+	Time time.Time `json:"time"`
+	// Level is "debug", "info", "warn" or "error"
+	Level   string `json:"level"`
+	Message string `json:"message"`
+	// Source is the component that logged the line, e.g. "build" or "deploy"
+	Source string `json:"source,omitempty"`
+	// DeploymentID is the deployment the line belongs to, if any
+	DeploymentID string `json:"deployment_id,omitempty"`
+	// Cursor continues after this entry, so a followed log can resume
+	// from it
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// LogOptions pages and filters GetProjectLogs and GetBuildLogs
+type LogOptions struct {
+	// Cursor is the NextCursor of the previous page; empty for the start
+	Cursor string
+	// Limit is the page size; 0 uses the server's default. Following
+	// ignores it.
+	Limit int
+	// Tail asks for the last Limit entries after Cursor instead of the
+	// first ones, so recent lines take a single request. Following
+	// ignores it.
+	Tail bool
+	// DeploymentID limits project logs to one deployment; when following,
+	// the stream ends when that deployment finishes instead of the latest
+	DeploymentID string
+}
+
+// LogList is a page of log entries, oldest first
+type LogList struct {
+	Entries []LogEntry `json:"entries"`
+	// NextCursor continues after the last entry; it is set on the last
+	// page too, so a caller can poll for new entries
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// GetProjectLogs returns a page of the deploy logs of a project. opts may
+// be nil.
+func (c *Client) GetProjectLogs(ctx context.Context, projectID string, opts *LogOptions) (*LogList, error) {
+	return c.getLogs(ctx, pathf("/api/projects/%s/logs", projectID), opts)
+}
+
+// GetBuildLogs returns a page of the logs of a build. opts may be nil.
+func (c *Client) GetBuildLogs(ctx context.Context, projectID, buildID string, opts *LogOptions) (*LogList, error) {
+	return c.getLogs(ctx, pathf("/api/projects/%s/builds/%s/logs", projectID, buildID), opts)
+}
+
+func (c *Client) getLogs(ctx context.Context, path string, opts *LogOptions) (*LogList, error) {
+	query := url.Values{}
+	if opts != nil {
+		setQuery(query, "cursor", opts.Cursor)
+		setQuery(query, "deployment_id", opts.DeploymentID)
+		if opts.Limit > 0 {
+			query.Set("limit", strconv.Itoa(opts.Limit))
+		}
+		if opts.Tail {
+			query.Set("tail", "true")
+		}
+	}
+
+	var list LogList
+	if err := c.call(ctx, http.MethodGet, path, query, nil, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// LogHandler is called with each entry of a followed log, in order. An
+// error stops following and is returned as it is.
+type LogHandler func(LogEntry) error
+
+// FollowProjectLogs streams the deploy logs of a project, calling handle
+// with each entry after opts.Cursor, until the deployment being logged
+// finishes or the context is done. opts may be nil. See followLogs for how
+// dropped streams are handled.
+func (c *Client) FollowProjectLogs(ctx context.Context, projectID string, opts *LogOptions, handle LogHandler) error {
+	return c.followLogs(ctx, pathf("/api/projects/%s/logs", projectID), opts, handle)
+}
+
+// FollowBuildLogs streams the logs of a build like FollowProjectLogs, until
+// the build finishes
+func (c *Client) FollowBuildLogs(ctx context.Context, projectID, buildID string, opts *LogOptions, handle LogHandler) error {
+	return c.followLogs(ctx, pathf("/api/projects/%s/builds/%s/logs", projectID, buildID), opts, handle)
+}
+
+// followLogs requests path with follow=true and reads the response as
+// server-sent events or newline-delimited JSON, whichever the server
+// answers with. A stream that drops before the server ends it is
+// reconnected from the cursor of the last entry received, after the retry
+// policy's BaseDelay. Following fails after MaxAttempts connections in a row
+// that fail or drop without delivering an entry.
+func (c *Client) followLogs(ctx context.Context, path string, opts *LogOptions, handle LogHandler) error {
+	var stream logStream
+	if opts != nil {
+		stream.cursor = opts.Cursor
+		stream.deploymentID = opts.DeploymentID
+	}
+	// The stream stays open as long as the deployment runs
+	client := c.withTimeout(0)
+
+	var failures int
+	for {
+		received := stream.received
+		ended, err := client.readLogStream(ctx, path, &stream, handle)
+		if ended || stream.stopErr != nil {
+			return err
+		}
+		if err != nil && !IsUnavailable(err) {
+			return err
+		}
+		if ctx.Err() != nil {
+			return requestError(ctx.Err())
+		}
+
+		if stream.received > received {
+			failures = 0
+		}
+		failures++
+		if failures >= max(c.retry.MaxAttempts, 1) {
+			if err == nil {
+				err = &Error{Message: "log stream ended before the deployment finished", Retryable: true, Kind: ErrUnavailable}
+			}
+			return err
+		}
+		if err := sleep(ctx, c.retry.BaseDelay); err != nil {
+			return requestError(err)
+		}
+	}
+}
+
+// logStream is the state of a followed log across reconnects
+type logStream struct {
+	cursor       string
+	deploymentID string
+	// received counts the entries handled so far
+	received int
+	// stopErr is an error retrying can't fix, such as one returned by
+	// the handler or an entry that can't be decoded
+	stopErr error
+}
+
+// readLogStream opens one connection of a followed log and handles its
+// entries. ended reports whether the server ended the stream.
+func (c *Client) readLogStream(ctx context.Context, path string, stream *logStream, handle LogHandler) (ended bool, err error) {
+	query := url.Values{"follow": {"true"}}
+	setQuery(query, "cursor", stream.cursor)
+	setQuery(query, "deployment_id", stream.deploymentID)
+	req, err := c.newRequest(ctx, http.MethodGet, path, query, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "text/event-stream, application/x-ndjson")
+	if stream.cursor != "" {
+		req.Header.Set("Last-Event-ID", stream.cursor)
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(resp.Body)
+		return false, ResponseError(resp, body)
+	}
+
+	// deliver hands one entry to the handler and remembers its cursor
+	deliver := func(data []byte) error {
+		var entry LogEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			stream.stopErr = fmt.Errorf("failed to decode log entry: %w", err)
+			return stream.stopErr
+		}
+		if err := handle(entry); err != nil {
+			stream.stopErr = err
+			return err
+		}
+		stream.received++
+		if entry.Cursor != "" {
+			stream.cursor = entry.Cursor
+		}
+		return nil
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLogLine)
+	switch mediaType := strings.TrimSpace(strings.Split(resp.Header.Get("Content-Type"), ";")[0]); mediaType {
+	case "text/event-stream":
+		ended, err = readEvents(scanner, stream, deliver)
+	case "application/x-ndjson":
+		ended, err = readJSONLines(scanner, deliver)
+	default:
+		return false, &Error{
+			StatusCode: resp.StatusCode,
+			Message:    fmt.Sprintf("server doesn't stream logs (got %s)", mediaType),
+			RequestID:  resp.Header.Get("X-Request-Id"),
+			Kind:       ErrNotImplemented,
+		}
+	}
+	if err != nil || ended {
+		return ended, err
+	}
+	if err := scanner.Err(); err != nil {
+		return false, requestError(err)
+	}
+	return false, nil
+}
+
+// maxLogLine is the longest line of a log stream that can be read
+const maxLogLine = 1024 * 1024
+
+// readEvents reads server-sent events. "log" events (or events without a
+// type) carry an entry as JSON; their id is the cursor to resume from. An
+// "end" event ends the stream.
+func readEvents(scanner *bufio.Scanner, stream *logStream, deliver func([]byte) error) (bool, error) {
+	var event, id string
+	var data []byte
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			// A blank line dispatches the event
+			switch event {
+			case "end":
+				return true, nil
+			case "", "log", "message":
+				if len(data) > 0 {
+					if err := deliver(data); err != nil {
+						return false, err
+					}
+					if id != "" {
+						stream.cursor = id
+					}
+				}
+			}
+			event, id, data = "", "", nil
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			// Comment, e.g. a keep-alive
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event = value
+		case "id":
+			id = value
+		case "data":
+			if data != nil {
+				data = append(data, '\n')
+			}
+			data = append(data, value...)
+		}
+	}
+	return false, nil
+}
+
+// readJSONLines reads one entry per line; the line {"end":true} ends the
+// stream
+func readJSONLines(scanner *bufio.Scanner, deliver func([]byte) error) (bool, error) {
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var end struct {
+			End bool `json:"end"`
+		}
+		if json.Unmarshal(line, &end) == nil && end.End {
+			return true, nil
+		}
+		if err := deliver(line); err != nil {
+			return false, err
+		}
+	}
+	return false, nil
+}
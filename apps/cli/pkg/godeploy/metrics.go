@@ -0,0 +1,169 @@
+package godeploy
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ProjectMetrics summarizes the deployments and traffic of a project
+type ProjectMetrics struct {
+	Deployments        int        `json:"deployments"`
+	FailedDeployments  int        `json:"failed_deployments"`
+	AverageDurationSec float64    `json:"average_duration_seconds"`
+	LastDeployAt       *time.Time `json:"last_deploy_at,omitempty"`
+	Requests           int64      `json:"requests"`
+	BandwidthBytes     int64      `json:"bandwidth_bytes"`
+}
+
+// Analytics is the visitor traffic of a project in a time range
+type Analytics struct {
+	From     time.Time  `json:"from"`
+	To       time.Time  `json:"to"`
+	Visitors int64      `json:"visitors"`
+	Views    int64      `json:"page_views"`
+	Series   []DayCount `json:"series"`
+}
+
+// DayCount is a count for one day
+type DayCount struct {
+	// Date is formatted as 2006-01-02
+	Date  string `json:"date"`
+	Count int    `json:"count"`
+}
+
+// MetricsPage is a page showing the deploy metrics of some projects
+type MetricsPage struct {
+	ID          string    `json:"id"`
+	TenantID    string    `json:"tenant_id"`
+	OwnerID     string    `json:"owner_id"`
+	Slug        string    `json:"slug"`
+	Title       *string   `json:"title,omitempty"`
+	Description *string   `json:"description,omitempty"`
+	ProjectIDs  []string  `json:"project_ids"`
+	IsPublic    bool      `json:"is_public"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// MetricsPageRequest creates or updates a metrics page. Nil fields are
+// left unchanged by UpdateMetricsPage.
+type MetricsPageRequest struct {
+	// Slug is at least 3 characters; the server picks one when empty
+	Slug        string   `json:"slug,omitempty"`
+	Title       *string  `json:"title,omitempty"`
+	Description *string  `json:"description,omitempty"`
+	ProjectIDs  []string `json:"projectIds,omitempty"`
+	IsPublic    *bool    `json:"isPublic,omitempty"`
+}
+
+// PublicMetricsPage is what anyone can see of a public metrics page
+type PublicMetricsPage struct {
+	Slug        string   `json:"slug"`
+	Title       *string  `json:"title"`
+	Description *string  `json:"description"`
+	ProjectIDs  []string `json:"projectIds"`
+	IsPublic    bool     `json:"isPublic"`
+}
+
+// DeployFrequency is the number of successful deploys per day of the
+// projects of a public metrics page
+type DeployFrequency struct {
+	Range struct {
+		From     string `json:"from"`
+		To       string `json:"to"`
+		Interval string `json:"interval"`
+	} `json:"range"`
+	Series []struct {
+		ProjectID string     `json:"projectId"`
+		Data      []DayCount `json:"data"`
+	} `json:"series"`
+	Totals struct {
+		Overall   int            `json:"overall"`
+		ByProject map[string]int `json:"byProject"`
+	} `json:"totals"`
+}
+
+// GetProjectMetrics returns the deploy and traffic metrics of a project
+func (c *Client) GetProjectMetrics(ctx context.Context, projectID string) (*ProjectMetrics, error) {
+	var metrics ProjectMetrics
+	if err := c.call(ctx, http.MethodGet, pathf("/api/projects/%s/metrics", projectID), nil, nil, &metrics); err != nil {
+		return nil, err
+	}
+	return &metrics, nil
+}
+
+// GetAnalytics returns the visitor traffic of a project between from and
+// to. Zero times use the server's default range.
+func (c *Client) GetAnalytics(ctx context.Context, projectID string, from, to time.Time) (*Analytics, error) {
+	var analytics Analytics
+	if err := c.call(ctx, http.MethodGet, pathf("/api/projects/%s/analytics/data", projectID), rangeQuery(from, to), nil, &analytics); err != nil {
+		return nil, err
+	}
+	return &analytics, nil
+}
+
+// ListMetricsPages returns the metrics pages of the authenticated tenant
+func (c *Client) ListMetricsPages(ctx context.Context) ([]MetricsPage, error) {
+	var pages []MetricsPage
+	if err := c.call(ctx, http.MethodGet, "/api/metrics/pages", nil, nil, &pages); err != nil {
+		return nil, err
+	}
+	return pages, nil
+}
+
+// CreateMetricsPage creates a metrics page for at least one project
+func (c *Client) CreateMetricsPage(ctx context.Context, req MetricsPageRequest) (*MetricsPage, error) {
+	var page MetricsPage
+	if err := c.call(ctx, http.MethodPost, "/api/metrics/pages", nil, req, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// UpdateMetricsPage changes the fields of a metrics page set in req
+func (c *Client) UpdateMetricsPage(ctx context.Context, pageID string, req MetricsPageRequest) (*MetricsPage, error) {
+	var page MetricsPage
+	if err := c.call(ctx, http.MethodPatch, pathf("/api/metrics/pages/%s", pageID), nil, req, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// DeleteMetricsPage deletes a metrics page
+func (c *Client) DeleteMetricsPage(ctx context.Context, pageID string) error {
+	return c.call(ctx, http.MethodDelete, pathf("/api/metrics/pages/%s", pageID), nil, nil, nil)
+}
+
+// GetPublicMetricsPage returns a public metrics page by slug. It needs no
+// credentials.
+func (c *Client) GetPublicMetricsPage(ctx context.Context, slug string) (*PublicMetricsPage, error) {
+	var page PublicMetricsPage
+	if err := c.call(ctx, http.MethodGet, pathf("/api/public/metrics/%s", slug), nil, nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// GetDeployFrequency returns the daily deploys of the projects of a public
+// metrics page between from and to. Zero times default to the last 30 days.
+func (c *Client) GetDeployFrequency(ctx context.Context, slug string, from, to time.Time) (*DeployFrequency, error) {
+	var frequency DeployFrequency
+	if err := c.call(ctx, http.MethodGet, pathf("/api/public/metrics/%s/deploy-frequency", slug), rangeQuery(from, to), nil, &frequency); err != nil {
+		return nil, err
+	}
+	return &frequency, nil
+}
+
+// rangeQuery encodes the non-zero bounds of a time range
+func rangeQuery(from, to time.Time) url.Values {
+	query := url.Values{}
+	if !from.IsZero() {
+		query.Set("from", from.UTC().Format(time.RFC3339))
+	}
+	if !to.IsZero() {
+		query.Set("to", to.UTC().Format(time.RFC3339))
+	}
+	return query
+}
@@ -0,0 +1,206 @@
+package godeploy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Project is a deployable site
+type Project struct {
+	ID          string    `json:"id"`
+	TenantID    string    `json:"tenant_id"`
+	OwnerID     string    `json:"owner_id"`
+	Name        string    `json:"name"`
+	Subdomain   string    `json:"subdomain"`
+	Description *string   `json:"description"`
+	Domain      *string   `json:"domain,omitempty"`
+	URL         string    `json:"url,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// CreateProjectRequest is the body of CreateProject
+type CreateProjectRequest struct {
+	// Name is at least 3 characters; the subdomain is derived from it
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// ProjectStatus is the live state of a project
+type ProjectStatus struct {
+	Project Project `json:"project"`
+	// Active is the deployment being served, if any
+	Active *Deployment `json:"active_deployment"`
+	// InProgress is a deployment that hasn't finished yet, if any
+	InProgress *Deployment `json:"in_progress_deployment,omitempty"`
+	// Domains are the hostnames serving the project
+	Domains []string `json:"domains"`
+	// Health is the result of the last health check
+	Health *Health `json:"health,omitempty"`
+}
+
+// Health is the result of a health check of a project's URL
+type Health struct {
+	// Status is "healthy", "degraded" or "down"
+	Status         string    `json:"status"`
+	StatusCode     int       `json:"status_code,omitempty"`
+	ResponseTimeMS int       `json:"response_time_ms,omitempty"`
+	CheckedAt      time.Time `json:"checked_at"`
+}
+
+// Diff lists the files that differ between two deployments
+type Diff struct {
+	From     string   `json:"from"`
+	To       string   `json:"to"`
+	Added    []string `json:"added"`
+	Modified []string `json:"modified"`
+	Removed  []string `json:"removed"`
+}
+
+// ListProjects returns all projects of the authenticated tenant, following
+// pages when the server paginates
+func (c *Client) ListProjects(ctx context.Context) ([]Project, error) {
+	var projects []Project
+	for offset := 0; ; {
+		query := url.Values{}
+		if offset > 0 {
+			query.Set("offset", strconv.Itoa(offset))
+		}
+
+		var raw json.RawMessage
+		if err := c.call(ctx, http.MethodGet, "/api/projects", query, nil, &raw); err != nil {
+			return nil, err
+		}
+
+		// The server returns either a plain array or a page envelope
+		var list []Project
+		var page struct {
+			Data []Project `json:"data"`
+			Meta struct {
+				Total int `json:"total"`
+			} `json:"meta"`
+		}
+		if err := json.Unmarshal(raw, &list); err == nil {
+			return list, nil
+		}
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		projects = append(projects, page.Data...)
+		offset += len(page.Data)
+		if len(page.Data) == 0 || offset >= page.Meta.Total {
+			return projects, nil
+		}
+	}
+}
+
+// FindProject returns the project whose ID, name or subdomain matches
+// nameOrID. IDs are matched first so a renamed project is still found by ID.
+func (c *Client) FindProject(ctx context.Context, nameOrID string) (*Project, error) {
+	projects, err := c.ListProjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range projects {
+		if projects[i].ID == nameOrID {
+			return &projects[i], nil
+		}
+	}
+	for i := range projects {
+		if projects[i].Name == nameOrID || strings.EqualFold(projects[i].Subdomain, nameOrID) {
+			return &projects[i], nil
+		}
+	}
+
+	return nil, &Error{Message: fmt.Sprintf("project '%s' not found", nameOrID), Kind: ErrNotFound}
+}
+
+// CreateProject creates a project
+func (c *Client) CreateProject(ctx context.Context, req CreateProjectRequest) (*Project, error) {
+	var project Project
+	if err := c.call(ctx, http.MethodPost, "/api/projects", nil, req, &project); err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+// DeleteProject deletes a project and its deployments
+func (c *Client) DeleteProject(ctx context.Context, projectID string) error {
+	return c.call(ctx, http.MethodDelete, pathf("/api/projects/%s", projectID), nil, nil, nil)
+}
+
+// SetProjectDomain sets the custom domain of a project; an empty domain
+// removes it. The domain's CNAME must point at CNAMETarget.
+func (c *Client) SetProjectDomain(ctx context.Context, projectID, domain string) (*Project, error) {
+	body := struct {
+		Domain *string `json:"domain"`
+	}{}
+	if domain != "" {
+		body.Domain = &domain
+	}
+
+	var project Project
+	if err := c.call(ctx, http.MethodPatch, pathf("/api/projects/%s/domain", projectID), nil, body, &project); err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+// GetProjectStatus returns the active deployment and health of a project
+func (c *Client) GetProjectStatus(ctx context.Context, projectID string) (*ProjectStatus, error) {
+	var status ProjectStatus
+	if err := c.call(ctx, http.MethodGet, pathf("/api/projects/%s/status", projectID), nil, nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// GetProjectHealth checks the project's URL
+func (c *Client) GetProjectHealth(ctx context.Context, projectID string) (*Health, error) {
+	var health Health
+	if err := c.call(ctx, http.MethodGet, pathf("/api/projects/%s/health", projectID), nil, nil, &health); err != nil {
+		return nil, err
+	}
+	return &health, nil
+}
+
+// GetProjectDiff returns the changes the active deployment of a project
+// made to the one before it
+func (c *Client) GetProjectDiff(ctx context.Context, projectID string) (*Diff, error) {
+	var diff Diff
+	if err := c.call(ctx, http.MethodGet, pathf("/api/projects/%s/diff", projectID), nil, nil, &diff); err != nil {
+		return nil, err
+	}
+	return &diff, nil
+}
+
+// Rollback serves an earlier deployment of a project again. An empty
+// deploymentID rolls back to the deployment before the active one.
+func (c *Client) Rollback(ctx context.Context, projectID, deploymentID string) (*Deployment, error) {
+	body := struct {
+		DeploymentID string `json:"deployment_id,omitempty"`
+	}{deploymentID}
+
+	var deployment Deployment
+	if err := c.call(ctx, http.MethodPost, pathf("/api/projects/%s/rollback", projectID), nil, body, &deployment); err != nil {
+		return nil, err
+	}
+	return &deployment, nil
+}
+
+// Promote deploys the active deployment of the source project, e.g.
+// staging, to the target project
+func (c *Client) Promote(ctx context.Context, sourceProjectID, targetProjectID string) (*Deployment, error) {
+	var deployment Deployment
+	if err := c.call(ctx, http.MethodPost, pathf("/api/projects/%s/promote/%s", sourceProjectID, targetProjectID), nil, struct{}{}, &deployment); err != nil {
+		return nil, err
+	}
+	return &deployment, nil
+}
@@ -0,0 +1,200 @@
+package godeploy
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// IdempotencyKeyHeader lets the server recognize a retried request, so a
+// retried non-idempotent call like a deploy takes effect only once
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// RetryPolicy controls how failed requests are retried. Requests are retried
+// on connection errors and on 408, 429, 500, 502, 503 and 504 responses. A
+// request that runs out its own timeout is not retried.
+// POST and PATCH requests are retried only when they carry an
+// Idempotency-Key header.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	// Values below 2 disable retries.
+	MaxAttempts int
+	// BaseDelay is the backoff before the first retry; it doubles with
+	// each retry up to MaxDelay. The actual delay is jittered.
+	BaseDelay time.Duration
+	// MaxDelay caps a single backoff, including one asked for with
+	// Retry-After
+	MaxDelay time.Duration
+	// Budget caps the total time spent waiting between attempts. A retry
+	// that would exceed it is not made.
+	Budget time.Duration
+}
+
+// DefaultRetryPolicy is used by clients created without WithRetry
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    10 * time.Second,
+	Budget:      30 * time.Second,
+}
+
+// NoRetry sends every request exactly once
+var NoRetry = RetryPolicy{MaxAttempts: 1}
+
+// send performs req, retrying according to the client's policy. The last
+// response is returned when retries run out on a retryable status.
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	policy := c.retry
+	if !canRetry(req) {
+		policy = NoRetry
+	}
+
+	var waited time.Duration
+	for attempt := 1; ; attempt++ {
+		resp, err := c.httpClient.Do(req)
+		if attempt >= policy.MaxAttempts || !shouldRetry(resp, err) {
+			if err != nil {
+				return nil, requestError(err)
+			}
+			return resp, nil
+		}
+
+		delay := policy.backoff(attempt)
+		if resp != nil {
+			if after, ok := retryAfter(resp); ok {
+				delay = min(after, policy.MaxDelay)
+			}
+		}
+		if waited+delay > policy.Budget {
+			if err != nil {
+				return nil, requestError(err)
+			}
+			return resp, nil
+		}
+
+		// The response is discarded; let the connection be reused
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			_ = resp.Body.Close()
+		}
+
+		if err := sleep(req.Context(), delay); err != nil {
+			return nil, requestError(err)
+		}
+		waited += delay
+
+		if req, err = cloneRequest(req); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// canRetry reports whether req may be sent more than once
+func canRetry(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	switch req.Method {
+	case http.MethodPost, http.MethodPatch:
+		return req.Header.Get(IdempotencyKeyHeader) != ""
+	}
+	return true
+}
+
+// shouldRetry reports whether the outcome of an attempt is worth retrying
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		var apiErr *Error
+		if errors.As(err, &apiErr) {
+			return apiErr.Retryable
+		}
+		// An attempt that timed out would likely time out again, and each
+		// retry adds a full timeout, up to 40 minutes for a deploy
+		var netErr net.Error
+		if errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) && netErr.Timeout() {
+			return false
+		}
+		return !errors.Is(err, context.Canceled)
+	}
+	return retryableStatus(resp.StatusCode)
+}
+
+// backoff returns the jittered delay before retry number attempt: a random
+// duration up to BaseDelay * 2^(attempt-1), capped at MaxDelay
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	ceiling := p.BaseDelay << (attempt - 1)
+	if ceiling > p.MaxDelay || ceiling <= 0 {
+		ceiling = p.MaxDelay
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(ceiling)))
+	if err != nil {
+		return ceiling
+	}
+	return time.Duration(n.Int64())
+}
+
+// retryAfter parses the Retry-After header, in seconds or as an HTTP date
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// cloneRequest creates a copy of the HTTP request for retry, with the body
+// rewound to the start
+func cloneRequest(req *http.Request) (*http.Request, error) {
+	newReq := req.Clone(req.Context())
+	if req.Body == nil || req.Body == http.NoBody {
+		return newReq, nil
+	}
+	// Clone shares the already-read body; GetBody returns a fresh copy
+	if req.GetBody == nil {
+		return nil, fmt.Errorf("request body can't be replayed")
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	newReq.Body = body
+	return newReq, nil
+}
+
+// newIdempotencyKey returns a random key for IdempotencyKeyHeader
+func newIdempotencyKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate idempotency key: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,123 @@
+package godeploy
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestBackoffIsJitteredAndCapped tests the backoff bounds
+func TestBackoffIsJitteredAndCapped(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+	for attempt := 1; attempt <= 10; attempt++ {
+		ceiling := min(policy.BaseDelay<<(attempt-1), policy.MaxDelay)
+		for i := 0; i < 20; i++ {
+			if d := policy.backoff(attempt); d < 0 || d > ceiling {
+				t.Fatalf("backoff(%d) = %v, want within [0, %v]", attempt, d, ceiling)
+			}
+		}
+	}
+}
+
+// TestCloneRequestRewindsBody tests that a cloned request resends the body
+func TestCloneRequestRewindsBody(t *testing.T) {
+	req, err := New().newRequest(context.Background(), "POST", "/api/projects", nil, map[string]string{"a": "b"})
+	if err != nil {
+		t.Fatalf("newRequest failed: %v", err)
+	}
+	first, _ := io.ReadAll(req.Body)
+
+	clone, err := cloneRequest(req)
+	if err != nil {
+		t.Fatalf("cloneRequest failed: %v", err)
+	}
+	second, _ := io.ReadAll(clone.Body)
+	if string(first) != string(second) || len(second) == 0 {
+		t.Fatalf("Expected clone to resend %q, got %q", first, second)
+	}
+}
+
+// TestTransportErrorIsNotRetried tests that a transport failing with an
+// *Error is reported as is and not retried
+func TestTransportErrorIsNotRetried(t *testing.T) {
+	attempts := 0
+	transport := roundTripFunc(func(*http.Request) (*http.Response, error) {
+		attempts++
+		return nil, &Error{Message: "bad CA bundle"}
+	})
+	client := New(
+		WithHTTPClient(&http.Client{Transport: transport}),
+		WithRetry(RetryPolicy{MaxAttempts: 3, Budget: time.Second}),
+	)
+
+	_, err := client.ListProjects(context.Background())
+	if err == nil || err.Error() != "bad CA bundle" || IsUnavailable(err) {
+		t.Fatalf("Expected the transport's error, got %v", err)
+	}
+	if attempts != 1 {
+		t.Fatalf("Expected a single attempt, got %d", attempts)
+	}
+}
+
+// TestRetriedGetSucceeds tests that an idempotent request survives a 503
+func TestRetriedGetSucceeds(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	client := New(WithBaseURL(server.URL), WithRetry(RetryPolicy{MaxAttempts: 2, MaxDelay: time.Millisecond, Budget: time.Second}))
+	if _, err := client.ListTokens(context.Background()); err != nil {
+		t.Fatalf("ListTokens failed: %v", err)
+	}
+	if attempts != 2 {
+		t.Fatalf("Expected 2 attempts, got %d", attempts)
+	}
+}
+
+// TestTimedOutAttemptIsNotRetried tests that a request that runs out its
+// timeout fails without another attempt
+func TestTimedOutAttemptIsNotRetried(t *testing.T) {
+	var attempts atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := New(
+		WithBaseURL(server.URL),
+		WithHTTPClient(&http.Client{Timeout: 50 * time.Millisecond}),
+		WithRetry(RetryPolicy{MaxAttempts: 4, MaxDelay: time.Millisecond, Budget: time.Second}),
+	)
+	_, err := client.ListTokens(context.Background())
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("Expected ErrTimeout, got %v", err)
+	}
+	if n := attempts.Load(); n != 1 {
+		t.Fatalf("Expected a single attempt, got %d", n)
+	}
+}
+
+// roundTripFunc adapts a function to http.RoundTripper
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,39 @@
+package godeploy
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Subscription is the plan of a tenant
+type Subscription struct {
+	ID         string `json:"id"`
+	TenantID   string `json:"tenant_id"`
+	PlanName   string `json:"plan_name"`
+	PriceCents int    `json:"price_cents"`
+	Currency   string `json:"currency"`
+	Interval   string `json:"interval"`
+	// Status is "active", "canceled" or "expired"
+	Status             string     `json:"status"`
+	TrialEndsAt        *time.Time `json:"trial_ends_at"`
+	CurrentPeriodStart time.Time  `json:"current_period_start"`
+	CurrentPeriodEnd   *time.Time `json:"current_period_end"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
+
+// GetSubscription returns the current subscription of the authenticated
+// tenant. It fails with ErrNotFound when there is none.
+func (c *Client) GetSubscription(ctx context.Context) (*Subscription, error) {
+	var subscription Subscription
+	if err := c.call(ctx, http.MethodGet, "/api/subscriptions/current", nil, nil, &subscription); err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// CancelSubscription cancels a subscription at the end of its period
+func (c *Client) CancelSubscription(ctx context.Context, subscriptionID string) error {
+	return c.call(ctx, http.MethodPost, pathf("/api/subscriptions/%s/cancel", subscriptionID), nil, struct{}{}, nil)
+}
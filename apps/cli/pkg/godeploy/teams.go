@@ -0,0 +1,105 @@
+package godeploy
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Team roles
+const (
+	RoleOwner  = "owner"
+	RoleAdmin  = "admin"
+	RoleMember = "member"
+)
+
+// Team is a group of users sharing projects
+type Team struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TeamMember is a user in a team
+type TeamMember struct {
+	UserID string `json:"user_id"`
+	Email  string `json:"email"`
+	// Role is RoleOwner, RoleAdmin or RoleMember
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// ListTeams returns the teams of the authenticated user
+func (c *Client) ListTeams(ctx context.Context) ([]Team, error) {
+	var teams []Team
+	if err := c.call(ctx, http.MethodGet, "/api/teams", nil, nil, &teams); err != nil {
+		return nil, err
+	}
+	return teams, nil
+}
+
+// GetTeam returns a team by ID
+func (c *Client) GetTeam(ctx context.Context, teamID string) (*Team, error) {
+	var team Team
+	if err := c.call(ctx, http.MethodGet, pathf("/api/teams/%s", teamID), nil, nil, &team); err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+// CreateTeam creates a team owned by the authenticated user
+func (c *Client) CreateTeam(ctx context.Context, name string) (*Team, error) {
+	return c.saveTeam(ctx, http.MethodPost, "/api/teams", name)
+}
+
+// RenameTeam changes the name of a team
+func (c *Client) RenameTeam(ctx context.Context, teamID, name string) (*Team, error) {
+	return c.saveTeam(ctx, http.MethodPatch, pathf("/api/teams/%s", teamID), name)
+}
+
+// DeleteTeam deletes a team
+func (c *Client) DeleteTeam(ctx context.Context, teamID string) error {
+	return c.call(ctx, http.MethodDelete, pathf("/api/teams/%s", teamID), nil, nil, nil)
+}
+
+// ListTeamMembers returns the members of a team
+func (c *Client) ListTeamMembers(ctx context.Context, teamID string) ([]TeamMember, error) {
+	var members []TeamMember
+	if err := c.call(ctx, http.MethodGet, pathf("/api/teams/%s/members", teamID), nil, nil, &members); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// AddTeamMember invites the user with email to a team with role
+func (c *Client) AddTeamMember(ctx context.Context, teamID, email, role string) (*TeamMember, error) {
+	body := struct {
+		Email string `json:"email"`
+		Role  string `json:"role"`
+	}{email, role}
+
+	var member TeamMember
+	if err := c.call(ctx, http.MethodPost, pathf("/api/teams/%s/members", teamID), nil, body, &member); err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// RemoveTeamMember removes a user from a team
+func (c *Client) RemoveTeamMember(ctx context.Context, teamID, userID string) error {
+	return c.call(ctx, http.MethodDelete, pathf("/api/teams/%s/members/%s", teamID, userID), nil, nil, nil)
+}
+
+// saveTeam creates or renames a team
+func (c *Client) saveTeam(ctx context.Context, method, path, name string) (*Team, error) {
+	body := struct {
+		Name string `json:"name"`
+	}{name}
+
+	var team Team
+	if err := c.call(ctx, method, path, nil, body, &team); err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
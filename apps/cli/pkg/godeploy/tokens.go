@@ -0,0 +1,92 @@
+package godeploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// APIToken describes a long-lived API token. The secret itself is only
+// returned once, by CreateToken.
+type APIToken struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+// CreateTokenRequest is the body of CreateToken
+type CreateTokenRequest struct {
+	Name string `json:"name"`
+	// ExpiresAt is nil for a token that doesn't expire
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// CreatedToken is a newly created API token
+type CreatedToken struct {
+	APIToken
+	// Token is the secret; it cannot be retrieved again
+	Token string `json:"token"`
+}
+
+// ListTokens returns the API tokens of the authenticated user
+func (c *Client) ListTokens(ctx context.Context) ([]APIToken, error) {
+	var tokens []APIToken
+	if err := c.call(ctx, http.MethodGet, "/api/tokens", nil, nil, &tokens); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// GetToken returns the API token with the given ID
+func (c *Client) GetToken(ctx context.Context, tokenID string) (*APIToken, error) {
+	var token APIToken
+	if err := c.call(ctx, http.MethodGet, pathf("/api/tokens/%s", tokenID), nil, nil, &token); err != nil {
+		return nil, tokenError(err)
+	}
+	return &token, nil
+}
+
+// CreateToken creates an API token. It is never retried, as a retry could
+// create a second token.
+func (c *Client) CreateToken(ctx context.Context, req CreateTokenRequest) (*CreatedToken, error) {
+	var created CreatedToken
+	if err := c.call(ctx, http.MethodPost, "/api/tokens", nil, req, &created); err != nil {
+		return nil, err
+	}
+	if created.Token == "" {
+		return nil, fmt.Errorf("server did not return the token secret")
+	}
+	return &created, nil
+}
+
+// RenameToken changes the name of an API token
+func (c *Client) RenameToken(ctx context.Context, tokenID, name string) (*APIToken, error) {
+	body := struct {
+		Name string `json:"name"`
+	}{name}
+
+	var token APIToken
+	if err := c.call(ctx, http.MethodPatch, pathf("/api/tokens/%s", tokenID), nil, body, &token); err != nil {
+		return nil, tokenError(err)
+	}
+	return &token, nil
+}
+
+// RevokeToken revokes the API token with the given ID
+func (c *Client) RevokeToken(ctx context.Context, tokenID string) error {
+	return tokenError(c.call(ctx, http.MethodDelete, pathf("/api/tokens/%s", tokenID), nil, nil, nil))
+}
+
+// tokenError names the missing resource of a 404 from a token endpoint
+func tokenError(err error) error {
+	var apiErr *Error
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		apiErr.Message = "token not found"
+	}
+	return err
+}